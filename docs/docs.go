@@ -18,51 +18,71 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth": {
-            "post": {
-                "description": "Аутентифицирует врача по номеру телефона и паролю",
-                "consumes": [
-                    "application/json"
+        "/admin/emergency-access": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Все экстренные доступы к пациентам с обоснованием и числом обращений к данным — для проверки администратором",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "Вход в систему",
+                "summary": "Emergency access report",
                 "parameters": [
                     {
-                        "description": "Данные для входа",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.DoctorLoginRequest"
-                        }
+                        "type": "string",
+                        "description": "С даты (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "По дату включительно (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пациента",
+                        "name": "patient_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешное создание",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.DoctorAuthResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EmergencyAccessReportItem"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Неверный формат запроса",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/handlers.IncorrectFormatError"
                         }
                     },
                     "401": {
-                        "description": "Неверные учётные данные",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/handlers.IncorrectDataError"
                         }
                     },
                     "500": {
-                        "description": "Внутренняя ошибка сервера",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/handlers.InternalServerError"
                         }
@@ -70,47 +90,53 @@ const docTemplate = `{
                 }
             }
         },
-        "/medcard/{pat_id}": {
-            "get": {
+        "/admin/login-locks/unlock": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Сбрасывает счётчик неудачных входов и снимает блокировку по логину и/или IP-адресу",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "MedicalCard"
+                    "Admin"
                 ],
-                "summary": "Get medical card by patient ID",
+                "summary": "Unlock login after failed attempts",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Patient ID",
-                        "name": "pat_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Логин и/или IP",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UnlockLoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/entities.OneCMedicalCard"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Блокировки нет",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -121,208 +147,115 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.InternalServerError"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/patients/duplicates": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Пары записей, похожих на одного человека, по убыванию оценки. Оценка складывается из совпадения СНИЛС, полиса, ФИО и даты рождения",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "MedicalCard"
+                    "Admin"
                 ],
-                "summary": "Update medical card by patient ID",
+                "summary": "Patient duplicate review queue",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Patient ID",
-                        "name": "pat_id",
-                        "in": "path",
-                        "required": true
+                        "description": "pending (default), merged или dismissed",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
-                        "description": "Medical card update data",
-                        "name": "update",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.OneCMedicalCard"
-                        }
+                        "type": "integer",
+                        "description": "Max results (default: 50, max: 200)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DuplicateCandidateView"
                             }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
                         }
                     }
                 }
             }
         },
-        "/onec/auth": {
+        "/admin/patients/duplicates/scan": {
             "post": {
-                "description": "Syncs a batch of users (login + password) received from 1C into the internal auth system.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Webhooks"
-                ],
-                "summary": "Sync users from 1C",
-                "parameters": [
-                    {
-                        "description": "List of users to sync",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.AuthCreditionals"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/patients": {
-            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Запускает поиск дублей сразу, не дожидаясь воркера. Возвращает число пар в очереди",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Patients"
-                ],
-                "summary": "Get cached patient list from 1C with pagination",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page (default: 20, max: 100)",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "Admin"
                 ],
+                "summary": "Scan for patient duplicates",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PatientListResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
                         }
                     }
                 }
             }
         },
-        "/signature/{recep_id}": {
-            "get": {
+        "/admin/patients/duplicates/{id}/dismiss": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Returns the base64-encoded signature image for a given reception ID.",
+                "description": "Отмечает пару как разных людей, повторно она не предлагается",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Emergency"
+                    "Admin"
                 ],
-                "summary": "Get patient signature",
+                "summary": "Dismiss patient duplicate",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Reception ID",
-                        "name": "recep_id",
+                        "type": "integer",
+                        "description": "Duplicate candidate ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                        "description": "OK"
                     },
                     "404": {
                         "description": "Not Found",
@@ -333,8 +266,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Пара уже рассмотрена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -343,52 +276,59 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/patients/duplicates/{id}/merge": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Accepts a multipart form with a 'signature' file (e.g., PNG, JPG) and saves it.",
+                "description": "Переносит медкарту, вызовы, назначения бригад, историю правок и экстренные доступы второй записи пары в survivor_id.\nВторая запись удаляется, об объединении сообщается в 1С (при недоступности 1С — повторно воркером)",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Emergency"
+                    "Admin"
                 ],
-                "summary": "Upload patient signature",
+                "summary": "Merge patient duplicates",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Reception ID",
-                        "name": "recep_id",
+                        "type": "integer",
+                        "description": "Duplicate candidate ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "Signature image file",
-                        "name": "signature",
-                        "in": "formData",
-                        "required": true
+                        "description": "Запись, которая остаётся",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MergePatientsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entities.PatientMerge"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "survivor_id не из этой пары",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -396,8 +336,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Пара уже рассмотрена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -408,38 +348,93 @@ const docTemplate = `{
                 }
             }
         },
-        "/webhook/onec/patients": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/security-events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Журнал неудачных входов, блокировок и разблокировок для проверки администратором",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "1C"
+                    "Admin"
                 ],
-                "summary": "Webhook from 1C: patient list update",
+                "summary": "Security events",
                 "parameters": [
                     {
-                        "description": "Patient list update from 1C",
-                        "name": "update",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.PatientListUpdate"
-                        }
+                        "type": "string",
+                        "description": "С даты (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "По дату включительно (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тип события (login_failed, login_locked, login_blocked, login_unlocked)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин",
+                        "name": "login",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP-адрес",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей (по умолчанию 200, максимум 1000)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.SecurityEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
                     }
                 }
             }
         },
-        "/webhook/onec/receptions": {
+        "/auth": {
             "post": {
+                "description": "Аутентифицирует врача по номеру телефона и паролю\nЕсли у пользователя подключён TOTP или его роль требует второй фактор, вместо токенов\nвозвращается mfa_required и mfa_token для /auth/mfa/verify",
                 "consumes": [
                     "application/json"
                 ],
@@ -447,29 +442,99 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "1C"
+                    "Auth"
                 ],
-                "summary": "Webhook from 1C: new receptions",
+                "summary": "Вход в систему",
                 "parameters": [
                     {
-                        "description": "Receptions update",
-                        "name": "update",
+                        "description": "Данные для входа",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Call"
+                            "$ref": "#/definitions/models.DoctorLoginRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "Успешное создание",
+                        "schema": {
+                            "$ref": "#/definitions/models.DoctorAuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверные учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток, см. заголовок Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
                     }
                 }
             }
         },
-        "/ws/notification/register/{user_id}": {
-            "get": {
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отзывает текущий access-токен и все refresh-токены этого входа",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Выход из системы",
+                "responses": {
+                    "200": {
+                        "description": "Выход выполнен"
+                    },
+                    "401": {
+                        "description": "Токен недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Проверяет первый код из приложения и возвращает коды восстановления (показываются один раз)",
                 "consumes": [
                     "application/json"
                 ],
@@ -477,27 +542,65 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Notification"
+                    "Auth"
                 ],
-                "summary": "Подписаться на уведомления",
+                "summary": "Подтверждение TOTP",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User id",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Код из приложения",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MFACodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MFARecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный код",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "409": {
+                        "description": "Подключение не начато или уже завершено",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
                     }
                 }
             }
         },
-        "/ws/notification/unregister/{user_id}": {
-            "get": {
+        "/auth/mfa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Требует код из приложения или код восстановления. Недоступно для ролей, которым MFA обязателен",
                 "consumes": [
                     "application/json"
                 ],
@@ -505,272 +608,3187 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Notification"
+                    "Auth"
                 ],
-                "summary": "Отписаться от уведомлений",
+                "summary": "Отключение TOTP",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User id",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Код из приложения или код восстановления",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MFACodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный код",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "403": {
+                        "description": "MFA обязателен для роли",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "MFA не подключён",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает секрет и otpauth:// URI для QR-кода. Подключение завершается через /auth/mfa/confirm",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Подключение TOTP",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MFAEnrollment"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "409": {
+                        "description": "MFA уже подключён",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/verify": {
+            "post": {
+                "description": "Принимает MFA-токен из ответа /auth и код из приложения-аутентификатора или код восстановления.\nЕсли MFA подключался при этом входе, в ответе один раз приходят коды восстановления",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Второй шаг входа (TOTP)",
+                "parameters": [
+                    {
+                        "description": "MFA-токен и код",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MFAVerifyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DoctorAuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный код или MFA-токен",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток, см. заголовок Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Обменивает refresh-токен на новую пару access/refresh. Refresh-токен одноразовый: при повторном использовании все токены этого входа отзываются.\nТокен принимается только с устройства, на котором выполнен вход (device_id)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Обновление токенов",
+                "parameters": [
+                    {
+                        "description": "Refresh-токен",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Новая пара токенов",
+                        "schema": {
+                            "$ref": "#/definitions/models.DoctorAuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "401": {
+                        "description": "Refresh-токен недействителен, истёк или отозван",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/emergency/calls": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Recently updated calls first. Users with patients:all see every call, others only calls their crew is assigned to.\nPages are addressed by the opaque next_cursor/prev_cursor from the previous response",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emergency"
+                ],
+                "summary": "List emergency calls",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "next_cursor or prev_cursor from the previous response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return totalHits (extra COUNT query)",
+                        "name": "with_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CursorPage-array_models_CallListItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Профиль вошедшего сотрудника из 1С: ФИО, специализация, поликлиника, бригада",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Текущий пользователь",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID устройства, к которому привязан токен",
+                        "name": "X-Device-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DoctorInfoResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Токен недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/patients/favorites": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Пациенты, добавленные пользователем в избранное, по ФИО",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Избранные пациенты",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QuickAccessPatient"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Токен недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/patients/favorites/{pat_id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Повторное добавление не считается ошибкой",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Добавить пациента в избранное",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пациента",
+                        "name": "pat_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пациент в избранном"
+                    },
+                    "401": {
+                        "description": "Токен недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к пациенту",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Убрать пациента из избранного",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пациента",
+                        "name": "pat_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пациент убран из избранного"
+                    },
+                    "401": {
+                        "description": "Токен недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "404": {
+                        "description": "Пациента нет в избранном",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/patients/recent": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Пациенты, чьи медкарты пользователь открывал за срок хранения (RECENT_PATIENTS_RETENTION), последние первыми",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Недавние пациенты",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Сколько пациентов вернуть (по умолчанию и не больше RECENT_PATIENTS_MAX)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QuickAccessPatient"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Токен недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Список входов пользователя на устройствах. Сеанс текущего запроса отмечен current=true",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Активные сеансы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID устройства, к которому привязан токен",
+                        "name": "X-Device-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SessionInfo"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Токен недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/sessions/{session_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отзывает токены сеанса и закрывает его websocket-соединение",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Удалённый выход",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID устройства, к которому привязан токен",
+                        "name": "X-Device-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID сеанса",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сеанс завершён"
+                    },
+                    "401": {
+                        "description": "Токен недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "404": {
+                        "description": "Сеанс не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/medcard/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Карты из БД отдаются сразу, недостающие загружаются из 1С параллельно.\nОшибка по одному пациенту (нет доступа — 403, нет в 1С — 404, запись объединена с другой — 410, 1С недоступна — 502) не прерывает пакет: у элемента заполняются status и error.\nДоступ лечащего врача проверяется по сохранённой карте: если карты ещё нет в БД, её нужно сначала открыть через GET /medcard/{pat_id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MedicalCard"
+                ],
+                "summary": "Get medical cards of several patients",
+                "parameters": [
+                    {
+                        "description": "ID пациентов (не больше 50)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MedCardBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MedCardBatchItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/medcard/edits": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MedicalCard"
+                ],
+                "summary": "Get sync status of offline medical card edits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Клиентские ID правок через запятую",
+                        "name": "ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по статусу (pending, applied, conflict, rejected)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.MedCardEdit"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Принимает правки, сделанные на устройстве (в том числе без связи), и ставит их в очередь на отправку в 1С.\nПравки отправляет фоновый воркер в порядке поступления на сервер (внутри пакета — в порядке массива),\nclient_timestamp на порядок не влияет. Результат доступен через GET /medcard/edits.\nПравки с уже известным client_edit_id не дублируются. В ответе — текущий статус каждой правки",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MedicalCard"
+                ],
+                "summary": "Submit offline medical card edits",
+                "parameters": [
+                    {
+                        "description": "Пакет правок",
+                        "name": "edits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MedCardEditBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.MedCardEdit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к пациенту одной из правок",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/medcard/{pat_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MedicalCard"
+                ],
+                "summary": "Get medical card by patient ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "pat_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.OneCMedicalCard"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия карты, передаётся в If-Match при изменении"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к пациенту",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "410": {
+                        "description": "Запись объединена с другой: карта доступна под error.survivor_id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MedicalCard"
+                ],
+                "summary": "Update medical card by patient ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "pat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карты, полученный при чтении",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Medical card update data",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.OneCMedicalCard"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия карты"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к пациенту",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Карта изменена другим пользователем",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Некорректный СНИЛС, полис, телефон или дата рождения",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ValidationError"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Принимает JSON Merge Patch (RFC 7396). Разрешено менять только контакты\n(mobile_phone, additional_phone, email, address, workplace) и родственника (relative.status, relative.name)\nЧасти адреса (address_details) проверяются, строка address пересобирается из них; изменённая строка address заново разбирается на части",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MedicalCard"
+                ],
+                "summary": "Partially update medical card by patient ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "pat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карты, полученный при чтении",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.OneCMedicalCard"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия карты"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к пациенту",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Карта изменена другим пользователем",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Патч меняет запрещённые поля или содержит некорректные значения",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/medcard/{pat_id}/emergency-access": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Выдаёт временный доступ к карте пациента, к которому пользователь не привязан.\nОбоснование обязательно; лечащий врач получает уведомление, доступ попадает в отчёт для администратора",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MedicalCard"
+                ],
+                "summary": "Request emergency (break-the-glass) access to a patient",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "pat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Обоснование",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmergencyAccessRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.EmergencyAccessGrant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/onec/auth": {
+            "post": {
+                "description": "Creates, updates and disables users keyed by their stable 1C ID. Users are never deleted.\nWith full=true every user missing from the list is disabled. Passwords may be sent pre-hashed (bcrypt) in password_hash.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Sync users from 1C",
+                "parameters": [
+                    {
+                        "description": "Users to sync",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserSyncRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserSyncResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown role, duplicate ID or invalid password hash",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/onec/roles": {
+            "post": {
+                "description": "Replaces the role to permissions mapping. New permissions are applied to tokens issued after the sync.\nThe integration role always keeps onec:webhook, so a faulty mapping cannot lock 1C out of the webhooks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Sync role permissions from 1C",
+                "parameters": [
+                    {
+                        "description": "Role to permissions mapping",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OneCRoles"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown role or permission",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/patients": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "filter: conditions \"field.op.value\" joined with \"\u0026$\" (AND, in URL \"%26$\") or \"|\" (OR), grouped with parentheses.\nFields: full_name, snils (digits only), policy.number (without spaces and dashes) — eq, ne, like, in, nin;\nbirth_date (YYYY-MM-DD) — eq, ne, gt, gte, lt, lte, between; gender (true — male) — eq, ne.\nLists for in, nin and between are comma-separated. Escape \\ . , | ( ) \u0026 with a backslash.\norder: \"field.asc|desc\" joined with \"\u0026$\", the same fields (default: full_name.asc).\nBy default pages are addressed by number (OFFSET) and models.FilterResponse is returned.\nSending the cursor parameter switches to cursor pagination and returns models.CursorPage: an empty cursor requests the first page,\nthe next pages are addressed by the opaque next_cursor/prev_cursor from the previous response; a cursor is only valid with the same order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Get cached patient list from 1C with pagination, filtering and sorting",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Empty for the first page, then next_cursor or prev_cursor from the previous response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Cursor pagination only: also return totalHits (extra COUNT query)",
+                        "name": "with_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for OFFSET pagination (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter, e.g. (full_name.like.Иванов|snils.in.12345678901,10987654321)\u0026$birth_date.between.1980-01-01,1990-12-31",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order, e.g. birth_date.desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "With cursor",
+                        "schema": {
+                            "$ref": "#/definitions/models.CursorPage-array_entities_OneCPatientListItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown field, operator or malformed value in filter/order, invalid cursor",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    }
+                }
+            }
+        },
+        "/patients/resolve": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "code — содержимое QR-кода браслета (подпись проверяется) или номер полиса ОМС со штрихкода полиса (16 цифр)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Identify patient by scanned code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Scanned QR payload or OMS policy number",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.OneCPatientListItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid code or signature",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Policy number matches several patients",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/patients/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Searches by full name tolerating typos, Latin transliteration and ё/е. Exact matches on SNILS, policy number or phone come first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Fuzzy patient search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name, SNILS, policy number or phone",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max results (default: 20, max: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PatientSearchHit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Empty query",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    }
+                }
+            }
+        },
+        "/patients/{pat_id}/qr": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "QR-код для браслета или карточки пациента. Код подписан сервером, его разбирает GET /patients/resolve",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Signed patient QR code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "pat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "png (default) or svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Side in pixels, 128-1024 (default: 256)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown format",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/signature/{recep_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the base64-encoded signature image for a given reception ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emergency"
+                ],
+                "summary": "Get patient signature",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reception ID",
+                        "name": "recep_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Accepts a multipart form with a 'signature' file (e.g., PNG, JPG) and saves it.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emergency"
+                ],
+                "summary": "Upload patient signature",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reception ID",
+                        "name": "recep_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Signature image file",
+                        "name": "signature",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sync": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns patients, medical cards and calls created, updated or deleted since the token from the previous response.\nWithout since returns everything (full sync). Medical cards require medcard:read, calls require emergency:read;\nonly cards and calls available to the user are included, every delivered card is written to the patient access log.\nDeleted also lists cards and calls the user lost access to (call completed, attending doctor changed).\nApply deleted first, then upsert the other lists. Repeat immediately while has_more is true.\nOn reset drop local data and start again without since",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Delta sync feed for offline clients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "token from the previous response",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max changes per response (default: 500, max: 2000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed sync token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectFormatError"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Для каждой платформы: min_version — сборки ниже получают 426 на все запросы API,\nrecommended_version — сборкам ниже предлагается обновиться (заголовок X-App-Update-Recommended)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Version"
+                ],
+                "summary": "Версия сервера и поддерживаемые версии приложения",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.VersionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook/onec/patients": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "1C"
+                ],
+                "summary": "Webhook from 1C: patient list update",
+                "parameters": [
+                    {
+                        "description": "Patient list update from 1C",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.PatientListUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/webhook/onec/receptions": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "1C"
+                ],
+                "summary": "Webhook from 1C: new receptions",
+                "parameters": [
+                    {
+                        "description": "Receptions update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Call"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/ws/notification/register/{user_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Соединение привязывается к сеансу входа из токена и закрывается при отзыве сеанса.\nБраузер не может передать заголовки при открытии WebSocket, поэтому токен и ID устройства принимаются и в параметрах access_token и device_id\nИз браузера соединение принимается только с разрешённых для CORS origin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Подписаться на уведомления",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access-токен, если нет заголовка Authorization",
+                        "name": "access_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID устройства, если нет заголовка X-Device-ID",
+                        "name": "device_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "403": {
+                        "description": "Подписка на уведомления другого пользователя",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ws/notification/unregister/{user_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Отписаться от уведомлений",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IncorrectDataError"
+                        }
+                    },
+                    "403": {
+                        "description": "Отписка другого пользователя",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "entities.Address": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "г. Москва"
+                },
+                "entrance": {
+                    "type": "string",
+                    "example": "2"
+                },
+                "flat": {
+                    "type": "string",
+                    "example": "12"
+                },
+                "floor": {
+                    "type": "string",
+                    "example": "3"
+                },
+                "house": {
+                    "description": "С корпусом и строением",
+                    "type": "string",
+                    "example": "5 к2"
+                },
+                "intercom_code": {
+                    "type": "string",
+                    "example": "12К"
+                },
+                "lat": {
+                    "type": "number",
+                    "example": 55.7558
+                },
+                "lon": {
+                    "type": "number",
+                    "example": 37.6173
+                },
+                "precision": {
+                    "description": "Точность координат: house, street или city. Пусто, если координат нет",
+                    "type": "string",
+                    "example": "house"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "Московская обл."
+                },
+                "street": {
+                    "type": "string",
+                    "example": "ул. Ленина"
+                }
+            }
+        },
+        "entities.Certificate": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.ClientRef": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Doctor": {
+            "type": "object",
+            "properties": {
+                "attachment_end": {
+                    "type": "string"
+                },
+                "attachment_start": {
+                    "type": "string"
+                },
+                "clinic": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "policy_or_cert_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.EmergencyAccessGrant": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.MedCardEdit": {
+            "type": "object",
+            "properties": {
+                "applied_at": {
+                    "type": "string"
+                },
+                "applied_version": {
+                    "type": "integer"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "base_version": {
+                    "description": "Версия карты, на которой сделана правка",
+                    "type": "integer"
+                },
+                "client_edit_id": {
+                    "description": "ID, сгенерированный клиентом, уникален в пределах пользователя",
+                    "type": "string"
+                },
+                "client_timestamp": {
+                    "description": "Время правки по часам устройства, только для показа: правки отправляются в порядке поступления",
+                    "type": "string"
+                },
+                "conflict_card": {
+                    "description": "Актуальная карта на момент конфликта",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "description": "Раньше этого времени правка не отправляется",
+                    "type": "string"
+                },
+                "patch": {
+                    "description": "JSON Merge Patch",
+                    "type": "object"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/entities.MedCardEditStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.MedCardEditStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "applied",
+                "conflict",
+                "rejected"
+            ],
+            "x-enum-comments": {
+                "MedCardEditApplied": "Применена в 1С и в БД",
+                "MedCardEditConflict": "Карта изменилась, нужно решение врача",
+                "MedCardEditPending": "Ожидает отправки в 1С",
+                "MedCardEditRejected": "Правка некорректна и не будет применена"
+            },
+            "x-enum-varnames": [
+                "MedCardEditPending",
+                "MedCardEditApplied",
+                "MedCardEditConflict",
+                "MedCardEditRejected"
+            ]
+        },
+        "entities.OneCMedicalCard": {
+            "type": "object",
+            "properties": {
+                "additional_phone": {
+                    "type": "string"
+                },
+                "address": {
+                    "type": "string"
+                },
+                "address_details": {
+                    "description": "Адрес по частям с координатами. В 1С не передаётся: там остаётся строка Address",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.Address"
+                        }
+                    ]
+                },
+                "age": {
+                    "description": "Вычисляется из даты рождения, см. FillAge",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.PatientAge"
+                        }
+                    ]
+                },
+                "attending_doctor": {
+                    "$ref": "#/definitions/entities.Doctor"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "certificate": {
+                    "$ref": "#/definitions/entities.Certificate"
+                },
+                "display_name": {
+                    "description": "Основные поля",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "legal_representative": {
+                    "description": "Вложенные структуры",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.ClientRef"
+                        }
+                    ]
+                },
+                "mobile_phone": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "policy": {
+                    "$ref": "#/definitions/entities.Policy"
+                },
+                "relative": {
+                    "$ref": "#/definitions/entities.Relative"
+                },
+                "snils": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Версия карты для оптимистичной блокировки (ETag)",
+                    "type": "integer"
+                },
+                "workplace": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.OneCPatientListItem": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "description": "Вычисляется из даты рождения, см. FillAge",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.PatientAge"
+                        }
+                    ]
+                },
+                "birthDate": {
+                    "description": "в формате \"YYYY-MM-DD\"",
+                    "type": "string"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "gender": {
+                    "description": "true — мужской",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "medical_card": {
+                    "$ref": "#/definitions/entities.OneCMedicalCard"
+                },
+                "patientID": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.PatientAge": {
+            "description": "Возраст пациента на текущую дату",
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "Дней после последнего полного месяца",
+                    "type": "integer",
+                    "example": 12
+                },
+                "display": {
+                    "description": "Строка для отображения",
+                    "type": "string",
+                    "example": "1 год 3 месяца"
+                },
+                "months": {
+                    "description": "Полных месяцев после последнего дня рождения",
+                    "type": "integer",
+                    "example": 3
+                },
+                "years": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "entities.PatientDuplicateStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "merged",
+                "dismissed"
+            ],
+            "x-enum-comments": {
+                "DuplicateDismissed": "Разные люди, повторно не предлагается",
+                "DuplicateMerged": "Записи объединены",
+                "DuplicatePending": "Ждёт решения администратора"
+            },
+            "x-enum-varnames": [
+                "DuplicatePending",
+                "DuplicateMerged",
+                "DuplicateDismissed"
+            ]
+        },
+        "entities.PatientListUpdate": {
+            "type": "object",
+            "properties": {
+                "patients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.OneCPatientListItem"
+                    }
+                }
+            }
+        },
+        "entities.PatientMerge": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "merged_by": {
+                    "type": "integer"
+                },
+                "merged_id": {
+                    "type": "string"
+                },
+                "reported_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/entities.PatientMergeStatus"
+                },
+                "survivor_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.PatientMergeStatus": {
+            "type": "string",
+            "enum": [
+                "pending_sync",
+                "synced"
+            ],
+            "x-enum-varnames": [
+                "PatientMergePendingSync",
+                "PatientMergeSynced"
+            ]
+        },
+        "entities.Permission": {
+            "type": "string",
+            "enum": [
+                "patients:read",
+                "patients:all",
+                "medcard:read",
+                "medcard:write",
+                "emergency:read",
+                "emergency:write",
+                "onec:webhook",
+                "audit:read",
+                "users:manage",
+                "patients:merge"
+            ],
+            "x-enum-comments": {
+                "PermAuditRead": "Отчёты для проверки доступа к пациентам",
+                "PermPatientsAll": "Доступ к любому пациенту клиники, а не только к своим",
+                "PermPatientsMerge": "Проверка и объединение дублей пациентов",
+                "PermUsersManage": "Разблокировка учётных записей"
+            },
+            "x-enum-varnames": [
+                "PermPatientsRead",
+                "PermPatientsAll",
+                "PermMedCardRead",
+                "PermMedCardWrite",
+                "PermEmergencyRead",
+                "PermEmergencyWrite",
+                "PermOneCWebhook",
+                "PermAuditRead",
+                "PermUsersManage",
+                "PermPatientsMerge"
+            ]
+        },
+        "entities.Policy": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Relative": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Role": {
+            "type": "string",
+            "enum": [
+                "doctor",
+                "paramedic",
+                "dispatcher",
+                "admin",
+                "integration"
+            ],
+            "x-enum-comments": {
+                "RoleIntegration": "Технический пользователь 1С для вебхуков"
+            },
+            "x-enum-varnames": [
+                "RoleDoctor",
+                "RoleParamedic",
+                "RoleDispatcher",
+                "RoleAdmin",
+                "RoleIntegration"
+            ]
+        },
+        "entities.SecurityEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/entities.SecurityEventType"
+                },
+                "user_id": {
+                    "description": "Администратор для ручных действий",
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.SecurityEventType": {
+            "type": "string",
+            "enum": [
+                "login_failed",
+                "login_locked",
+                "login_blocked",
+                "login_unlocked"
+            ],
+            "x-enum-comments": {
+                "SecurityEventLoginBlocked": "Попытка входа во время блокировки",
+                "SecurityEventLoginLocked": "Ключ заблокирован после очередной ошибки"
+            },
+            "x-enum-varnames": [
+                "SecurityEventLoginFailed",
+                "SecurityEventLoginLocked",
+                "SecurityEventLoginBlocked",
+                "SecurityEventLoginUnlocked"
+            ]
+        },
+        "entities.SyncEntity": {
+            "type": "string",
+            "enum": [
+                "patient",
+                "medical_card",
+                "call"
+            ],
+            "x-enum-varnames": [
+                "SyncEntityPatient",
+                "SyncEntityMedicalCard",
+                "SyncEntityCall"
+            ]
+        },
+        "handlers.IncorrectDataError": {
+            "type": "object",
+            "properties": {
+                "response": {
+                    "type": "object",
+                    "properties": {
+                        "code": {
+                            "type": "integer",
+                            "example": 401
+                        },
+                        "message": {
+                            "type": "string",
+                            "example": "Некорректные данные"
+                        }
+                    }
+                },
+                "status": {
+                    "description": "error",
+                    "type": "string",
+                    "example": "IncorrectDataError"
+                }
+            }
+        },
+        "handlers.IncorrectFormatError": {
+            "type": "object",
+            "properties": {
+                "response": {
+                    "type": "object",
+                    "properties": {
+                        "code": {
+                            "type": "integer",
+                            "example": 400
+                        },
+                        "message": {
+                            "type": "string",
+                            "example": "Неверный формат запроса"
+                        }
+                    }
+                },
+                "status": {
+                    "description": "error",
+                    "type": "string",
+                    "example": "IncorrectFormatError"
+                }
+            }
+        },
+        "handlers.InternalServerError": {
+            "type": "object",
+            "properties": {
+                "response": {
+                    "type": "object",
+                    "properties": {
+                        "code": {
+                            "type": "integer",
+                            "example": 500
+                        },
+                        "message": {
+                            "type": "string",
+                            "example": "Внутренняя ошибка сервера"
+                        }
+                    }
+                },
+                "status": {
+                    "description": "error",
+                    "type": "string",
+                    "example": "InternalServerError"
+                }
+            }
+        },
+        "handlers.OneCRoles": {
+            "type": "object",
+            "required": [
+                "roles"
+            ],
+            "properties": {
+                "roles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "handlers.ValidationError": {
+            "type": "object",
+            "properties": {
+                "response": {
+                    "type": "object",
+                    "properties": {
+                        "code": {
+                            "type": "integer",
+                            "example": 422
+                        },
+                        "message": {
+                            "type": "string",
+                            "example": "Ошибка валидации"
+                        }
+                    }
+                },
+                "status": {
+                    "description": "error",
+                    "type": "string",
+                    "example": "ValidationError"
+                }
+            }
+        },
+        "models.Call": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Адрес (адрес вызова)",
+                    "type": "string"
+                },
+                "address_details": {
+                    "description": "Адрес по частям с координатами, если 1С его передаёт. Address остаётся основным",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.Address"
+                        }
+                    ]
+                },
+                "call_id": {
+                    "type": "string"
+                },
+                "crew": {
+                    "description": "Логины членов бригады, назначенной на вызов",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "invalid_fields": {
+                    "description": "Поля, не прошедшие проверку: имя поля → значение, как его передала 1С. Само поле очищается",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "patient_count": {
+                    "description": "Кол-во пациентов",
+                    "type": "integer"
+                },
+                "patients": {
+                    "description": "Данные пациентов",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Patient"
+                    }
+                },
+                "phone": {
+                    "description": "Телефон пациента",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус вызова",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CallStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.CallListItem": {
+            "type": "object",
+            "properties": {
+                "call_id": {
+                    "type": "string"
+                },
+                "patients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Patient"
+                    }
+                },
+                "status": {
+                    "description": "Статус синхронизации с 1С: received, edited, pending_sync, synced, sync_failed",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CallStatus": {
+            "type": "string",
+            "enum": [
+                "compleated",
+                "proccess"
+            ],
+            "x-enum-varnames": [
+                "CallStatusCompleted",
+                "CallStatusWork"
+            ]
+        },
+        "models.ClientVersions": {
+            "type": "object",
+            "properties": {
+                "min_version": {
+                    "type": "string",
+                    "example": "1.4.0"
+                },
+                "recommended_version": {
+                    "type": "string",
+                    "example": "1.6.0"
+                }
+            }
+        },
+        "models.CursorPage-array_entities_OneCPatientListItem": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.OneCPatientListItem"
+                    }
+                },
+                "hitsPerPage": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "description": "Нет — это последняя страница",
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "description": "Нет — это первая страница",
+                    "type": "string"
+                },
+                "totalHits": {
+                    "description": "Только при with_total=true",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CursorPage-array_models_CallListItem": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CallListItem"
+                    }
+                },
+                "hitsPerPage": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "description": "Нет — это последняя страница",
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "description": "Нет — это первая страница",
+                    "type": "string"
+                },
+                "totalHits": {
+                    "description": "Только при with_total=true",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DoctorAuthResponse": {
+            "description": "Ответ с данными авторизованного врача",
+            "type": "object",
+            "properties": {
+                "doctor": {
+                    "description": "Профиль пользователя, только при входе",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DoctorInfoResponse"
+                        }
+                    ]
+                },
+                "expires_at": {
+                    "description": "Время истечения access-токена (unix)",
+                    "type": "integer",
+                    "example": 1715783400
+                },
+                "id": {
+                    "description": "ID врача",
+                    "type": "integer",
+                    "example": 1
+                },
+                "mfa_enrollment": {
+                    "description": "Роль требует MFA, а он ещё не подключён",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.MFAEnrollment"
+                        }
+                    ]
+                },
+                "mfa_required": {
+                    "description": "Второй фактор: если MFARequired, токенов нет — нужно вызвать /auth/mfa/verify с MFAToken",
+                    "type": "boolean",
+                    "example": false
+                },
+                "mfa_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                },
+                "permissions": {
+                    "description": "Права, зашитые в access-токен",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Permission"
+                    },
+                    "example": [
+                        "medcard:read"
+                    ]
+                },
+                "recovery_codes": {
+                    "description": "Показываются один раз после подключения MFA",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "entities.Certificate": {
-            "type": "object",
-            "properties": {
-                "date": {
-                    "type": "string"
                 },
-                "number": {
-                    "type": "string"
-                }
-            }
-        },
-        "entities.ClientRef": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
+                "refresh_expires_at": {
+                    "type": "integer",
+                    "example": 1718375400
                 },
-                "name": {
-                    "type": "string"
+                "refresh_token": {
+                    "description": "Refresh-токен, меняется при каждом обновлении",
+                    "type": "string",
+                    "example": "Zk9yZXZlci..."
+                },
+                "role": {
+                    "description": "Роль пользователя",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.Role"
+                        }
+                    ],
+                    "example": "doctor"
+                },
+                "token": {
+                    "description": "Access-токен (JWT)",
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
                 }
             }
         },
-        "entities.Doctor": {
+        "models.DoctorInfoResponse": {
+            "description": "Возвращается в GET /me и в ответе на вход",
             "type": "object",
             "properties": {
-                "attachment_end": {
-                    "type": "string"
+                "clinic": {
+                    "type": "string",
+                    "example": "Поликлиника 1"
                 },
-                "attachment_start": {
-                    "type": "string"
+                "crew": {
+                    "description": "Бригада СМП",
+                    "type": "string",
+                    "example": "Бригада 3"
                 },
-                "clinic": {
-                    "type": "string"
+                "doctor_id": {
+                    "type": "integer",
+                    "example": 1
                 },
                 "full_name": {
-                    "type": "string"
+                    "description": "Полное имя врача",
+                    "type": "string",
+                    "example": "Иванов Иван Иванович"
                 },
-                "policy_or_cert_number": {
-                    "type": "string"
+                "login": {
+                    "type": "string",
+                    "example": "+79622840761"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.Role"
+                        }
+                    ],
+                    "example": "doctor"
+                },
+                "specialization": {
+                    "type": "string",
+                    "example": "Терапевт"
                 }
             }
         },
-        "entities.OneCMedicalCard": {
+        "models.DoctorLoginRequest": {
+            "description": "Запрос для входа врача в систему",
             "type": "object",
+            "required": [
+                "device_id",
+                "password",
+                "phone"
+            ],
             "properties": {
-                "additional_phone": {
-                    "type": "string"
+                "app_version": {
+                    "description": "Версия приложения",
+                    "type": "string",
+                    "example": "1.2.3"
                 },
-                "address": {
-                    "type": "string"
+                "device_id": {
+                    "description": "Идентификатор устройства, токены привязываются к нему",
+                    "type": "string",
+                    "example": "a1b2c3d4"
                 },
-                "age": {
-                    "type": "string"
+                "password": {
+                    "description": "Пароль",
+                    "type": "string",
+                    "example": "password123"
                 },
-                "attending_doctor": {
-                    "$ref": "#/definitions/entities.Doctor"
+                "phone": {
+                    "description": "Логин (телефон)",
+                    "type": "string",
+                    "example": "+79622840765"
                 },
-                "birth_date": {
+                "platform": {
+                    "description": "ios, android",
+                    "type": "string",
+                    "example": "android"
+                },
+                "push_token": {
+                    "description": "Токен push-уведомлений",
+                    "type": "string",
+                    "example": "fcm:APA91b..."
+                }
+            }
+        },
+        "models.DuplicateCandidateView": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "certificate": {
-                    "$ref": "#/definitions/entities.Certificate"
+                "id": {
+                    "type": "integer"
                 },
-                "display_name": {
-                    "description": "Основные поля",
-                    "type": "string"
+                "other": {
+                    "$ref": "#/definitions/models.PatientRecord"
                 },
-                "email": {
+                "other_patient_id": {
                     "type": "string"
                 },
-                "legal_representative": {
-                    "description": "Вложенные структуры",
+                "patient": {
+                    "description": "nil — запись уже удалена",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entities.ClientRef"
+                            "$ref": "#/definitions/models.PatientRecord"
                         }
                     ]
                 },
-                "mobile_phone": {
+                "patient_id": {
                     "type": "string"
                 },
-                "patient_id": {
+                "reasons": {
+                    "description": "Совпавшие признаки через запятую: snils, policy, name, birth_date",
                     "type": "string"
                 },
-                "policy": {
-                    "$ref": "#/definitions/entities.Policy"
+                "reviewed_at": {
+                    "type": "string"
                 },
-                "relative": {
-                    "$ref": "#/definitions/entities.Relative"
+                "reviewed_by": {
+                    "type": "integer"
                 },
-                "snils": {
-                    "type": "string"
+                "score": {
+                    "description": "От 0 до 1",
+                    "type": "number"
                 },
-                "workplace": {
+                "status": {
+                    "$ref": "#/definitions/entities.PatientDuplicateStatus"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "entities.OneCPatientListItem": {
+        "models.EmergencyAccessReportItem": {
             "type": "object",
             "properties": {
-                "birthDate": {
-                    "description": "в формате \"YYYY-MM-DD\"",
-                    "type": "string"
+                "access_count": {
+                    "description": "Сколько раз данные пациента открывались по этому доступу",
+                    "type": "integer",
+                    "example": 3
                 },
-                "fullName": {
+                "created_at": {
                     "type": "string"
                 },
-                "gender": {
-                    "description": "true — мужской",
-                    "type": "boolean"
+                "expires_at": {
+                    "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "medical_card": {
-                    "$ref": "#/definitions/entities.OneCMedicalCard"
+                "patient_id": {
+                    "type": "string"
                 },
-                "patientID": {
+                "reason": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_login": {
+                    "type": "string",
+                    "example": "+79622840761"
                 }
             }
         },
-        "entities.PatientListUpdate": {
+        "models.EmergencyAccessRequest": {
+            "description": "Обоснование обязательно: все экстренные доступы проверяются администратором",
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "minLength": 10,
+                    "example": "Пациент без сознания, бригада на месте, карта нужна для анамнеза"
+                }
+            }
+        },
+        "models.FilterResponse-array_entities_OneCPatientListItem": {
             "type": "object",
             "properties": {
-                "patients": {
+                "currentPage": {
+                    "type": "integer"
+                },
+                "hits": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/entities.OneCPatientListItem"
                     }
+                },
+                "hitsPerPage": {
+                    "type": "integer"
+                },
+                "totalHits": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
                 }
             }
         },
-        "entities.Policy": {
+        "models.MFACodeRequest": {
             "type": "object",
             "properties": {
-                "number": {
-                    "type": "string"
+                "code": {
+                    "type": "string",
+                    "example": "123456"
                 },
-                "type": {
-                    "type": "string"
+                "recovery_code": {
+                    "description": "Только для отключения",
+                    "type": "string",
+                    "example": "ABCDE-FGHIJ"
                 }
             }
         },
-        "entities.Relative": {
+        "models.MFAEnrollment": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string"
+                "provisioning_uri": {
+                    "description": "Содержимое QR-кода",
+                    "type": "string",
+                    "example": "otpauth://totp/MobileApp:%2B79622840761?..."
                 },
-                "status": {
-                    "type": "string"
+                "secret": {
+                    "description": "Секрет для ручного ввода",
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
                 }
             }
         },
-        "handlers.AuthCreditionals": {
+        "models.MFARecoveryCodesResponse": {
             "type": "object",
             "properties": {
-                "users": {
+                "recovery_codes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/handlers.OneCUser"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "ABCDE-FGHIJ"
+                    ]
                 }
             }
         },
-        "handlers.IncorrectDataError": {
+        "models.MFAVerifyRequest": {
+            "description": "Передаётся код из приложения (code) или одноразовый код восстановления (recovery_code)",
             "type": "object",
+            "required": [
+                "mfa_token"
+            ],
             "properties": {
-                "response": {
-                    "type": "object",
-                    "properties": {
-                        "code": {
-                            "type": "integer",
-                            "example": 401
-                        },
-                        "message": {
-                            "type": "string",
-                            "example": "Некорректные данные"
-                        }
-                    }
+                "code": {
+                    "type": "string",
+                    "example": "123456"
                 },
-                "status": {
-                    "description": "error",
+                "mfa_token": {
                     "type": "string",
-                    "example": "IncorrectDataError"
+                    "example": "eyJhbGciOi..."
+                },
+                "recovery_code": {
+                    "type": "string",
+                    "example": "ABCDE-FGHIJ"
                 }
             }
         },
-        "handlers.IncorrectFormatError": {
+        "models.MedCardBatchItem": {
+            "description": "Либо card, либо error; status — HTTP-код, который вернул бы GET /medcard/{pat_id}",
             "type": "object",
             "properties": {
-                "response": {
-                    "type": "object",
-                    "properties": {
-                        "code": {
-                            "type": "integer",
-                            "example": 400
-                        },
-                        "message": {
-                            "type": "string",
-                            "example": "Неверный формат запроса"
-                        }
-                    }
+                "card": {
+                    "$ref": "#/definitions/entities.OneCMedicalCard"
                 },
-                "status": {
-                    "description": "error",
+                "error": {
+                    "type": "string"
+                },
+                "patient_id": {
                     "type": "string",
-                    "example": "IncorrectFormatError"
+                    "example": "user1_id"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
                 }
             }
         },
-        "handlers.InternalServerError": {
+        "models.MedCardBatchRequest": {
             "type": "object",
+            "required": [
+                "patient_ids"
+            ],
             "properties": {
-                "response": {
-                    "type": "object",
-                    "properties": {
-                        "code": {
-                            "type": "integer",
-                            "example": 500
-                        },
-                        "message": {
-                            "type": "string",
-                            "example": "Внутренняя ошибка сервера"
-                        }
+                "patient_ids": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user1_id",
+                        "user2_id"
+                    ]
+                }
+            }
+        },
+        "models.MedCardEditBatchRequest": {
+            "type": "object",
+            "required": [
+                "edits"
+            ],
+            "properties": {
+                "edits": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.MedCardEditRequest"
                     }
+                }
+            }
+        },
+        "models.MedCardEditRequest": {
+            "description": "Правка с клиентским ID; повторная отправка с тем же ID не создаёт дубликат",
+            "type": "object",
+            "required": [
+                "base_version",
+                "client_edit_id",
+                "client_timestamp",
+                "patch",
+                "patient_id"
+            ],
+            "properties": {
+                "base_version": {
+                    "description": "Версия карты, на которой сделана правка",
+                    "type": "integer",
+                    "example": 3
                 },
-                "status": {
-                    "description": "error",
+                "client_edit_id": {
                     "type": "string",
-                    "example": "InternalServerError"
+                    "maxLength": 64,
+                    "example": "5f0c6a1e-7d2b-4c1a-9b1e-2a7f3c9d8e11"
+                },
+                "client_timestamp": {
+                    "description": "Время правки на устройстве, только для показа",
+                    "type": "string",
+                    "example": "2024-05-15T14:30:00Z"
+                },
+                "patch": {
+                    "description": "JSON Merge Patch (RFC 7396)",
+                    "type": "object"
+                },
+                "patient_id": {
+                    "type": "string",
+                    "example": "user1_id"
+                }
+            }
+        },
+        "models.MergePatientsRequest": {
+            "type": "object",
+            "required": [
+                "survivor_id"
+            ],
+            "properties": {
+                "survivor_id": {
+                    "description": "Запись, которая остаётся; вторая запись пары переносится в неё",
+                    "type": "string"
                 }
             }
         },
-        "handlers.OneCUser": {
+        "models.OneCUser": {
             "type": "object",
+            "required": [
+                "id",
+                "login"
+            ],
             "properties": {
+                "clinic": {
+                    "type": "string",
+                    "example": "Поликлиника 1"
+                },
+                "crew": {
+                    "type": "string",
+                    "example": "Бригада 3"
+                },
+                "disabled": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "doctor_cert_number": {
+                    "description": "Номер сертификата врача, по которому он указан лечащим в медкартах",
+                    "type": "string",
+                    "example": "POL1"
+                },
+                "full_name": {
+                    "description": "Профиль сотрудника, показывается в приложении (GET /me)",
+                    "type": "string",
+                    "example": "Иванов Иван Иванович"
+                },
+                "id": {
+                    "description": "Стабильный ID пользователя в 1С",
+                    "type": "string",
+                    "example": "a3f1c2d4-0001"
+                },
                 "login": {
                     "type": "string",
                     "example": "doc1"
@@ -778,144 +3796,346 @@ const docTemplate = `{
                 "password": {
                     "type": "string",
                     "example": "secret123"
+                },
+                "password_hash": {
+                    "type": "string",
+                    "example": "$2a$10$N9qo8uLOickgx2ZMRZoMye..."
+                },
+                "role": {
+                    "description": "doctor, paramedic, dispatcher, admin, integration; новым — doctor, у существующих пустая роль не меняется",
+                    "type": "string",
+                    "example": "doctor"
+                },
+                "specialization": {
+                    "type": "string",
+                    "example": "Терапевт"
                 }
             }
         },
-        "models.Call": {
+        "models.Patient": {
             "type": "object",
             "properties": {
-                "address": {
-                    "description": "Адрес (адрес вызова)",
+                "age": {
+                    "description": "Возраст",
                     "type": "string"
                 },
-                "call_id": {
+                "birth_date": {
+                    "description": "Дата рождения",
                     "type": "string"
                 },
-                "patient_count": {
-                    "description": "Кол-во пациентов",
-                    "type": "integer"
+                "certificate": {
+                    "description": "Сертификат",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.Certificate"
+                        }
+                    ]
                 },
-                "patients": {
-                    "description": "Данные пациентов",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Patient"
+                "full_name": {
+                    "description": "ФИО",
+                    "type": "string"
+                },
+                "gender": {
+                    "description": "Пол: true — мужской, false — женский",
+                    "type": "boolean"
+                },
+                "invalid_fields": {
+                    "description": "Поля, не прошедшие проверку: имя поля → значение, как его передала 1С. Само поле очищается",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
                     }
                 },
+                "patient_id": {
+                    "description": "ID пациента в 1С (если пациент известен)",
+                    "type": "string"
+                },
                 "phone": {
-                    "description": "Телефон пациента",
+                    "description": "Телефон",
                     "type": "string"
                 },
-                "status": {
-                    "description": "Статус вызова",
+                "policy": {
+                    "description": "Полис",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.CallStatus"
+                            "$ref": "#/definitions/entities.Policy"
                         }
                     ]
+                },
+                "snils": {
+                    "description": "СНИЛС",
+                    "type": "string"
                 }
             }
         },
-        "models.CallStatus": {
+        "models.PatientRecord": {
+            "type": "object",
+            "properties": {
+                "birth_date": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "policy": {
+                    "type": "string"
+                },
+                "snils": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/models.PatientRecordSource"
+                }
+            }
+        },
+        "models.PatientRecordSource": {
             "type": "string",
             "enum": [
-                "Выполнен",
-                "В работе"
+                "list",
+                "call"
             ],
+            "x-enum-comments": {
+                "PatientSourceCall": "Пациент, добавленный бригадой в вызов, которого нет в списке",
+                "PatientSourceList": "Список пациентов из 1С (с документами из медкарты)"
+            },
             "x-enum-varnames": [
-                "CallStatusCompleted",
-                "CallStatusWork"
+                "PatientSourceList",
+                "PatientSourceCall"
             ]
         },
-        "models.DoctorAuthResponse": {
-            "description": "Ответ с данными авторизованного врача",
+        "models.PatientSearchHit": {
+            "description": "Сначала идут точные совпадения по СНИЛС, полису или телефону, затем — по похожести ФИО",
             "type": "object",
             "properties": {
-                "id": {
-                    "description": "ID врача",
-                    "type": "integer",
-                    "example": 1
-                },
-                "token": {
-                    "description": "JWT токен",
+                "matched_by": {
+                    "description": "name, snils, policy, phone",
                     "type": "string",
-                    "example": "eyJhbGciOi..."
+                    "example": "name"
+                },
+                "patient": {
+                    "$ref": "#/definitions/entities.OneCPatientListItem"
+                },
+                "score": {
+                    "description": "Похожесть ФИО от 0 до 1, у точных совпадений 1",
+                    "type": "number",
+                    "example": 0.72
                 }
             }
         },
-        "models.DoctorLoginRequest": {
-            "description": "Запрос для входа врача в систему",
+        "models.QuickAccessPatient": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "description": "Когда добавлен в избранное",
+                    "type": "string"
+                },
+                "age": {
+                    "$ref": "#/definitions/entities.PatientAge"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "favorite": {
+                    "type": "boolean"
+                },
+                "full_name": {
+                    "description": "Из списка пациентов, для пациентов вне списка — из медкарты",
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "view_count": {
+                    "description": "Просмотров за срок хранения",
+                    "type": "integer"
+                },
+                "viewed_at": {
+                    "description": "Последний просмотр медкарты",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RefreshTokenRequest": {
+            "description": "Refresh-токен одноразовый: повторное использование отзывает все токены этого входа",
             "type": "object",
             "required": [
-                "password",
-                "phone"
+                "device_id",
+                "refresh_token"
             ],
             "properties": {
-                "password": {
-                    "description": "Пароль",
+                "device_id": {
+                    "description": "Должен совпадать с устройством входа",
                     "type": "string",
-                    "example": "123"
+                    "example": "a1b2c3d4"
                 },
-                "phone": {
-                    "description": "Логин (телефон)",
+                "refresh_token": {
                     "type": "string",
-                    "example": "+79622840765"
+                    "example": "Zk9yZXZlci..."
                 }
             }
         },
-        "models.Patient": {
+        "models.SessionInfo": {
+            "description": "Сеанс соответствует входу на одном устройстве",
             "type": "object",
             "properties": {
-                "age": {
-                    "description": "Возраст",
-                    "type": "string"
+                "app_version": {
+                    "type": "string",
+                    "example": "1.2.3"
                 },
-                "birth_date": {
-                    "description": "Дата рождения",
+                "created_at": {
                     "type": "string"
                 },
-                "certificate": {
-                    "description": "Сертификат",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/entities.Certificate"
-                        }
-                    ]
+                "current": {
+                    "description": "Сеанс, из которого сделан запрос",
+                    "type": "boolean",
+                    "example": true
                 },
-                "full_name": {
-                    "description": "ФИО",
+                "device_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4"
+                },
+                "expires_at": {
                     "type": "string"
                 },
-                "gender": {
-                    "description": "Пол: true — мужской, false — женский",
-                    "type": "boolean"
+                "id": {
+                    "type": "string",
+                    "example": "3f1c2d4e-5a6b-4c7d-8e9f-0a1b2c3d4e5f"
                 },
-                "phone": {
-                    "description": "Телефон",
+                "ip": {
+                    "type": "string",
+                    "example": "10.0.0.15"
+                },
+                "last_used_at": {
                     "type": "string"
                 },
-                "policy": {
-                    "description": "Полис",
+                "platform": {
+                    "type": "string",
+                    "example": "android"
+                }
+            }
+        },
+        "models.SyncDeletion": {
+            "description": "Медкарта или вызов удаляются с устройства и тогда, когда пользователь потерял к ним доступ: вызов завершён или сменился лечащий врач",
+            "type": "object",
+            "properties": {
+                "entity": {
+                    "description": "patient, medical_card, call",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entities.Policy"
+                            "$ref": "#/definitions/entities.SyncEntity"
                         }
                     ]
                 },
-                "snils": {
-                    "description": "СНИЛС",
+                "id": {
+                    "description": "patient_id или call_id",
                     "type": "string"
                 }
             }
         },
-        "models.PatientListResponse": {
+        "models.SyncResponse": {
+            "description": "Клиент сначала применяет deleted, затем добавляет или заменяет записи из остальных списков. Если has_more, следующую порцию нужно запросить сразу с новым token. При reset клиент очищает локальные данные и синхронизируется заново с пустым since",
             "type": "object",
             "properties": {
-                "patient": {
+                "calls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CallListItem"
+                    }
+                },
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SyncDeletion"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "medical_cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.OneCMedicalCard"
+                    }
+                },
+                "patients": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/entities.OneCPatientListItem"
                     }
+                },
+                "reset": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "description": "since для следующего запроса",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UnlockLoginRequest": {
+            "description": "Передаётся логин, IP-адрес или оба",
+            "type": "object",
+            "properties": {
+                "ip": {
+                    "type": "string",
+                    "example": "10.0.0.15"
+                },
+                "login": {
+                    "type": "string",
+                    "example": "+79622840761"
+                }
+            }
+        },
+        "models.UserSyncRequest": {
+            "description": "Без full передаются только изменённые пользователи. С full=true список полный, и все отсутствующие в нём пользователи отключаются",
+            "type": "object",
+            "properties": {
+                "full": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OneCUser"
+                    }
+                }
+            }
+        },
+        "models.UserSyncResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "disabled": {
+                    "description": "Отключены явно или отсутствуют в полной выгрузке",
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 15
+                }
+            }
+        },
+        "models.VersionResponse": {
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "description": "Ключ — платформа: ios, android",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.ClientVersions"
+                    }
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0.0"
                 }
             }
         }
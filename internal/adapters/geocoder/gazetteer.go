@@ -0,0 +1,104 @@
+package geocoder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+)
+
+// GazetteerEntry — запись справочника адресов. Без дома — координаты улицы, без улицы — населённого пункта
+type GazetteerEntry struct {
+	Region string  `json:"region"`
+	City   string  `json:"city"`
+	Street string  `json:"street"`
+	House  string  `json:"house"`
+	Lat    float64 `json:"lat"`
+	Lon    float64 `json:"lon"`
+}
+
+// GazetteerGeocoder — офлайн-геокодер по локальному справочнику (JSON-массив GazetteerEntry).
+// Работает без сети: для разработки, тестов и как запасной вариант
+type GazetteerGeocoder struct {
+	byPlace map[string]GazetteerEntry // город|улица|дом
+	// Те же записи без города — для адресов, где город не указан. Неоднозначные ключи удалены
+	byPlaceNoCity map[string]GazetteerEntry
+}
+
+// NewGazetteerGeocoder загружает справочник из файла path
+func NewGazetteerGeocoder(path string) (interfaces.Geocoder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gazetteer: %w", err)
+	}
+	var entries []GazetteerEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse gazetteer %s: %w", path, err)
+	}
+
+	g := &GazetteerGeocoder{
+		byPlace:       make(map[string]GazetteerEntry, len(entries)),
+		byPlaceNoCity: make(map[string]GazetteerEntry, len(entries)),
+	}
+	ambiguous := map[string]bool{}
+	for _, entry := range entries {
+		g.byPlace[gazetteerKey(entry.City, entry.Street, entry.House)] = entry
+
+		key := gazetteerKey("", entry.Street, entry.House)
+		if entry.Street == "" || ambiguous[key] {
+			continue
+		}
+		if _, exists := g.byPlaceNoCity[key]; exists {
+			ambiguous[key] = true
+			delete(g.byPlaceNoCity, key)
+			continue
+		}
+		g.byPlaceNoCity[key] = entry
+	}
+	return g, nil
+}
+
+// Geocode ищет координаты дома, если его нет в справочнике — улицы, затем населённого пункта
+func (g *GazetteerGeocoder) Geocode(_ context.Context, raw string) (*entities.Address, error) {
+	addr := parseAddress(raw)
+
+	index := g.byPlace
+	if addr.City == "" {
+		index = g.byPlaceNoCity
+	}
+
+	type level struct{ street, house, precision string }
+	var levels []level
+	if addr.Street != "" && addr.House != "" {
+		levels = append(levels, level{addr.Street, addr.House, entities.AddressPrecisionHouse})
+	}
+	if addr.Street != "" {
+		levels = append(levels, level{addr.Street, "", entities.AddressPrecisionStreet})
+	}
+	if addr.City != "" {
+		levels = append(levels, level{"", "", entities.AddressPrecisionCity})
+	}
+
+	for _, level := range levels {
+		entry, ok := index[gazetteerKey(addr.City, level.street, level.house)]
+		if !ok {
+			continue
+		}
+
+		lat, lon := entry.Lat, entry.Lon
+		addr.Lat, addr.Lon = &lat, &lon
+		addr.Precision = level.precision
+		if addr.Region == "" {
+			addr.Region = entry.Region
+		}
+		break
+	}
+	return &addr, nil
+}
+
+func gazetteerKey(city, street, house string) string {
+	return placeKey(city) + "|" + placeKey(street) + "|" + houseKey(house)
+}
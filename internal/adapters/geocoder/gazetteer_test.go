@@ -0,0 +1,73 @@
+package geocoder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+)
+
+func TestGazetteerGeocode(t *testing.T) {
+	g, err := NewGazetteerGeocoder("testdata/gazetteer.json")
+	if err != nil {
+		t.Fatalf("NewGazetteerGeocoder: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		raw       string
+		precision string
+		lat, lon  float64
+	}{
+		{"house", "г. Москва, ул. Тестовая, д. 3", entities.AddressPrecisionHouse, 55.749689, 37.535490},
+		{"house with building", "Москва, Ленина улица, дом 5 корп. 2", entities.AddressPrecisionHouse, 55.708472, 37.653201},
+		{"unknown house falls back to street", "г. Москва, ул. Тестовая, д. 99", entities.AddressPrecisionStreet, 55.749792, 37.537057},
+		{"street without house", "г. Москва, ул. Тестовая", entities.AddressPrecisionStreet, 55.749792, 37.537057},
+		{"unknown street falls back to city", "г. Москва, ул. Неизвестная, д. 1", entities.AddressPrecisionCity, 55.755864, 37.617698},
+		{"city only", "г. Москва", entities.AddressPrecisionCity, 55.755864, 37.617698},
+		{"address without city", "ул. Тестовая, 5", entities.AddressPrecisionHouse, 55.749857, 37.536761},
+		{"not in gazetteer", "г. Казань, ул. Баумана, д. 1", "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := g.Geocode(context.Background(), tt.raw)
+			if err != nil {
+				t.Fatalf("Geocode(%q) error: %v", tt.raw, err)
+			}
+			if addr.Precision != tt.precision {
+				t.Errorf("precision = %q, want %q", addr.Precision, tt.precision)
+			}
+			if tt.precision == "" {
+				if addr.HasCoordinates() {
+					t.Errorf("coordinates = %v,%v, want none", *addr.Lat, *addr.Lon)
+				}
+				return
+			}
+			if !addr.HasCoordinates() || *addr.Lat != tt.lat || *addr.Lon != tt.lon {
+				t.Errorf("coordinates = %v,%v, want %v,%v", addr.Lat, addr.Lon, tt.lat, tt.lon)
+			}
+		})
+	}
+}
+
+func TestGazetteerKeepsParsedParts(t *testing.T) {
+	g, err := NewGazetteerGeocoder("testdata/gazetteer.json")
+	if err != nil {
+		t.Fatalf("NewGazetteerGeocoder: %v", err)
+	}
+
+	addr, err := g.Geocode(context.Background(), "г. Москва, ул. Тестовая, д. 3, кв. 12, эт. 4")
+	if err != nil {
+		t.Fatalf("Geocode error: %v", err)
+	}
+	if addr.Street != "ул. Тестовая" || addr.House != "3" || addr.Flat != "12" || addr.Floor != "4" {
+		t.Errorf("parts = %+v, want street, house, flat and floor from the input", addr)
+	}
+}
+
+func TestNewGazetteerGeocoderErrors(t *testing.T) {
+	if _, err := NewGazetteerGeocoder("testdata/missing.json"); err == nil {
+		t.Error("NewGazetteerGeocoder(missing file) = nil error, want error")
+	}
+}
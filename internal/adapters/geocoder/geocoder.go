@@ -0,0 +1,21 @@
+package geocoder
+
+import (
+	"context"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+)
+
+// ParseOnlyGeocoder только разбирает адрес на части, координат не находит.
+// Используется, когда геокодер не настроен
+type ParseOnlyGeocoder struct{}
+
+func NewParseOnlyGeocoder() interfaces.Geocoder {
+	return ParseOnlyGeocoder{}
+}
+
+func (ParseOnlyGeocoder) Geocode(_ context.Context, raw string) (*entities.Address, error) {
+	addr := parseAddress(raw)
+	return &addr, nil
+}
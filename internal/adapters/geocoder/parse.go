@@ -0,0 +1,157 @@
+package geocoder
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+)
+
+// Обозначения частей адреса. Слова сравниваются в нижнем регистре без точки на конце
+var (
+	regionWords = map[string]bool{"обл": true, "область": true, "край": true, "респ": true, "республика": true, "ао": true}
+	cityWords   = map[string]bool{"г": true, "город": true, "пгт": true, "пос": true, "поселок": true, "посёлок": true, "с": true, "село": true, "дер": true, "деревня": true}
+	streetWords = map[string]bool{
+		"ул": true, "улица": true, "пр-т": true, "просп": true, "проспект": true, "пер": true, "переулок": true,
+		"ш": true, "шоссе": true, "б-р": true, "бульвар": true, "наб": true, "набережная": true, "пл": true,
+		"площадь": true, "проезд": true, "мкр": true, "микрорайон": true, "тупик": true, "аллея": true,
+	}
+
+	// Слова, с которых начинается часть, даже если она не отделена запятой: "д. 5 кв. 12"
+	unitWords = map[string]string{
+		"д": "house", "дом": "house",
+		"корп": "building", "корпус": "building", "к": "building", "стр": "building", "строение": "building",
+		"кв": "flat", "квартира": "flat", "оф": "flat", "офис": "flat",
+		"под": "entrance", "подъезд": "entrance", "п-д": "entrance",
+		"эт": "floor", "этаж": "floor",
+		"домофон": "intercom", "код": "intercom",
+	}
+	// Внутри части новую часть начинают только однозначные слова: "К." в "ул. К. Маркса" — не корпус
+	splitWords = map[string]bool{
+		"кв": true, "квартира": true, "под": true, "подъезд": true, "эт": true, "этаж": true,
+		"домофон": true, "корп": true, "корпус": true, "стр": true, "строение": true,
+	}
+)
+
+// parseAddress разбирает строку вида "г. Москва, ул. Ленина, д. 5 к2, кв. 12, под. 2, эт. 3, домофон 12К".
+// Нераспознанные части пропускаются, строка адреса при этом остаётся без изменений у вызывающего
+func parseAddress(raw string) entities.Address {
+	var addr entities.Address
+	for _, segment := range addressSegments(raw) {
+		words := strings.Fields(segment)
+		first := normalizeWord(words[0])
+		value := strings.Join(words[1:], " ")
+
+		// "д. 5" — дом, "д. Ивановка" — деревня
+		if kind, ok := unitWords[first]; ok && value != "" && (kind != "house" || startsWithDigit(value)) {
+			setUnit(&addr, kind, value)
+			continue
+		}
+
+		switch {
+		case hasWord(words, regionWords):
+			addr.Region = segment
+		case hasWord(words, streetWords):
+			addr.Street = segment
+		case hasWord(words, cityWords):
+			addr.City = segment
+		case addr.Street != "" && addr.House == "" && startsWithDigit(segment):
+			// "ул. Ленина, 5"
+			addr.House = segment
+		case addr.City == "" && addr.Street == "":
+			// Первая часть без обозначения обычно населённый пункт: "Москва, ул. Ленина, 5"
+			addr.City = segment
+		}
+	}
+	return addr
+}
+
+func setUnit(addr *entities.Address, kind, value string) {
+	switch kind {
+	case "house":
+		addr.House = value
+	case "building":
+		if addr.House != "" {
+			addr.House += " к" + value
+		}
+	case "flat":
+		addr.Flat = value
+	case "entrance":
+		addr.Entrance = value
+	case "floor":
+		addr.Floor = value
+	case "intercom":
+		addr.IntercomCode = value
+	}
+}
+
+// addressSegments делит адрес по запятым, а части — перед однозначными словами из splitWords
+func addressSegments(raw string) []string {
+	var segments []string
+	for _, part := range strings.Split(raw, ",") {
+		var current []string
+		for _, word := range strings.Fields(part) {
+			if len(current) > 0 && splitWords[normalizeWord(word)] {
+				segments = append(segments, strings.Join(current, " "))
+				current = nil
+			}
+			current = append(current, word)
+		}
+		if len(current) > 0 {
+			segments = append(segments, strings.Join(current, " "))
+		}
+	}
+	return segments
+}
+
+func hasWord(words []string, set map[string]bool) bool {
+	for _, word := range words {
+		if set[normalizeWord(word)] {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeWord(word string) string {
+	return strings.TrimRight(strings.ToLower(word), ".")
+}
+
+func startsWithDigit(s string) bool {
+	for _, r := range s {
+		return unicode.IsDigit(r)
+	}
+	return false
+}
+
+// placeKey — ключ населённого пункта или улицы для сравнения: без обозначений ("г.", "ул."),
+// знаков препинания и регистра, ё как е. "ул. Ленина" и "Ленина улица" дают "ленина"
+func placeKey(s string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	}) {
+		if cityWords[word] || streetWords[word] || regionWords[word] {
+			continue
+		}
+		words = append(words, strings.ReplaceAll(word, "ё", "е"))
+	}
+	return strings.Join(words, " ")
+}
+
+// houseKey — ключ номера дома: "5 корп. 2", "5к2" и "5 К 2" дают "5к2"
+func houseKey(s string) string {
+	s = strings.ToLower(s)
+	for _, r := range []struct{ from, to string }{
+		{"строение", "с"}, {"стр", "с"}, {"корпус", "к"}, {"корп", "к"},
+	} {
+		s = strings.ReplaceAll(s, r.from, r.to)
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '/' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
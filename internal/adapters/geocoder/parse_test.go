@@ -0,0 +1,94 @@
+package geocoder
+
+import (
+	"testing"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want entities.Address
+	}{
+		{
+			name: "all parts",
+			raw:  "Московская обл., г. Подольск, ул. Ленина, д. 5 к2, кв. 12, под. 2, эт. 3, домофон 12К",
+			want: entities.Address{
+				Region: "Московская обл.", City: "г. Подольск", Street: "ул. Ленина", House: "5 к2",
+				Flat: "12", Entrance: "2", Floor: "3", IntercomCode: "12К",
+			},
+		},
+		{
+			name: "parts without commas",
+			raw:  "г. Москва, ул. Ленина, д. 5 кв. 12 эт. 3",
+			want: entities.Address{City: "г. Москва", Street: "ул. Ленина", House: "5", Flat: "12", Floor: "3"},
+		},
+		{
+			name: "house without designation",
+			raw:  "Москва, ул. Ленина, 5",
+			want: entities.Address{City: "Москва", Street: "ул. Ленина", House: "5"},
+		},
+		{
+			name: "building as separate part",
+			raw:  "г. Москва, ул. Ленина, д. 5, корп. 2",
+			want: entities.Address{City: "г. Москва", Street: "ул. Ленина", House: "5 к2"},
+		},
+		{
+			name: "д. with number is a house",
+			raw:  "Московская обл., д. Ивановка, д. 5",
+			want: entities.Address{Region: "Московская обл.", City: "д. Ивановка", House: "5"},
+		},
+		{
+			name: "д. with name is a village",
+			raw:  "д. Ивановка, ул. Садовая, д. 1",
+			want: entities.Address{City: "д. Ивановка", Street: "ул. Садовая", House: "1"},
+		},
+		{
+			name: "initial in street name is not a building",
+			raw:  "г. Москва, ул. К. Маркса, д. 7",
+			want: entities.Address{City: "г. Москва", Street: "ул. К. Маркса", House: "7"},
+		},
+		{
+			name: "unrecognized parts are skipped",
+			raw:  "г. Москва, ул. Ленина, д. 5, у магазина",
+			want: entities.Address{City: "г. Москва", Street: "ул. Ленина", House: "5"},
+		},
+		{
+			name: "empty",
+			raw:  " , ",
+			want: entities.Address{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAddress(tt.raw); got != tt.want {
+				t.Errorf("parseAddress(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceKey(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"ул. Ленина", "Ленина улица"},
+		{"г. Москва", "Москва"},
+		{"ул. Королёва", "улица Королева"},
+		{"ул. К. Маркса", "ул. к маркса"},
+	}
+	for _, tt := range tests {
+		if placeKey(tt.a) != placeKey(tt.b) {
+			t.Errorf("placeKey(%q) = %q, placeKey(%q) = %q, want equal", tt.a, placeKey(tt.a), tt.b, placeKey(tt.b))
+		}
+	}
+}
+
+func TestHouseKey(t *testing.T) {
+	for _, house := range []string{"5 к2", "5к2", "5 корп. 2", "5 К 2", "5 корпус 2"} {
+		if got := houseKey(house); got != "5к2" {
+			t.Errorf("houseKey(%q) = %q, want %q", house, got, "5к2")
+		}
+	}
+}
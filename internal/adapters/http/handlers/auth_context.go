@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	middleware "github.com/AlexanderMorozov1919/mobileapp/internal/middleware/jwt"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID возвращает ID пользователя, положенный в контекст middleware.JWTAuth
+func currentUserID(c *gin.Context) (uint, bool) {
+	value, ok := c.Get("user_id")
+	if !ok {
+		return 0, false
+	}
+
+	// Числа из jwt.MapClaims приходят как float64
+	switch id := value.(type) {
+	case float64:
+		if id <= 0 {
+			return 0, false
+		}
+		return uint(id), true
+	case uint:
+		return id, id > 0
+	default:
+		return 0, false
+	}
+}
+
+// authorizePatient проверяет доступ текущего пользователя к пациенту.
+// При отказе сам пишет ответ и возвращает false
+func (h *Handler) authorizePatient(c *gin.Context, patientID string, action entities.PatientAccessAction) bool {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return false
+	}
+
+	subject := models.AccessSubject{
+		UserID:     userID,
+		ClinicWide: middleware.HasPermission(c, string(entities.PermPatientsAll)),
+	}
+	err := h.usecase.AuthorizePatientAccess(c.Request.Context(), subject, patientID, action)
+	if errors.Is(err, errors.ErrForbidden) {
+		h.logger.Warn("Patient access denied", "user_id", userID, "patient_id", patientID, "action", action)
+		h.ErrorResponse(c, err, http.StatusForbidden, "access to patient denied", false)
+		return false
+	}
+	if err != nil {
+		h.InternalError(c, err)
+		return false
+	}
+	return true
+}
@@ -1,15 +1,21 @@
 package handlers
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
 	"github.com/gin-gonic/gin"
 )
 
 // LoginDoctor аутентифицирует врача
 // @Summary Вход в систему
 // @Description Аутентифицирует врача по номеру телефона и паролю
+// @Description Если у пользователя подключён TOTP или его роль требует второй фактор, вместо токенов
+// @Description возвращается mfa_required и mfa_token для /auth/mfa/verify
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -17,6 +23,7 @@ import (
 // @Success 200 {object} models.DoctorAuthResponse "Успешное создание"
 // @Failure 400 {object} IncorrectFormatError "Неверный формат запроса"
 // @Failure 401 {object} IncorrectDataError "Неверные учётные данные"
+// @Failure 429 {object} map[string]string "Слишком много неудачных попыток, см. заголовок Retry-After"
 // @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
 // @Router /auth [post]
 func (h *Handler) LoginDoctor(c *gin.Context) {
@@ -27,9 +34,17 @@ func (h *Handler) LoginDoctor(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Auth attempt", "phone", req.Phone)
+	req.ClientIP = c.ClientIP()
+	h.logger.Info("Auth attempt", "phone", req.Phone, "ip", req.ClientIP)
 
-	credentials, err := h.usecase.LoginDoctor(c.Request.Context(), req.Phone, req.Password)
+	credentials, err := h.usecase.LoginDoctor(c.Request.Context(), req)
+	var locked *errors.LoginLockedError
+	if errors.As(err, &locked) {
+		h.logger.Warn("Auth blocked", "phone", req.Phone, "ip", req.ClientIP, "retry_after", locked.RetryAfter)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		h.ErrorResponse(c, err, http.StatusTooManyRequests, "Too many failed login attempts", false)
+		return
+	}
 	if err != nil {
 		h.logger.Warn("Auth failed", "phone", req.Phone, "error", err)
 		h.ErrorResponse(c, err, http.StatusBadRequest, "Invalid credentials", true)
@@ -39,8 +54,84 @@ func (h *Handler) LoginDoctor(c *gin.Context) {
 	h.ResultResponse(c, "success", Object, credentials)
 }
 
+// RefreshToken обновляет пару токенов
+// @Summary Обновление токенов
+// @Description Обменивает refresh-токен на новую пару access/refresh. Refresh-токен одноразовый: при повторном использовании все токены этого входа отзываются.
+// @Description Токен принимается только с устройства, на котором выполнен вход (device_id)
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body models.RefreshTokenRequest true "Refresh-токен"
+// @Success 200 {object} models.DoctorAuthResponse "Новая пара токенов"
+// @Failure 400 {object} IncorrectFormatError "Неверный формат запроса"
+// @Failure 401 {object} IncorrectDataError "Refresh-токен недействителен, истёк или отозван"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /auth/refresh [post]
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Error decoding refresh request", "error", err)
+		h.ErrorResponse(c, err, http.StatusBadRequest, "Invalid request payload", true)
+		return
+	}
+	req.ClientIP = c.ClientIP()
+
+	credentials, err := h.usecase.RefreshTokens(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Warn("Token refresh failed", "error", err)
+		h.ErrorResponse(c, err, http.StatusUnauthorized, "Invalid refresh token", true)
+		return
+	}
+
+	h.ResultResponse(c, "success", Object, credentials)
+}
+
+// Logout завершает сеанс врача
+// @Summary Выход из системы
+// @Description Отзывает текущий access-токен и все refresh-токены этого входа
+// @Tags Auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 "Выход выполнен"
+// @Failure 401 {object} IncorrectDataError "Токен недействителен"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	session := models.TokenSession{
+		JTI:      c.GetString("jti"),
+		FamilyID: c.GetString("sid"),
+	}
+	if exp, ok := c.Get("exp"); ok {
+		session.ExpiresAt, _ = exp.(time.Time)
+	}
+
+	if err := h.usecase.Logout(c.Request.Context(), session); err != nil {
+		h.logger.Error("Logout failed", "error", err)
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to logout", true)
+		return
+	}
+
+	h.ResultResponse(c, "success", Empty, nil)
+}
+
+// GetVersionProject godoc
+// @Summary Версия сервера и поддерживаемые версии приложения
+// @Description Для каждой платформы: min_version — сборки ниже получают 426 на все запросы API,
+// @Description recommended_version — сборкам ниже предлагается обновиться (заголовок X-App-Update-Recommended)
+// @Tags Version
+// @Produce json
+// @Success 200 {object} models.VersionResponse
+// @Router /version [get]
 func (h *Handler) GetVersionProject(c *gin.Context) {
-	version := "1.2.3"
-	// version := h.usecase.GetVersion()
-	c.JSON(http.StatusOK, gin.H{"version": version})
+	clients := make(map[string]models.ClientVersions, len(h.clients.Platforms))
+	for platform, versions := range h.clients.Platforms {
+		clients[platform] = models.ClientVersions{
+			MinVersion:         versions.MinVersion,
+			RecommendedVersion: versions.RecommendedVersion,
+		}
+	}
+	c.JSON(http.StatusOK, models.VersionResponse{
+		Version: h.app.Version,
+		Clients: clients,
+	})
 }
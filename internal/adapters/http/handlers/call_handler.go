@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	middleware "github.com/AlexanderMorozov1919/mobileapp/internal/middleware/jwt"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCalls godoc
+// @Summary List emergency calls
+// @Description Recently updated calls first. Users with patients:all see every call, others only calls their crew is assigned to.
+// @Description Pages are addressed by the opaque next_cursor/prev_cursor from the previous response
+// @Tags Emergency
+// @Produce json
+// @Param cursor query string false "next_cursor or prev_cursor from the previous response"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Param with_total query bool false "Also return totalHits (extra COUNT query)"
+// @Success 200 {object} models.CursorPage[[]models.CallListItem]
+// @Failure 400 {object} IncorrectFormatError "Invalid cursor"
+// @Security ApiKeyAuth
+// @Router /emergency/calls [get]
+func (h *Handler) GetCalls(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	subject := models.AccessSubject{
+		UserID:     userID,
+		ClinicWide: middleware.HasPermission(c, string(entities.PermPatientsAll)),
+	}
+	calls, err := h.usecase.ListCalls(c.Request.Context(), subject, cursorPageRequest(c))
+	if errors.Is(err, validation.ErrInvalid) {
+		h.ErrorResponse(c, err, http.StatusBadRequest, "Invalid cursor", true)
+		return
+	}
+	if err != nil {
+		h.InternalError(c, err)
+		return
+	}
+
+	h.ResultResponse(c, "success", Object, calls)
+}
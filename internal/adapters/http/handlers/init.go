@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/config"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/middleware/appversion"
 	middleware "github.com/AlexanderMorozov1919/mobileapp/internal/middleware/jwt"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/middleware/logging"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/middleware/swagger"
@@ -24,10 +27,12 @@ type Handler struct {
 	logger  *logging.Logger
 	usecase interfaces.Usecases
 	service interfaces.Service
+	app     config.AppConfig
+	clients config.ClientsConfig
 }
 
 // NewHandler создает новый экземпляр Handler со всеми зависимостями
-func NewHandler(usecase interfaces.Usecases, parentLogger *logging.Logger, service interfaces.Service) *Handler {
+func NewHandler(usecase interfaces.Usecases, parentLogger *logging.Logger, service interfaces.Service, cfg *config.Config) *Handler {
 	handlerLogger := parentLogger.WithPrefix("HANDLER")
 	handlerLogger.Info("Handler initialized",
 		"component", "GENERAL",
@@ -36,19 +41,28 @@ func NewHandler(usecase interfaces.Usecases, parentLogger *logging.Logger, servi
 		logger:  handlerLogger,
 		usecase: usecase,
 		service: service,
+		app:     cfg.App,
+		clients: cfg.Clients,
 	}
 }
 
 // ProvideRouter создает и настраивает маршруты
-func ProvideRouter(h *Handler, ws *WebsocketHandler, cfg *config.Config, swagCfg *swagger.Config) http.Handler {
-	r := gin.Default()
+func ProvideRouter(h *Handler, ws *WebsocketHandler, cfg *config.Config, swagCfg *swagger.Config) (http.Handler, error) {
+	// Без стандартного логгера gin: он пишет URI целиком, а в параметрах WebSocket-запроса передаётся токен
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	// От IP клиента зависит защита входа от перебора, поэтому X-Forwarded-For принимается только от своих прокси
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
 
 	// CORS
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.Server.AllowedOrigins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match", middleware.DeviceIDHeader, appversion.PlatformHeader, appversion.VersionHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", appversion.UpdateRecommendedHeader},
 		AllowCredentials: true,
 	}))
 
@@ -61,46 +75,103 @@ func ProvideRouter(h *Handler, ws *WebsocketHandler, cfg *config.Config, swagCfg
 	// Общая группа для API
 	baseRouter := r.Group("/api/v1")
 
-	protected := baseRouter.Group("/")
-	protected.Use(middleware.JWTAuth(cfg.JWTSecret))
-
-	//Версия
+	//Версия (доступна и устаревшим сборкам, чтобы они узнали о необходимости обновления)
 	baseRouter.GET("/version", h.GetVersionProject)
 
+	baseRouter.Use(appversion.RequireSupported(cfg.Clients))
+
+	protected := baseRouter.Group("/")
+	protected.Use(middleware.JWTAuth(cfg.JWTSecret, h.usecase))
+
 	// Авторизация
 	authGroup := baseRouter.Group("/auth")
 	authGroup.POST("/", h.LoginDoctor)
+	authGroup.POST("/refresh", h.RefreshToken)
+	authGroup.POST("/logout", middleware.JWTAuth(cfg.JWTSecret, h.usecase), h.Logout)
+	authGroup.POST("/mfa/verify", h.VerifyMFA) // Второй шаг входа
+
+	mfaGroup := authGroup.Group("/mfa", middleware.JWTAuth(cfg.JWTSecret, h.usecase))
+	mfaGroup.POST("/enroll", h.EnrollMFA)
+	mfaGroup.POST("/confirm", h.ConfirmMFA)
+	mfaGroup.POST("/disable", h.DisableMFA)
+
+	// Текущий пользователь и его сеансы
+	meGroup := protected.Group("/me")
+	meGroup.GET("", h.GetMe)
+	meGroup.GET("/sessions", h.GetMySessions)
+	meGroup.DELETE("/sessions/:session_id", h.RevokeMySession) // Удалённый выход
+
+	// Быстрый доступ к пациентам: недавние и избранные
+	myPatients := meGroup.Group("/patients", middleware.RequirePermission(string(entities.PermPatientsRead)))
+	myPatients.GET("/recent", h.GetRecentPatients)
+	myPatients.GET("/favorites", h.GetFavoritePatients)
+	myPatients.PUT("/favorites/:pat_id", h.AddFavoritePatient)
+	myPatients.DELETE("/favorites/:pat_id", h.RemoveFavoritePatient)
 
 	// WebSocket-группа
 	wsGroup := r.Group("/ws/notification")
-	// wsGroup.Use(middleware.JWTAuth(cfg.JWTSecret))
+	wsGroup.Use(middleware.TokenFromQuery(), middleware.JWTAuth(cfg.JWTSecret, h.usecase))
 
 	wsGroup.GET("/register/:user_id", ws.Register)
 	wsGroup.GET("/unregister/:user_id", ws.Unregister)
 
 	//Запросы от 1С
-	webhook := protected.Group("webhook")
+	webhook := protected.Group("webhook", middleware.RequirePermission(string(entities.PermOneCWebhook)))
 	webhook.POST("/onec/receptions", h.OneCWebhook)          // Получение заявок
 	webhook.POST("/onec/patients", h.OneCPatientListWebhook) // получение списка пациентов
 	webhook.POST("/onec/auth", h.OneCAuthWebhook)            // Получение списка авторизации
+	webhook.POST("/onec/roles", h.OneCRolesWebhook)          // Получение прав ролей
 
 	// Пациенты
-	patientGroup := protected.Group("/patients")
-	patientGroup.GET("", h.GetPatientList) // Отдаёт список всех пациентов c пагинацией (1С)
+	patientGroup := protected.Group("/patients", middleware.RequirePermission(string(entities.PermPatientsRead)))
+	patientGroup.GET("", h.GetPatientList)        // Отдаёт список всех пациентов c пагинацией (1С)
+	patientGroup.GET("/search", h.SearchPatients) // Нечёткий поиск по ФИО, СНИЛС, полису и телефону
+
+	// Идентификация по QR-коду браслета или штрихкоду полиса ОМС
+	patientGroup.GET("/resolve", h.ResolvePatientCode)
+	patientGroup.GET("/:pat_id/qr", h.GetPatientQR)
+
+	// Лента изменений для офлайн-синхронизации приложения
+	protected.GET("/sync", middleware.RequirePermission(string(entities.PermPatientsRead)), h.GetSync)
 
 	// Медкарты (Больше не формируется а получаются от 1С)
-	medCardGroup := protected.Group("/medcard")
-	medCardGroup.GET("/:pat_id", h.GetMedCardByPatientID)
-	medCardGroup.PUT("/:pat_id", h.UpdateMedCard)
+	medCardRead := protected.Group("/medcard", middleware.RequirePermission(string(entities.PermMedCardRead)))
+	medCardRead.GET("/edits", h.GetMedCardEdits)
+	medCardRead.GET("/:pat_id", h.GetMedCardByPatientID)
+	// Карты нескольких пациентов, например всех пострадавших вызова
+	medCardRead.POST("/batch", h.GetMedCardsBatch)
+	medCardRead.POST("/:pat_id/emergency-access", h.RequestEmergencyAccess) // Экстренный доступ с обоснованием
+
+	medCardWrite := protected.Group("/medcard", middleware.RequirePermission(string(entities.PermMedCardWrite)))
+	medCardWrite.POST("/edits", h.SubmitMedCardEdits) // Офлайн-правки с очередью синхронизации
+	medCardWrite.PUT("/:pat_id", h.UpdateMedCard)
+	medCardWrite.PATCH("/:pat_id", h.PatchMedCard)
+
+	// Отчёты для администратора
+	adminAudit := protected.Group("/admin", middleware.RequirePermission(string(entities.PermAuditRead)))
+	adminAudit.GET("/emergency-access", h.GetEmergencyAccessReport)
+	adminAudit.GET("/security-events", h.GetSecurityEvents)
+
+	adminUsers := protected.Group("/admin", middleware.RequirePermission(string(entities.PermUsersManage)))
+	adminUsers.POST("/login-locks/unlock", h.UnlockLogin) // Снятие блокировки входа
+
+	adminPatients := protected.Group("/admin/patients", middleware.RequirePermission(string(entities.PermPatientsMerge)))
+	adminPatients.GET("/duplicates", h.GetPatientDuplicates) // Очередь проверки дублей
+	adminPatients.POST("/duplicates/scan", h.ScanPatientDuplicates)
+	adminPatients.POST("/duplicates/:id/merge", h.MergePatientDuplicate)
+	adminPatients.POST("/duplicates/:id/dismiss", h.DismissPatientDuplicate)
 
 	// Выезд
-	emergencyGroup := protected.Group("/emergency")
+	emergencyRead := protected.Group("/emergency", middleware.RequirePermission(string(entities.PermEmergencyRead)))
+	emergencyWrite := protected.Group("/emergency", middleware.RequirePermission(string(entities.PermEmergencyWrite)))
+
+	emergencyRead.GET("/calls", h.GetCalls) // Вызовы бригады, по курсору
 
 	//Подписи пациентов
-	emergencyGroup.GET("/signature/:recep_id", h.GetSignature)
-	emergencyGroup.POST("/signature/:recep_id", h.SaveSignature)
+	emergencyRead.GET("/signature/:recep_id", h.GetSignature)
+	emergencyWrite.POST("/signature/:recep_id", h.SaveSignature)
 
 	// emergencyGroup.POST("/pdf/:rec_id", h.UploadPdf)
 
-	return r
+	return r, nil
 }
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMe возвращает текущего пользователя
+// @Summary Текущий пользователь
+// @Description Профиль вошедшего сотрудника из 1С: ФИО, специализация, поликлиника, бригада
+// @Tags Auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Device-ID header string true "ID устройства, к которому привязан токен"
+// @Success 200 {object} models.DoctorInfoResponse
+// @Failure 401 {object} IncorrectDataError "Токен недействителен"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /me [get]
+func (h *Handler) GetMe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, nil, http.StatusUnauthorized, "Unauthorized", true)
+		return
+	}
+
+	info, appErr := h.usecase.GetCurrentUser(c.Request.Context(), userID)
+	if appErr != nil {
+		h.ErrorResponse(c, appErr.Err, appErr.Code, appErr.Message, appErr.IsUserFacing)
+		return
+	}
+
+	h.ResultResponse(c, "success", Object, info)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// SubmitMedCardEdits godoc
+// @Summary Submit offline medical card edits
+// @Description Принимает правки, сделанные на устройстве (в том числе без связи), и ставит их в очередь на отправку в 1С.
+// @Description Правки отправляет фоновый воркер в порядке поступления на сервер (внутри пакета — в порядке массива),
+// @Description client_timestamp на порядок не влияет. Результат доступен через GET /medcard/edits.
+// @Description Правки с уже известным client_edit_id не дублируются. В ответе — текущий статус каждой правки
+// @Tags MedicalCard
+// @Accept json
+// @Produce json
+// @Param edits body models.MedCardEditBatchRequest true "Пакет правок"
+// @Success 202 {array} entities.MedCardEdit
+// @Failure 400 {object} IncorrectFormatError
+// @Failure 401 {object} IncorrectDataError
+// @Failure 403 {object} map[string]string "Нет доступа к пациенту одной из правок"
+// @Failure 500 {object} InternalServerError
+// @Security ApiKeyAuth
+// @Router /medcard/edits [post]
+func (h *Handler) SubmitMedCardEdits(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	var req models.MedCardEditBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	for _, edit := range req.Edits {
+		if !h.authorizePatient(c, edit.PatientID, entities.PatientAccessWrite) {
+			return
+		}
+	}
+
+	edits, err := h.usecase.EnqueueMedCardEdits(c.Request.Context(), userID, req.Edits)
+	if err != nil {
+		h.InternalError(c, err)
+		return
+	}
+	h.AcceptedResponse(c, "accepted", Array, edits)
+}
+
+// GetMedCardEdits godoc
+// @Summary Get sync status of offline medical card edits
+// @Tags MedicalCard
+// @Produce json
+// @Param ids query string false "Клиентские ID правок через запятую"
+// @Param status query string false "Фильтр по статусу (pending, applied, conflict, rejected)"
+// @Success 200 {array} entities.MedCardEdit
+// @Failure 401 {object} IncorrectDataError
+// @Failure 500 {object} InternalServerError
+// @Security ApiKeyAuth
+// @Router /medcard/edits [get]
+func (h *Handler) GetMedCardEdits(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	var ids []string
+	if raw := c.Query("ids"); raw != "" {
+		for _, id := range strings.Split(raw, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	status := entities.MedCardEditStatus(c.Query("status"))
+
+	edits, err := h.usecase.GetMedCardEdits(c.Request.Context(), userID, ids, status)
+	if err != nil {
+		h.InternalError(c, err)
+		return
+	}
+	h.ResultResponse(c, "success", Array, edits)
+}
@@ -1,9 +1,17 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	middleware "github.com/AlexanderMorozov1919/mobileapp/internal/middleware/jwt"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/mergepatch"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,8 +21,11 @@ import (
 // @Produce json
 // @Param pat_id path string true "Patient ID"
 // @Success 200 {object} entities.OneCMedicalCard
+// @Header 200 {string} ETag "Версия карты, передаётся в If-Match при изменении"
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Нет доступа к пациенту"
 // @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]interface{} "Запись объединена с другой: карта доступна под error.survivor_id"
 // @Failure 500 {object} map[string]string
 // @Security ApiKeyAuth
 // @Router /medcard/{pat_id} [get]
@@ -25,23 +36,126 @@ func (h *Handler) GetMedCardByPatientID(c *gin.Context) {
 		return
 	}
 
+	if !h.authorizePatient(c, patientID, entities.PatientAccessRead) {
+		return
+	}
+
 	card, err := h.usecase.GetMedCardByPatientID(c.Request.Context(), patientID)
+	var merged *errors.MergedError
+	if errors.As(err, &merged) {
+		c.JSON(http.StatusGone, gin.H{
+			"status": "error",
+			"error": gin.H{
+				"code":        http.StatusGone,
+				"message":     "patient record was merged",
+				"survivor_id": merged.SurvivorID,
+			},
+		})
+		return
+	}
 	if err != nil {
 		h.ErrorResponse(c, err, http.StatusBadRequest, "medical card not found", true)
 		return
 	}
+
+	// Список недавних пациентов не должен мешать выдаче карты
+	if userID, ok := currentUserID(c); ok {
+		if err := h.usecase.RecordPatientView(c.Request.Context(), userID, patientID); err != nil {
+			h.logger.Error("Failed to record patient view", "user_id", userID, "patient_id", patientID, "error", err)
+		}
+	}
+
+	c.Header("ETag", formatETag(card.Version))
 	h.ResultResponse(c, "success", Object, card)
 }
 
+// GetMedCardsBatch godoc
+// @Summary Get medical cards of several patients
+// @Description Карты из БД отдаются сразу, недостающие загружаются из 1С параллельно.
+// @Description Ошибка по одному пациенту (нет доступа — 403, нет в 1С — 404, запись объединена с другой — 410, 1С недоступна — 502) не прерывает пакет: у элемента заполняются status и error.
+// @Description Доступ лечащего врача проверяется по сохранённой карте: если карты ещё нет в БД, её нужно сначала открыть через GET /medcard/{pat_id}
+// @Tags MedicalCard
+// @Accept json
+// @Produce json
+// @Param request body models.MedCardBatchRequest true "ID пациентов (не больше 50)"
+// @Success 200 {array} models.MedCardBatchItem
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /medcard/batch [post]
+func (h *Handler) GetMedCardsBatch(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	var req models.MedCardBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.ErrorResponse(c, err, http.StatusBadRequest, "patient_ids must contain 1 to 50 IDs", true)
+		return
+	}
+
+	// Доступ проверяется до загрузки и только по данным БД (сохранённая карта, вызовы, экстренный доступ).
+	// Из 1С карты загружает только GetMedCardsBatch — параллельно и лишь для разрешённых пациентов
+	subject := models.AccessSubject{
+		UserID:     userID,
+		ClinicWide: middleware.HasPermission(c, string(entities.PermPatientsAll)),
+	}
+	items := make([]models.MedCardBatchItem, 0, len(req.PatientIDs))
+	seen := make(map[string]bool, len(req.PatientIDs))
+	var allowed []string
+	for _, patientID := range req.PatientIDs {
+		if seen[patientID] {
+			continue
+		}
+		seen[patientID] = true
+
+		err := h.usecase.AuthorizeCachedPatientAccess(c.Request.Context(), subject, patientID, entities.PatientAccessRead)
+		if errors.Is(err, errors.ErrForbidden) {
+			h.logger.Warn("Patient access denied", "user_id", userID, "patient_id", patientID, "action", entities.PatientAccessRead)
+			items = append(items, models.MedCardBatchItem{PatientID: patientID, Status: http.StatusForbidden, Error: "access to patient denied"})
+			continue
+		}
+		if err != nil {
+			h.InternalError(c, err)
+			return
+		}
+		items = append(items, models.MedCardBatchItem{PatientID: patientID})
+		allowed = append(allowed, patientID)
+	}
+
+	if len(allowed) > 0 {
+		// Usecase возвращает элементы в порядке allowed, они занимают места с нулевым статусом
+		fetched := h.usecase.GetMedCardsBatch(c.Request.Context(), allowed)
+		j := 0
+		for i := range items {
+			if items[i].Status == 0 {
+				items[i] = fetched[j]
+				j++
+			}
+		}
+	}
+
+	h.ResultResponse(c, "success", Array, items)
+}
+
 // UpdateMedCard godoc
 // @Summary Update medical card by patient ID
 // @Tags MedicalCard
 // @Accept json
 // @Produce json
 // @Param pat_id path string true "Patient ID"
+// @Param If-Match header string true "ETag карты, полученный при чтении"
 // @Param update body entities.OneCMedicalCard true "Medical card update data"
 // @Success 200 {object} map[string]string
+// @Header 200 {string} ETag "Новая версия карты"
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Нет доступа к пациенту"
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "Карта изменена другим пользователем"
+// @Failure 422 {object} ValidationError "Некорректный СНИЛС, полис, телефон или дата рождения"
+// @Failure 428 {object} map[string]string "Не передан заголовок If-Match"
 // @Failure 500 {object} map[string]string
 // @Security ApiKeyAuth
 // @Router /medcard/{pat_id} [put]
@@ -52,6 +166,15 @@ func (h *Handler) UpdateMedCard(c *gin.Context) {
 		return
 	}
 
+	if !h.authorizePatient(c, patientID, entities.PatientAccessWrite) {
+		return
+	}
+
+	expectedVersion, ok := h.parseIfMatch(c)
+	if !ok {
+		return
+	}
+
 	var card entities.OneCMedicalCard
 	if err := c.ShouldBindJSON(&card); err != nil {
 		h.ErrorResponse(c, err, http.StatusBadRequest, "invalid request body", true)
@@ -64,9 +187,115 @@ func (h *Handler) UpdateMedCard(c *gin.Context) {
 		return
 	}
 
-	if err := h.usecase.UpdateMedicalCard(c.Request.Context(), &card); err != nil {
-		h.ErrorResponse(c, err, http.StatusBadRequest, "failed to update medical card", true)
+	version, err := h.usecase.UpdateMedicalCard(c.Request.Context(), &card, expectedVersion)
+	if err != nil {
+		h.medCardUpdateError(c, err)
 		return
 	}
+	c.Header("ETag", formatETag(version))
 	h.ResultResponse(c, "success", Empty, nil)
 }
+
+// PatchMedCard godoc
+// @Summary Partially update medical card by patient ID
+// @Description Принимает JSON Merge Patch (RFC 7396). Разрешено менять только контакты
+// @Description (mobile_phone, additional_phone, email, address, workplace) и родственника (relative.status, relative.name)
+// @Description Части адреса (address_details) проверяются, строка address пересобирается из них; изменённая строка address заново разбирается на части
+// @Tags MedicalCard
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param pat_id path string true "Patient ID"
+// @Param If-Match header string true "ETag карты, полученный при чтении"
+// @Param patch body object true "Merge patch"
+// @Success 200 {object} entities.OneCMedicalCard
+// @Header 200 {string} ETag "Новая версия карты"
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Нет доступа к пациенту"
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "Карта изменена другим пользователем"
+// @Failure 422 {object} map[string]string "Патч меняет запрещённые поля или содержит некорректные значения"
+// @Failure 428 {object} map[string]string "Не передан заголовок If-Match"
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /medcard/{pat_id} [patch]
+func (h *Handler) PatchMedCard(c *gin.Context) {
+	patientID := c.Param("pat_id")
+	if patientID == "" {
+		h.ErrorResponse(c, http.ErrAbortHandler, http.StatusBadRequest, "patient_id is required", true)
+		return
+	}
+
+	if !h.authorizePatient(c, patientID, entities.PatientAccessWrite) {
+		return
+	}
+
+	expectedVersion, ok := h.parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		h.ErrorResponse(c, err, http.StatusBadRequest, "invalid request body", true)
+		return
+	}
+
+	card, err := h.usecase.PatchMedicalCard(c.Request.Context(), patientID, patch, expectedVersion)
+	if err != nil {
+		h.medCardUpdateError(c, err)
+		return
+	}
+	c.Header("ETag", formatETag(card.Version))
+	h.ResultResponse(c, "success", Object, card)
+}
+
+// parseIfMatch достаёт ожидаемую версию карты из заголовка If-Match.
+// При отсутствии или неверном формате заголовка сам отвечает клиенту и возвращает false
+func (h *Handler) parseIfMatch(c *gin.Context) (uint, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		h.ErrorResponse(c, nil, http.StatusPreconditionRequired, "If-Match header is required", false)
+		return 0, false
+	}
+
+	value := strings.TrimPrefix(strings.TrimSpace(header), "W/")
+	version, err := strconv.ParseUint(strings.Trim(value, `"`), 10, 64)
+	if err != nil {
+		h.ErrorResponse(c, err, http.StatusBadRequest, "invalid If-Match header", true)
+		return 0, false
+	}
+	return uint(version), true
+}
+
+// medCardUpdateError переводит ошибку изменения карты в HTTP-ответ
+func (h *Handler) medCardUpdateError(c *gin.Context, err error) {
+	var conflict *errors.VersionConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.Header("ETag", formatETag(conflict.CurrentVersion))
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"status": "error",
+			"error": gin.H{
+				"code":            http.StatusPreconditionFailed,
+				"message":         "medical card was modified by another user",
+				"current_version": conflict.CurrentVersion,
+			},
+		})
+	case errors.Is(err, errors.ErrVersionConflict):
+		h.ErrorResponse(c, err, http.StatusPreconditionFailed, "medical card was modified by another user", false)
+	case errors.Is(err, errors.ErrNotFound):
+		h.NotFound(c, err)
+	case errors.Is(err, mergepatch.ErrFieldNotAllowed):
+		h.ErrorResponse(c, err, http.StatusUnprocessableEntity, "field is not allowed to be changed", true)
+	case errors.Is(err, mergepatch.ErrInvalidPatch):
+		h.BadRequest(c, err)
+	case errors.Is(err, validation.ErrInvalid):
+		h.ErrorResponse(c, err, http.StatusUnprocessableEntity, "validation failed", true)
+	default:
+		h.ErrorResponse(c, err, http.StatusBadRequest, "failed to update medical card", true)
+	}
+}
+
+func formatETag(version uint) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
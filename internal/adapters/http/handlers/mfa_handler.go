@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyMFA завершает вход вторым фактором
+// @Summary Второй шаг входа (TOTP)
+// @Description Принимает MFA-токен из ответа /auth и код из приложения-аутентификатора или код восстановления.
+// @Description Если MFA подключался при этом входе, в ответе один раз приходят коды восстановления
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body models.MFAVerifyRequest true "MFA-токен и код"
+// @Success 200 {object} models.DoctorAuthResponse
+// @Failure 400 {object} IncorrectFormatError "Неверный формат запроса"
+// @Failure 401 {object} IncorrectDataError "Неверный код или MFA-токен"
+// @Failure 429 {object} map[string]string "Слишком много неудачных попыток, см. заголовок Retry-After"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /auth/mfa/verify [post]
+func (h *Handler) VerifyMFA(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.ErrorResponse(c, err, http.StatusBadRequest, "Invalid request payload", true)
+		return
+	}
+	req.ClientIP = c.ClientIP()
+
+	credentials, appErr := h.usecase.VerifyMFA(c.Request.Context(), req)
+	if appErr != nil {
+		var locked *errors.LoginLockedError
+		if errors.As(appErr, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		}
+		h.logger.Warn("MFA verification failed", "ip", req.ClientIP, "error", appErr)
+		h.ErrorResponse(c, appErr.Err, appErr.Code, appErr.Message, appErr.IsUserFacing)
+		return
+	}
+
+	h.ResultResponse(c, "success", Object, credentials)
+}
+
+// EnrollMFA начинает подключение второго фактора
+// @Summary Подключение TOTP
+// @Description Возвращает секрет и otpauth:// URI для QR-кода. Подключение завершается через /auth/mfa/confirm
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} models.MFAEnrollment
+// @Failure 401 {object} IncorrectDataError
+// @Failure 409 {object} map[string]string "MFA уже подключён"
+// @Failure 500 {object} InternalServerError
+// @Security ApiKeyAuth
+// @Router /auth/mfa/enroll [post]
+func (h *Handler) EnrollMFA(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	enrollment, appErr := h.usecase.EnrollMFA(c.Request.Context(), userID)
+	if appErr != nil {
+		h.ErrorResponse(c, appErr.Err, appErr.Code, appErr.Message, appErr.IsUserFacing)
+		return
+	}
+	h.ResultResponse(c, "success", Object, enrollment)
+}
+
+// ConfirmMFA подтверждает подключение второго фактора
+// @Summary Подтверждение TOTP
+// @Description Проверяет первый код из приложения и возвращает коды восстановления (показываются один раз)
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body models.MFACodeRequest true "Код из приложения"
+// @Success 200 {object} models.MFARecoveryCodesResponse
+// @Failure 400 {object} IncorrectFormatError
+// @Failure 401 {object} IncorrectDataError "Неверный код"
+// @Failure 409 {object} map[string]string "Подключение не начато или уже завершено"
+// @Failure 500 {object} InternalServerError
+// @Security ApiKeyAuth
+// @Router /auth/mfa/confirm [post]
+func (h *Handler) ConfirmMFA(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	codes, appErr := h.usecase.ConfirmMFA(c.Request.Context(), userID, req)
+	if appErr != nil {
+		h.ErrorResponse(c, appErr.Err, appErr.Code, appErr.Message, appErr.IsUserFacing)
+		return
+	}
+	h.ResultResponse(c, "success", Object, models.MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMFA отключает второй фактор
+// @Summary Отключение TOTP
+// @Description Требует код из приложения или код восстановления. Недоступно для ролей, которым MFA обязателен
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body models.MFACodeRequest true "Код из приложения или код восстановления"
+// @Success 200
+// @Failure 400 {object} IncorrectFormatError
+// @Failure 401 {object} IncorrectDataError "Неверный код"
+// @Failure 403 {object} map[string]string "MFA обязателен для роли"
+// @Failure 409 {object} map[string]string "MFA не подключён"
+// @Failure 500 {object} InternalServerError
+// @Security ApiKeyAuth
+// @Router /auth/mfa/disable [post]
+func (h *Handler) DisableMFA(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	if appErr := h.usecase.DisableMFA(c.Request.Context(), userID, req); appErr != nil {
+		h.ErrorResponse(c, appErr.Err, appErr.Code, appErr.Message, appErr.IsUserFacing)
+		return
+	}
+	h.ResultResponse(c, "success", Empty, nil)
+}
@@ -40,7 +40,7 @@ func LoggingMiddleware(parentLogger *logging.Logger) gin.HandlerFunc {
 				RemoteAddr: c.Request.RemoteAddr,
 				Method:     c.Request.Method,
 				Path:       c.Request.URL.Path,
-				Headers:    c.Request.Header,
+				Headers:    redactHeaders(c.Request.Header),
 			}
 
 			if infoJson, err := json.Marshal(reqInfo); err == nil {
@@ -65,3 +65,14 @@ func LoggingMiddleware(parentLogger *logging.Logger) gin.HandlerFunc {
 		)
 	}
 }
+
+// redactHeaders — копия заголовков без токенов и cookie для журнала
+func redactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "[REDACTED]")
+		}
+	}
+	return redacted
+}
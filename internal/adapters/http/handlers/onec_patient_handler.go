@@ -8,6 +8,8 @@ import (
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
 
@@ -35,41 +37,172 @@ func (h *Handler) OneCPatientListWebhook(c *gin.Context) {
 }
 
 // GetPatientList godoc
-// @Summary Get cached patient list from 1C with pagination
+// @Summary Get cached patient list from 1C with pagination, filtering and sorting
+// @Description filter: conditions "field.op.value" joined with "&$" (AND, in URL "%26$") or "|" (OR), grouped with parentheses.
+// @Description Fields: full_name, snils (digits only), policy.number (without spaces and dashes) — eq, ne, like, in, nin;
+// @Description birth_date (YYYY-MM-DD) — eq, ne, gt, gte, lt, lte, between; gender (true — male) — eq, ne.
+// @Description Lists for in, nin and between are comma-separated. Escape \ . , | ( ) & with a backslash.
+// @Description order: "field.asc|desc" joined with "&$", the same fields (default: full_name.asc).
+// @Description By default pages are addressed by number (OFFSET) and models.FilterResponse is returned.
+// @Description Sending the cursor parameter switches to cursor pagination and returns models.CursorPage: an empty cursor requests the first page,
+// @Description the next pages are addressed by the opaque next_cursor/prev_cursor from the previous response; a cursor is only valid with the same order
 // @Tags Patients
 // @Produce json
-// @Param page query int false "Page number (default: 1)"
+// @Param cursor query string false "Empty for the first page, then next_cursor or prev_cursor from the previous response"
 // @Param limit query int false "Items per page (default: 20, max: 100)"
-// @Success 200 {object} models.PatientListResponse
+// @Param with_total query bool false "Cursor pagination only: also return totalHits (extra COUNT query)"
+// @Param page query int false "Page number for OFFSET pagination (default: 1)"
+// @Param filter query string false "Filter, e.g. (full_name.like.Иванов|snils.in.12345678901,10987654321)&$birth_date.between.1980-01-01,1990-12-31"
+// @Param order query string false "Order, e.g. birth_date.desc"
+// @Success 200 {object} models.FilterResponse[[]entities.OneCPatientListItem] "Without cursor"
+// @Success 200 {object} models.CursorPage[[]entities.OneCPatientListItem] "With cursor"
+// @Failure 400 {object} IncorrectFormatError "Unknown field, operator or malformed value in filter/order, invalid cursor"
 // @Security ApiKeyAuth
 // @Router /patients [get]
 func (h *Handler) GetPatientList(c *gin.Context) {
-	// Получаем параметры пагинации
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
+	pageRequest := cursorPageRequest(c)
+	query := models.PatientListQuery{
+		Cursor:    pageRequest.Cursor,
+		WithTotal: pageRequest.WithTotal,
+		Limit:     pageRequest.Limit,
+		Filter:    c.Query("filter"),
+		Order:     c.Query("order"),
 	}
 
+	// Курсор — только по явному запросу: клиенты без параметров по-прежнему получают первую страницу по номеру
+	var response interface{}
+	var err error
+	if _, withCursor := c.GetQuery("cursor"); withCursor {
+		response, err = h.usecase.GetPatientListCursor(c.Request.Context(), query)
+	} else {
+		query.Page, err = strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || query.Page < 1 {
+			query.Page = 1
+		}
+		response, err = h.usecase.GetPatientListPage(c.Request.Context(), query)
+	}
+	if errors.Is(err, validation.ErrInvalid) {
+		h.ErrorResponse(c, err, http.StatusBadRequest, "Invalid filter, order or cursor", true)
+		return
+	}
+	if err != nil {
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to get patient list from cache", true)
+		return
+	}
+
+	h.ResultResponse(c, "success", Object, response)
+}
+
+// SearchPatients godoc
+// @Summary Fuzzy patient search
+// @Description Searches by full name tolerating typos, Latin transliteration and ё/е. Exact matches on SNILS, policy number or phone come first.
+// @Tags Patients
+// @Produce json
+// @Param q query string true "Name, SNILS, policy number or phone"
+// @Param limit query int false "Max results (default: 20, max: 50)"
+// @Success 200 {array} models.PatientSearchHit
+// @Failure 400 {object} IncorrectFormatError "Empty query"
+// @Security ApiKeyAuth
+// @Router /patients/search [get]
+func (h *Handler) SearchPatients(c *gin.Context) {
 	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	if err != nil || limit < 1 {
 		limit = 20
 	}
-	if limit > 100 {
-		limit = 100
+	if limit > 50 {
+		limit = 50
+	}
+
+	hits, err := h.usecase.SearchPatients(c.Request.Context(), c.Query("q"), limit)
+	if errors.Is(err, validation.ErrInvalid) {
+		h.ErrorResponse(c, err, http.StatusBadRequest, "Invalid search query", true)
+		return
+	}
+	if err != nil {
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to search patients", false)
+		return
+	}
+
+	h.ResultResponse(c, "success", Array, hits)
+}
+
+// GetPatientQR godoc
+// @Summary Signed patient QR code
+// @Description QR-код для браслета или карточки пациента. Код подписан сервером, его разбирает GET /patients/resolve
+// @Tags Patients
+// @Produce png
+// @Produce image/svg+xml
+// @Param pat_id path string true "Patient ID"
+// @Param format query string false "png (default) or svg"
+// @Param size query int false "Side in pixels, 128-1024 (default: 256)"
+// @Success 200 {file} binary
+// @Failure 400 {object} IncorrectFormatError "Unknown format"
+// @Failure 404 {object} map[string]string "Patient not found"
+// @Security ApiKeyAuth
+// @Router /patients/{pat_id}/qr [get]
+func (h *Handler) GetPatientQR(c *gin.Context) {
+	format := models.QRFormat(c.DefaultQuery("format", string(models.QRFormatPNG)))
+	contentType := "image/png"
+	switch format {
+	case models.QRFormatPNG:
+	case models.QRFormatSVG:
+		contentType = "image/svg+xml"
+	default:
+		h.ErrorResponse(c, validation.ErrInvalid, http.StatusBadRequest, "format must be png or svg", true)
+		return
 	}
 
-	offset := (page - 1) * limit
+	size, err := strconv.Atoi(c.DefaultQuery("size", "256"))
+	if err != nil || size < 128 {
+		size = 256
+	}
+	if size > 1024 {
+		size = 1024
+	}
 
-	// Получаем данные
-	patients, err := h.usecase.GetPatientListPage(c.Request.Context(), offset, limit)
+	image, err := h.usecase.GetPatientQR(c.Request.Context(), c.Param("pat_id"), format, size)
+	if errors.Is(err, errors.ErrNotFound) {
+		h.ErrorResponse(c, err, http.StatusNotFound, "Patient not found", true)
+		return
+	}
 	if err != nil {
-		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to get patient list from cache", true)
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to generate patient QR code", false)
 		return
 	}
 
-	response := models.PatientListResponse{
-		Patient: patients,
+	// Код идентифицирует пациента — промежуточным кэшам его хранить нельзя
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, contentType, image)
+}
+
+// ResolvePatientCode godoc
+// @Summary Identify patient by scanned code
+// @Description code — содержимое QR-кода браслета (подпись проверяется) или номер полиса ОМС со штрихкода полиса (16 цифр)
+// @Tags Patients
+// @Produce json
+// @Param code query string true "Scanned QR payload or OMS policy number"
+// @Success 200 {object} entities.OneCPatientListItem
+// @Failure 400 {object} IncorrectFormatError "Invalid code or signature"
+// @Failure 404 {object} map[string]string "Patient not found"
+// @Failure 409 {object} map[string]string "Policy number matches several patients"
+// @Security ApiKeyAuth
+// @Router /patients/resolve [get]
+func (h *Handler) ResolvePatientCode(c *gin.Context) {
+	patient, err := h.usecase.ResolvePatientCode(c.Request.Context(), c.Query("code"))
+	switch {
+	case errors.Is(err, validation.ErrInvalid):
+		h.ErrorResponse(c, err, http.StatusBadRequest, "Invalid patient code", true)
+		return
+	case errors.Is(err, errors.ErrNotFound):
+		h.ErrorResponse(c, err, http.StatusNotFound, "Patient not found", true)
+		return
+	case errors.Is(err, errors.ErrAmbiguous):
+		h.ErrorResponse(c, err, http.StatusConflict, "Policy number matches several patients", true)
+		return
+	case err != nil:
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to resolve patient code", false)
+		return
 	}
 
-	h.ResultResponse(c, "success", Object, response)
+	h.ResultResponse(c, "success", Object, patient)
 }
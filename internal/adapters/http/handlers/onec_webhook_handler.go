@@ -5,8 +5,9 @@ import (
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // OneCWebhook godoc
@@ -34,43 +35,79 @@ func (h *Handler) OneCWebhook(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
-type AuthCreditionals struct {
-	Users []OneCUser
-}
-type OneCUser struct {
-	Login    string `json:"login" example:"doc1"`
-	Password string `json:"password" example:"secret123"`
+// OneCRoles — соответствие ролей и прав из 1С (роль -> список прав)
+type OneCRoles struct {
+	Roles map[string][]string `json:"roles" binding:"required"`
 }
 
-// OneCAuthWebhook receives a list of users from 1C and syncs them into the system.
+// OneCAuthWebhook receives users from 1C and syncs them into the system.
 // @Summary Sync users from 1C
-// @Description Syncs a batch of users (login + password) received from 1C into the internal auth system.
+// @Description Creates, updates and disables users keyed by their stable 1C ID. Users are never deleted.
+// @Description With full=true every user missing from the list is disabled. Passwords may be sent pre-hashed (bcrypt) in password_hash.
 // @Tags Webhooks
 // @Accept json
 // @Produce json
-// @Param request body AuthCreditionals true "List of users to sync"
-// @Success 200
+// @Param request body models.UserSyncRequest true "Users to sync"
+// @Success 200 {object} models.UserSyncResult
 // @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string "Unknown role, duplicate ID or invalid password hash"
 // @Failure 500 {object} map[string]string
 // @Router /onec/auth [post]
 func (h *Handler) OneCAuthWebhook(c *gin.Context) {
-	var update AuthCreditionals
+	var update models.UserSyncRequest
+
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	result, err := h.usecase.SyncUsers(c.Request.Context(), update)
+	if errors.Is(err, validation.ErrInvalid) {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "sync failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// OneCRolesWebhook receives the role to permissions mapping from 1C.
+// @Summary Sync role permissions from 1C
+// @Description Replaces the role to permissions mapping. New permissions are applied to tokens issued after the sync.
+// @Description The integration role always keeps onec:webhook, so a faulty mapping cannot lock 1C out of the webhooks.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param request body OneCRoles true "Role to permissions mapping"
+// @Success 200
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string "Unknown role or permission"
+// @Failure 500 {object} map[string]string
+// @Router /onec/roles [post]
+func (h *Handler) OneCRolesWebhook(c *gin.Context) {
+	var update OneCRoles
 
 	if err := c.ShouldBindJSON(&update); err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
 		return
 	}
 
-	var users []entities.AuthUser
-	for _, u := range update.Users {
-		hash, _ := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
-		users = append(users, entities.AuthUser{
-			Login:    u.Login,
-			Password: string(hash),
-		})
+	mapping := make(map[entities.Role][]entities.Permission, len(update.Roles))
+	for role, permissions := range update.Roles {
+		for _, perm := range permissions {
+			mapping[entities.Role(role)] = append(mapping[entities.Role(role)], entities.Permission(perm))
+		}
 	}
 
-	if err := h.usecase.SyncUsers(c.Request.Context(), users); err != nil {
+	err := h.usecase.SyncRolePermissions(c.Request.Context(), mapping)
+	if errors.Is(err, validation.ErrInvalid) {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "sync failed"})
 		return
 	}
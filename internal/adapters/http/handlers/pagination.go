@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Размер страницы списков по умолчанию и максимальный
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// cursorPageRequest читает общие параметры постраничного вывода по курсору: cursor, limit, with_total
+func cursorPageRequest(c *gin.Context) models.CursorPageRequest {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	withTotal, _ := strconv.ParseBool(c.Query("with_total"))
+
+	return models.CursorPageRequest{
+		Cursor:    c.Query("cursor"),
+		Limit:     limit,
+		WithTotal: withTotal,
+	}
+}
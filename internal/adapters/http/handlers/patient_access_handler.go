@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestEmergencyAccess godoc
+// @Summary Request emergency (break-the-glass) access to a patient
+// @Description Выдаёт временный доступ к карте пациента, к которому пользователь не привязан.
+// @Description Обоснование обязательно; лечащий врач получает уведомление, доступ попадает в отчёт для администратора
+// @Tags MedicalCard
+// @Accept json
+// @Produce json
+// @Param pat_id path string true "Patient ID"
+// @Param request body models.EmergencyAccessRequest true "Обоснование"
+// @Success 200 {object} entities.EmergencyAccessGrant
+// @Failure 400 {object} IncorrectFormatError
+// @Failure 401 {object} IncorrectDataError
+// @Failure 500 {object} InternalServerError
+// @Security ApiKeyAuth
+// @Router /medcard/{pat_id}/emergency-access [post]
+func (h *Handler) RequestEmergencyAccess(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	patientID := c.Param("pat_id")
+	if patientID == "" {
+		h.ErrorResponse(c, http.ErrAbortHandler, http.StatusBadRequest, "patient_id is required", true)
+		return
+	}
+
+	var req models.EmergencyAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	grant, err := h.usecase.RequestEmergencyAccess(c.Request.Context(), userID, patientID, req.Reason)
+	if err != nil {
+		h.InternalError(c, err)
+		return
+	}
+
+	h.logger.Warn("Emergency patient access granted",
+		"user_id", userID,
+		"patient_id", patientID,
+		"expires_at", grant.ExpiresAt,
+	)
+	h.ResultResponse(c, "success", Object, grant)
+}
+
+// GetEmergencyAccessReport godoc
+// @Summary Emergency access report
+// @Description Все экстренные доступы к пациентам с обоснованием и числом обращений к данным — для проверки администратором
+// @Tags Admin
+// @Produce json
+// @Param from query string false "С даты (YYYY-MM-DD)"
+// @Param to query string false "По дату включительно (YYYY-MM-DD)"
+// @Param user_id query int false "ID пользователя"
+// @Param patient_id query string false "ID пациента"
+// @Success 200 {array} models.EmergencyAccessReportItem
+// @Failure 400 {object} IncorrectFormatError
+// @Failure 401 {object} IncorrectDataError
+// @Failure 500 {object} InternalServerError
+// @Security ApiKeyAuth
+// @Router /admin/emergency-access [get]
+func (h *Handler) GetEmergencyAccessReport(c *gin.Context) {
+	var filter models.EmergencyAccessFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	items, err := h.usecase.GetEmergencyAccessReport(c.Request.Context(), filter)
+	if err != nil {
+		h.InternalError(c, err)
+		return
+	}
+	h.ResultResponse(c, "success", Array, items)
+}
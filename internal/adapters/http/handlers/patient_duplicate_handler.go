@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPatientDuplicates godoc
+// @Summary Patient duplicate review queue
+// @Description Пары записей, похожих на одного человека, по убыванию оценки. Оценка складывается из совпадения СНИЛС, полиса, ФИО и даты рождения
+// @Tags Admin
+// @Produce json
+// @Param status query string false "pending (default), merged или dismissed"
+// @Param limit query int false "Max results (default: 50, max: 200)"
+// @Success 200 {array} models.DuplicateCandidateView
+// @Failure 400 {object} IncorrectFormatError
+// @Security ApiKeyAuth
+// @Router /admin/patients/duplicates [get]
+func (h *Handler) GetPatientDuplicates(c *gin.Context) {
+	status := entities.PatientDuplicateStatus(c.DefaultQuery("status", string(entities.DuplicatePending)))
+	switch status {
+	case entities.DuplicatePending, entities.DuplicateMerged, entities.DuplicateDismissed:
+	default:
+		h.BadRequest(c, &validation.Error{Field: "status", Message: "must be pending, merged or dismissed"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	duplicates, err := h.usecase.ListPatientDuplicates(c.Request.Context(), status, limit)
+	if err != nil {
+		h.InternalError(c, err)
+		return
+	}
+	h.ResultResponse(c, "success", Array, duplicates)
+}
+
+// ScanPatientDuplicates godoc
+// @Summary Scan for patient duplicates
+// @Description Запускает поиск дублей сразу, не дожидаясь воркера. Возвращает число пар в очереди
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]int
+// @Security ApiKeyAuth
+// @Router /admin/patients/duplicates/scan [post]
+func (h *Handler) ScanPatientDuplicates(c *gin.Context) {
+	found, err := h.usecase.ScanPatientDuplicates(c.Request.Context())
+	if err != nil {
+		h.InternalError(c, err)
+		return
+	}
+	h.ResultResponse(c, "success", Object, gin.H{"pending": found})
+}
+
+// MergePatientDuplicate godoc
+// @Summary Merge patient duplicates
+// @Description Переносит медкарту, вызовы, назначения бригад, историю правок и экстренные доступы второй записи пары в survivor_id.
+// @Description Вторая запись удаляется, об объединении сообщается в 1С (при недоступности 1С — повторно воркером)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Duplicate candidate ID"
+// @Param request body models.MergePatientsRequest true "Запись, которая остаётся"
+// @Success 200 {object} entities.PatientMerge
+// @Failure 400 {object} IncorrectFormatError "survivor_id не из этой пары"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Пара уже рассмотрена"
+// @Security ApiKeyAuth
+// @Router /admin/patients/duplicates/{id}/merge [post]
+func (h *Handler) MergePatientDuplicate(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	var req models.MergePatientsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	merge, err := h.usecase.MergePatientDuplicate(c.Request.Context(), uint(id), req.SurvivorID, adminID)
+	if !h.duplicateReviewError(c, err) {
+		return
+	}
+
+	h.logger.Info("Patients merged", "admin_id", adminID, "survivor_id", merge.SurvivorID, "merged_id", merge.MergedID)
+	h.ResultResponse(c, "success", Object, merge)
+}
+
+// DismissPatientDuplicate godoc
+// @Summary Dismiss patient duplicate
+// @Description Отмечает пару как разных людей, повторно она не предлагается
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Duplicate candidate ID"
+// @Success 200
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Пара уже рассмотрена"
+// @Security ApiKeyAuth
+// @Router /admin/patients/duplicates/{id}/dismiss [post]
+func (h *Handler) DismissPatientDuplicate(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	err = h.usecase.DismissPatientDuplicate(c.Request.Context(), uint(id), adminID)
+	if !h.duplicateReviewError(c, err) {
+		return
+	}
+	h.ResultResponse(c, "success", Empty, nil)
+}
+
+// duplicateReviewError пишет ответ с ошибкой решения по паре. Возвращает true, если ошибки нет
+func (h *Handler) duplicateReviewError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, validation.ErrInvalid):
+		h.BadRequest(c, err)
+	case errors.Is(err, errors.ErrNotFound):
+		h.ErrorResponse(c, err, http.StatusNotFound, errors.NotFound, true)
+	case errors.Is(err, errors.ErrEmptyAction):
+		h.ErrorResponse(c, err, http.StatusConflict, "duplicate candidate is already reviewed", false)
+	default:
+		h.InternalError(c, err)
+	}
+	return false
+}
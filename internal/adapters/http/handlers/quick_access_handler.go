@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRecentPatients возвращает недавно просмотренных пациентов
+// @Summary Недавние пациенты
+// @Description Пациенты, чьи медкарты пользователь открывал за срок хранения (RECENT_PATIENTS_RETENTION), последние первыми
+// @Tags Patients
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Сколько пациентов вернуть (по умолчанию и не больше RECENT_PATIENTS_MAX)"
+// @Success 200 {array} models.QuickAccessPatient
+// @Failure 401 {object} IncorrectDataError "Токен недействителен"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /me/patients/recent [get]
+func (h *Handler) GetRecentPatients(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, nil, http.StatusUnauthorized, "Unauthorized", true)
+		return
+	}
+
+	// Без limit или с некорректным значением отдаётся максимум из настроек
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	patients, err := h.usecase.GetRecentPatients(c.Request.Context(), userID, limit)
+	if err != nil {
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to get recent patients", false)
+		return
+	}
+
+	h.ResultResponse(c, "success", Array, patients)
+}
+
+// GetFavoritePatients возвращает избранных пациентов
+// @Summary Избранные пациенты
+// @Description Пациенты, добавленные пользователем в избранное, по ФИО
+// @Tags Patients
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.QuickAccessPatient
+// @Failure 401 {object} IncorrectDataError "Токен недействителен"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /me/patients/favorites [get]
+func (h *Handler) GetFavoritePatients(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, nil, http.StatusUnauthorized, "Unauthorized", true)
+		return
+	}
+
+	patients, err := h.usecase.GetFavoritePatients(c.Request.Context(), userID)
+	if err != nil {
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to get favorite patients", false)
+		return
+	}
+
+	h.ResultResponse(c, "success", Array, patients)
+}
+
+// AddFavoritePatient добавляет пациента в избранное
+// @Summary Добавить пациента в избранное
+// @Description Повторное добавление не считается ошибкой
+// @Tags Patients
+// @Produce json
+// @Security ApiKeyAuth
+// @Param pat_id path string true "ID пациента"
+// @Success 200 "Пациент в избранном"
+// @Failure 401 {object} IncorrectDataError "Токен недействителен"
+// @Failure 403 {object} map[string]string "Нет доступа к пациенту"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /me/patients/favorites/{pat_id} [put]
+func (h *Handler) AddFavoritePatient(c *gin.Context) {
+	patientID := c.Param("pat_id")
+	if !h.authorizePatient(c, patientID, entities.PatientAccessRead) {
+		return
+	}
+	userID, _ := currentUserID(c)
+
+	if err := h.usecase.AddFavoritePatient(c.Request.Context(), userID, patientID); err != nil {
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to add favorite patient", false)
+		return
+	}
+
+	h.ResultResponse(c, "success", Empty, nil)
+}
+
+// RemoveFavoritePatient убирает пациента из избранного
+// @Summary Убрать пациента из избранного
+// @Tags Patients
+// @Produce json
+// @Security ApiKeyAuth
+// @Param pat_id path string true "ID пациента"
+// @Success 200 "Пациент убран из избранного"
+// @Failure 401 {object} IncorrectDataError "Токен недействителен"
+// @Failure 404 {object} map[string]string "Пациента нет в избранном"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /me/patients/favorites/{pat_id} [delete]
+func (h *Handler) RemoveFavoritePatient(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, nil, http.StatusUnauthorized, "Unauthorized", true)
+		return
+	}
+
+	err := h.usecase.RemoveFavoritePatient(c.Request.Context(), userID, c.Param("pat_id"))
+	if errors.Is(err, errors.ErrNotFound) {
+		h.ErrorResponse(c, err, http.StatusNotFound, "Patient is not in favorites", true)
+		return
+	}
+	if err != nil {
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to remove favorite patient", false)
+		return
+	}
+
+	h.ResultResponse(c, "success", Empty, nil)
+}
@@ -39,6 +39,15 @@ func (h *Handler) ErrorResponse(c *gin.Context, err error, statusCode int, messa
 
 // ResultResponse - возвращает JSON с данными
 func (h *Handler) ResultResponse(c *gin.Context, message string, dataType string, data interface{}) {
+	h.resultResponse(c, http.StatusOK, message, dataType, data)
+}
+
+// AcceptedResponse - возвращает JSON с данными и кодом 202: запрос принят, обработка продолжится в фоне
+func (h *Handler) AcceptedResponse(c *gin.Context, message string, dataType string, data interface{}) {
+	h.resultResponse(c, http.StatusAccepted, message, dataType, data)
+}
+
+func (h *Handler) resultResponse(c *gin.Context, statusCode int, message string, dataType string, data interface{}) {
 	response := gin.H{
 		"status":  "success",
 		"message": message,
@@ -49,7 +58,7 @@ func (h *Handler) ResultResponse(c *gin.Context, message string, dataType string
 		response["data"] = data
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(statusCode, response)
 }
 
 // BadRequest - возвращает ошибку 400
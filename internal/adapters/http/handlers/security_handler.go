@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// UnlockLogin godoc
+// @Summary Unlock login after failed attempts
+// @Description Сбрасывает счётчик неудачных входов и снимает блокировку по логину и/или IP-адресу
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.UnlockLoginRequest true "Логин и/или IP"
+// @Success 200
+// @Failure 400 {object} IncorrectFormatError
+// @Failure 401 {object} IncorrectDataError
+// @Failure 404 {object} map[string]string "Блокировки нет"
+// @Failure 500 {object} InternalServerError
+// @Security ApiKeyAuth
+// @Router /admin/login-locks/unlock [post]
+func (h *Handler) UnlockLogin(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	var req models.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	err := h.usecase.UnlockLogin(c.Request.Context(), adminID, req)
+	switch {
+	case errors.Is(err, validation.ErrInvalid):
+		h.BadRequest(c, err)
+		return
+	case errors.Is(err, errors.ErrNotFound):
+		h.ErrorResponse(c, err, http.StatusNotFound, errors.NotFound, true)
+		return
+	case err != nil:
+		h.InternalError(c, err)
+		return
+	}
+
+	h.logger.Info("Login unlocked", "admin_id", adminID, "login", req.Login, "ip", req.IP)
+	h.ResultResponse(c, "success", Empty, nil)
+}
+
+// GetSecurityEvents godoc
+// @Summary Security events
+// @Description Журнал неудачных входов, блокировок и разблокировок для проверки администратором
+// @Tags Admin
+// @Produce json
+// @Param from query string false "С даты (YYYY-MM-DD)"
+// @Param to query string false "По дату включительно (YYYY-MM-DD)"
+// @Param type query string false "Тип события (login_failed, login_locked, login_blocked, login_unlocked)"
+// @Param login query string false "Логин"
+// @Param ip query string false "IP-адрес"
+// @Param limit query int false "Количество записей (по умолчанию 200, максимум 1000)"
+// @Success 200 {array} entities.SecurityEvent
+// @Failure 400 {object} IncorrectFormatError
+// @Failure 401 {object} IncorrectDataError
+// @Failure 500 {object} InternalServerError
+// @Security ApiKeyAuth
+// @Router /admin/security-events [get]
+func (h *Handler) GetSecurityEvents(c *gin.Context) {
+	var filter models.SecurityEventFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.BadRequest(c, err)
+		return
+	}
+
+	events, err := h.usecase.GetSecurityEvents(c.Request.Context(), filter)
+	if err != nil {
+		h.InternalError(c, err)
+		return
+	}
+	h.ResultResponse(c, "success", Array, events)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMySessions возвращает активные сеансы текущего пользователя
+// @Summary Активные сеансы
+// @Description Список входов пользователя на устройствах. Сеанс текущего запроса отмечен current=true
+// @Tags Auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Device-ID header string true "ID устройства, к которому привязан токен"
+// @Success 200 {array} models.SessionInfo
+// @Failure 401 {object} IncorrectDataError "Токен недействителен"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /me/sessions [get]
+func (h *Handler) GetMySessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, nil, http.StatusUnauthorized, "Unauthorized", true)
+		return
+	}
+
+	sessions, err := h.usecase.ListSessions(c.Request.Context(), userID, c.GetString("sid"))
+	if err != nil {
+		h.ErrorResponse(c, err, http.StatusInternalServerError, "Failed to get sessions", false)
+		return
+	}
+
+	h.ResultResponse(c, "success", Array, sessions)
+}
+
+// RevokeMySession завершает сеанс на другом устройстве
+// @Summary Удалённый выход
+// @Description Отзывает токены сеанса и закрывает его websocket-соединение
+// @Tags Auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Device-ID header string true "ID устройства, к которому привязан токен"
+// @Param session_id path string true "ID сеанса"
+// @Success 200 "Сеанс завершён"
+// @Failure 401 {object} IncorrectDataError "Токен недействителен"
+// @Failure 404 {object} map[string]string "Сеанс не найден"
+// @Failure 500 {object} InternalServerError "Внутренняя ошибка сервера"
+// @Router /me/sessions/{session_id} [delete]
+func (h *Handler) RevokeMySession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, nil, http.StatusUnauthorized, "Unauthorized", true)
+		return
+	}
+
+	if appErr := h.usecase.RevokeSession(c.Request.Context(), userID, c.Param("session_id")); appErr != nil {
+		h.ErrorResponse(c, appErr.Err, appErr.Code, appErr.Message, appErr.IsUserFacing)
+		return
+	}
+
+	h.ResultResponse(c, "success", Empty, nil)
+}
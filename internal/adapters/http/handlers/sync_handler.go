@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	middleware "github.com/AlexanderMorozov1919/mobileapp/internal/middleware/jwt"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// Размер порции ленты изменений по умолчанию и максимальный
+const (
+	defaultSyncLimit = 500
+	maxSyncLimit     = 2000
+)
+
+// GetSync godoc
+// @Summary Delta sync feed for offline clients
+// @Description Returns patients, medical cards and calls created, updated or deleted since the token from the previous response.
+// @Description Without since returns everything (full sync). Medical cards require medcard:read, calls require emergency:read;
+// @Description only cards and calls available to the user are included, every delivered card is written to the patient access log.
+// @Description Deleted also lists cards and calls the user lost access to (call completed, attending doctor changed).
+// @Description Apply deleted first, then upsert the other lists. Repeat immediately while has_more is true.
+// @Description On reset drop local data and start again without since
+// @Tags Sync
+// @Produce json
+// @Param since query string false "token from the previous response"
+// @Param limit query int false "Max changes per response (default: 500, max: 2000)"
+// @Success 200 {object} models.SyncResponse
+// @Failure 400 {object} IncorrectFormatError "Malformed sync token"
+// @Security ApiKeyAuth
+// @Router /sync [get]
+func (h *Handler) GetSync(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		h.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSyncLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultSyncLimit
+	}
+	if limit > maxSyncLimit {
+		limit = maxSyncLimit
+	}
+
+	response, err := h.usecase.GetChanges(c.Request.Context(), models.SyncRequest{
+		Since: c.Query("since"),
+		Limit: limit,
+		Subject: models.AccessSubject{
+			UserID:     userID,
+			ClinicWide: middleware.HasPermission(c, string(entities.PermPatientsAll)),
+		},
+		MedicalCards: middleware.HasPermission(c, string(entities.PermMedCardRead)),
+		Calls:        middleware.HasPermission(c, string(entities.PermEmergencyRead)),
+	})
+	if errors.Is(err, validation.ErrInvalid) {
+		h.ErrorResponse(c, err, http.StatusBadRequest, "Invalid sync token", true)
+		return
+	}
+	if errors.Is(err, errors.ErrForbidden) {
+		h.ErrorResponse(c, err, http.StatusForbidden, "account is disabled", false)
+		return
+	}
+	if err != nil {
+		h.InternalError(c, err)
+		return
+	}
+
+	h.ResultResponse(c, "success", Object, response)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/middleware/logging"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/services/websocket"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
 	"github.com/gin-gonic/gin"
 )
 
@@ -29,26 +30,34 @@ func NewWebsocketHandler(
 
 // Register godoc
 // @Summary Подписаться на уведомления
+// @Description Соединение привязывается к сеансу входа из токена и закрывается при отзыве сеанса.
+// @Description Браузер не может передать заголовки при открытии WebSocket, поэтому токен и ID устройства принимаются и в параметрах access_token и device_id
+// @Description Из браузера соединение принимается только с разрешённых для CORS origin
 // @Tags Notification
 // @Accept json
 // @Produce json
 // @Param user_id path int true "User id"
+// @Param access_token query string false "Access-токен, если нет заголовка Authorization"
+// @Param device_id query string false "ID устройства, если нет заголовка X-Device-ID"
 // @Success 200
+// @Failure 401 {object} IncorrectDataError
+// @Failure 403 {object} map[string]string "Подписка на уведомления другого пользователя"
+// @Security ApiKeyAuth
 // @Router /ws/notification/register/{user_id} [get]
 func (ws *WebsocketHandler) Register(c *gin.Context) {
-	strUserId := c.Param("user_id")
-	if strUserId == "" {
-		ws.Handler.ErrorResponse(c, nil, http.StatusBadRequest, "parameter 'user_id' must be exist", false)
+	userID, ok := ws.authorizeSubscriber(c)
+	if !ok {
 		return
 	}
 
-	userId, err := strconv.Atoi(strUserId)
-	if err != nil {
-		ws.Handler.ErrorResponse(c, err, http.StatusBadRequest, "parameter 'user_id' must be an integer", false)
+	sessionID := c.GetString("sid")
+	if sessionID == "" {
+		// Без сеанса соединение нельзя было бы закрыть при удалённом выходе
+		ws.Handler.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
 		return
 	}
 
-	err = ws.Hub.ServeRegister(c.Writer, c.Request, uint(userId))
+	err := ws.Hub.ServeRegister(c.Writer, c.Request, userID, sessionID)
 	if err != nil {
 		ws.Handler.ErrorResponse(c, err, http.StatusInternalServerError, "failed to register websocket", false)
 		return
@@ -65,20 +74,42 @@ func (ws *WebsocketHandler) Register(c *gin.Context) {
 // @Produce json
 // @Param user_id path int true "User id"
 // @Success 200 {object} map[string]string
+// @Failure 401 {object} IncorrectDataError
+// @Failure 403 {object} map[string]string "Отписка другого пользователя"
+// @Security ApiKeyAuth
 // @Router /ws/notification/unregister/{user_id} [get]
 func (ws *WebsocketHandler) Unregister(c *gin.Context) {
+	userID, ok := ws.authorizeSubscriber(c)
+	if !ok {
+		return
+	}
+
+	ws.Hub.ServeUnregister(c.Writer, c.Request, userID)
+	ws.Handler.ResultResponse(c, "Success unregister notification subscriber", Empty, nil)
+}
+
+// authorizeSubscriber проверяет, что user_id из пути совпадает с пользователем токена
+func (ws *WebsocketHandler) authorizeSubscriber(c *gin.Context) (uint, bool) {
 	strUserId := c.Param("user_id")
 	if strUserId == "" {
 		ws.Handler.ErrorResponse(c, nil, http.StatusBadRequest, "parameter 'user_id' must be exist", false)
-		return
+		return 0, false
 	}
 
 	userId, err := strconv.Atoi(strUserId)
 	if err != nil {
 		ws.Handler.ErrorResponse(c, err, http.StatusBadRequest, "parameter 'user_id' must be an integer", false)
-		return
+		return 0, false
 	}
 
-	ws.Hub.ServeUnregister(c.Writer, c.Request, uint(userId))
-	ws.Handler.ResultResponse(c, "Success unregister notification subscriber", Empty, nil)
+	tokenUserID, ok := currentUserID(c)
+	if !ok {
+		ws.Handler.ErrorResponse(c, errors.ErrUnauthorized, http.StatusUnauthorized, errors.UnauthorizedError, false)
+		return 0, false
+	}
+	if uint(userId) != tokenUserID {
+		ws.Handler.ErrorResponse(c, nil, http.StatusForbidden, "cannot subscribe to notifications of another user", false)
+		return 0, false
+	}
+	return tokenUserID, true
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/config"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
 )
 
 // Client — HTTP-клиент для взаимодействия с 1С
@@ -79,6 +80,10 @@ func (s *OneCClient) DoRequest(req *http.Request) ([]byte, *http.Response, error
 
 	resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
+	// 404 от 1С — пациента или карты нет, а не сбой обмена
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, resp, errors.NewNotFoundError(fmt.Sprintf("1C response code %d, body %s", resp.StatusCode, string(bodyBytes)))
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, resp, fmt.Errorf("response error: code %d, body %s", resp.StatusCode, string(bodyBytes))
 	}
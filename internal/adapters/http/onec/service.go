@@ -50,3 +50,49 @@ func (c *OneCClient) UpdateMedCardByPatientID(patientID string, card *entities.O
 
 	return nil
 }
+
+// PatchMedCardByPatientID отправляет в 1С только изменённые поля карты (JSON Merge Patch)
+func (c *OneCClient) PatchMedCardByPatientID(patientID string, changes map[string]interface{}) error {
+	body, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("/medical-card/%s", patientID)
+	headers := map[string]string{"Content-Type": "application/merge-patch+json"}
+
+	req, err := c.CreateRequestJSON(http.MethodPatch, endpoint, nil, headers, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("1C patch error: %w", err)
+	}
+
+	_, _, err = c.DoRequest(req)
+	if err != nil {
+		return fmt.Errorf("1C request error: %w", err)
+	}
+
+	return nil
+}
+
+// ReportPatientMerge сообщает 1С, что запись mergedID объединена с survivorID
+func (c *OneCClient) ReportPatientMerge(survivorID, mergedID string) error {
+	body, err := json.Marshal(map[string]string{
+		"survivor_id": survivorID,
+		"merged_id":   mergedID,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	req, err := c.CreateRequestJSON(http.MethodPost, "/patients/merge", nil, nil, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("1C merge report error: %w", err)
+	}
+
+	_, _, err = c.DoRequest(req)
+	if err != nil {
+		return fmt.Errorf("1C request error: %w", err)
+	}
+
+	return nil
+}
@@ -2,26 +2,188 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
 	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func (r *AuthRepository) SaveUsers(ctx context.Context, users []entities.AuthUser) error {
+// SyncUsers создаёт и обновляет пользователей по ID из 1С. Пустые пароль, роль и поля профиля
+// не затирают сохранённые; новые пользователи без роли получают роль врача.
+// При disableMissing отключаются все пользователи, которых нет в списке.
+// Сеансы и refresh-токены отключённых пользователей отзываются в той же транзакции
+func (r *AuthRepository) SyncUsers(ctx context.Context, users []entities.AuthUser, disableMissing bool) (models.UserSyncResult, error) {
+	var result models.UserSyncResult
+
+	oneCIDs := make([]string, 0, len(users))
+	for _, u := range users {
+		oneCIDs = append(oneCIDs, u.OneCID)
+	}
+
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []entities.AuthUser
+		if len(oneCIDs) > 0 {
+			if err := tx.Select("one_c_id", "role").Where("one_c_id IN ?", oneCIDs).Find(&existing).Error; err != nil {
+				return err
+			}
+		}
+		roles := make(map[string]entities.Role, len(existing))
+		for _, u := range existing {
+			roles[u.OneCID] = u.Role
+		}
+
+		for i, u := range users {
+			role, known := roles[u.OneCID]
+			if known {
+				result.Updated++
+			} else {
+				result.Created++
+				role = entities.RoleDoctor
+			}
+			// Колонка role имеет default, поэтому пустую роль нельзя отличить в excluded — подставляем её здесь
+			if u.Role == "" {
+				users[i].Role = role
+			}
+			if u.Disabled() {
+				result.Disabled++
+			}
+		}
+
+		if len(users) > 0 {
+			upsert := clause.OnConflict{
+				Columns: []clause.Column{{Name: "one_c_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"login":              gorm.Expr("excluded.login"),
+					"role":               gorm.Expr("excluded.role"),
+					"doctor_cert_number": gorm.Expr("excluded.doctor_cert_number"),
+					"disabled_at":        gorm.Expr("excluded.disabled_at"),
+					"updated_at":         gorm.Expr("excluded.updated_at"),
+					"password":           gorm.Expr("CASE WHEN excluded.password = '' THEN auth_users.password ELSE excluded.password END"),
+				}),
+			}
+			// Профили сохраняются отдельно: gorm не обновляет существующие связанные записи при upsert
+			if err := tx.Clauses(upsert).Omit(clause.Associations).CreateInBatches(&users, 100).Error; err != nil {
+				return err
+			}
+
+			profiles := make([]entities.DoctorProfile, 0, len(users))
+			for _, u := range users {
+				if u.Profile == nil {
+					continue
+				}
+				profile := *u.Profile
+				profile.UserID = u.ID
+				profiles = append(profiles, profile)
+			}
+			if len(profiles) > 0 {
+				if err := tx.Clauses(clause.OnConflict{
+					Columns: []clause.Column{{Name: "user_id"}},
+					DoUpdates: clause.Assignments(map[string]interface{}{
+						"full_name":      keepIfBlank("full_name"),
+						"specialization": keepIfBlank("specialization"),
+						"clinic":         keepIfBlank("clinic"),
+						"crew":           keepIfBlank("crew"),
+						"updated_at":     gorm.Expr("excluded.updated_at"),
+					}),
+				}).CreateInBatches(&profiles, 100).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		var disabledIDs []uint
+		for _, u := range users {
+			if u.Disabled() {
+				disabledIDs = append(disabledIDs, u.ID)
+			}
+		}
+
+		if disableMissing {
+			var missing []entities.AuthUser
+			query := tx.Model(&missing).
+				Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+				Where("disabled_at IS NULL")
+			if len(oneCIDs) > 0 {
+				query = query.Where("one_c_id NOT IN ?", oneCIDs)
+			}
+			disabled := query.Update("disabled_at", time.Now())
+			if disabled.Error != nil {
+				return disabled.Error
+			}
+			result.Disabled += disabled.RowsAffected
+			for _, u := range missing {
+				disabledIDs = append(disabledIDs, u.ID)
+			}
+		}
+
+		if len(disabledIDs) == 0 {
+			return nil
+		}
+		now := time.Now()
+		if err := tx.Model(&entities.RefreshToken{}).
+			Where("user_id IN ? AND revoked_at IS NULL", disabledIDs).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		var sessions []entities.Session
+		if err := tx.Model(&sessions).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+			Where("user_id IN ? AND revoked_at IS NULL", disabledIDs).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		for _, s := range sessions {
+			result.RevokedSessionIDs = append(result.RevokedSessionIDs, s.ID)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// keepIfBlank — пустое значение из 1С не затирает сохранённое поле профиля
+func keepIfBlank(column string) clause.Expr {
+	return gorm.Expr("CASE WHEN excluded." + column + " = '' THEN doctor_profiles." + column + " ELSE excluded." + column + " END")
+}
+
+func (r *AuthRepository) GetUserByLogin(ctx context.Context, login string) (*entities.AuthUser, error) {
+	var user entities.AuthUser
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).Where("login = ?", login).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &user, err
+}
+
+func (r *AuthRepository) GetUserByID(ctx context.Context, id uint) (*entities.AuthUser, error) {
+	var user entities.AuthUser
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &user, err
+}
+
+// SaveRolePermissions заменяет всё соответствие ролей и прав
+func (r *AuthRepository) SaveRolePermissions(ctx context.Context, rows []entities.RolePermission) error {
 	db := r.db.GetDB(ctx)
 	tx := db.WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return tx.Error
 	}
 
-	if err := tx.Exec("DELETE FROM auth_users").Error; err != nil {
+	if err := tx.Exec("DELETE FROM role_permissions").Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	if len(users) > 0 {
-		if err := tx.CreateInBatches(users, 100).Error; err != nil {
+	if len(rows) > 0 {
+		if err := tx.CreateInBatches(rows, 100).Error; err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -30,10 +192,21 @@ func (r *AuthRepository) SaveUsers(ctx context.Context, users []entities.AuthUse
 	return tx.Commit().Error
 }
 
-func (r *AuthRepository) GetUserByLogin(ctx context.Context, login string) (*entities.AuthUser, error) {
+func (r *AuthRepository) GetRolePermissions(ctx context.Context, role entities.Role) ([]entities.Permission, error) {
+	var permissions []entities.Permission
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Model(&entities.RolePermission{}).
+		Where("role = ?", role).
+		Order("permission").
+		Pluck("permission", &permissions).Error
+	return permissions, err
+}
+
+func (r *AuthRepository) GetUserByDoctorCertNumber(ctx context.Context, certNumber string) (*entities.AuthUser, error) {
 	var user entities.AuthUser
 	db := r.db.GetDB(ctx)
-	err := db.WithContext(ctx).Where("login = ?", login).First(&user).Error
+	err := db.WithContext(ctx).Where("doctor_cert_number = ?", certNumber).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -0,0 +1,78 @@
+package base
+
+import (
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+// Лента изменений для мобильных клиентов. Каждая запись пациента, медкарты и вызова хранит change_seq
+// из общей последовательности sync_change_seq: вставка получает его по умолчанию, изменение — через NextChangeSeq.
+// Удаления и уход записей из области видимости пользователя записываются в sync_tombstones той же последовательностью.
+//
+// Номера выдаются при вставке, а видны после коммита, поэтому транзакция с номером 10 может стать видна
+// позже транзакции с номером 11. Чтобы клиент не пропустил такую запись, пишущие транзакции держат
+// монопольную блокировку LockChangeFeed, а ChangeFeedHighWater берёт её разделяемой: полученный номер
+// не превышает номеров уже закоммиченных изменений. Синхронизаций намного больше, чем записей из 1С,
+// поэтому друг друга ждут только пишущие транзакции, а клиенты читают ленту параллельно
+
+// changeFeedLockKey — ключ advisory-блокировки ленты изменений
+const changeFeedLockKey = 0x73796e63 // "sync"
+
+// LockChangeFeed вызывается в транзакции до изменения записей, попадающих в ленту.
+// Если транзакция уже держит блокировку строки, которую ждёт другая пишущая транзакция,
+// Postgres обнаружит взаимную блокировку и откатит одну из них
+func LockChangeFeed(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", changeFeedLockKey).Error
+}
+
+// NextChangeSeq выдаёт номер изменения для обновляемой записи
+func NextChangeSeq(tx *gorm.DB) (int64, error) {
+	var seq int64
+	err := tx.Raw("SELECT nextval('sync_change_seq')").Scan(&seq).Error
+	return seq, err
+}
+
+// RecordDeletions записывает удаления в ленту изменений
+func RecordDeletions(tx *gorm.DB, entity entities.SyncEntity, ids []string) error {
+	return RecordScopeRemovals(tx, entity, "", ids)
+}
+
+// RecordScopeRemovals записывает, что записи ушли из области видимости пользователя login.
+// Пустой login — запись удалена для всех
+func RecordScopeRemovals(tx *gorm.DB, entity entities.SyncEntity, login string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	tombstones := make([]entities.SyncTombstone, len(ids))
+	for i, id := range ids {
+		tombstones[i] = entities.SyncTombstone{Entity: entity, EntityID: id, Login: login, DeletedAt: now}
+	}
+	return tx.CreateInBatches(tombstones, 100).Error
+}
+
+// RecordAttendingDoctorRemoval записывает, что медкарта ушла из области видимости врачей
+// с сертификатом certNumber (лечащий врач сменился)
+func RecordAttendingDoctorRemoval(tx *gorm.DB, patientID, certNumber string) error {
+	if certNumber == "" {
+		return nil
+	}
+	return tx.Exec(`INSERT INTO sync_tombstones (entity, entity_id, login, deleted_at)
+		SELECT ?, ?, login, ? FROM auth_users WHERE doctor_cert_number = ?`,
+		entities.SyncEntityMedicalCard, patientID, time.Now(), certNumber).Error
+}
+
+// ChangeFeedHighWater возвращает последний номер изменения, все изменения до которого уже закоммичены
+func ChangeFeedHighWater(db *gorm.DB) (int64, error) {
+	var highWater int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock_shared(?)", changeFeedLockKey).Error; err != nil {
+			return err
+		}
+		return tx.Raw("SELECT CASE WHEN is_called THEN last_value ELSE 0 END FROM sync_change_seq").Scan(&highWater).Error
+	})
+	return highWater, err
+}
@@ -0,0 +1,180 @@
+package base
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+	"gorm.io/gorm"
+)
+
+// keysetCursor — содержимое курсора. Клиенту отдаётся в base64, значения в SQL идут только параметрами
+type keysetCursor struct {
+	Order    string        `json:"o"` // Сортировка, для которой выдан курсор
+	Values   []interface{} `json:"v"` // Значения колонок сортировки у граничной строки
+	Backward bool          `json:"b"` // Курсор на предыдущую страницу
+}
+
+// Keyset — постраничный вывод по курсору: вместо OFFSET берутся строки после граничной,
+// поэтому страницы не съезжают, когда 1С добавляет или удаляет записи между запросами.
+// Последняя колонка Order должна быть уникальной и неизменной (например, ID из 1С)
+type Keyset struct {
+	order  []models.OrderColumn
+	cursor *keysetCursor
+}
+
+// NewKeyset разбирает курсор. Курсор от другой сортировки или повреждённый — ошибка валидации
+func NewKeyset(order []models.OrderColumn, cursor string) (*Keyset, error) {
+	k := &Keyset{order: order}
+	if cursor == "" {
+		return k, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, &validation.Error{Field: "cursor", Message: "malformed cursor"}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var c keysetCursor
+	if err := decoder.Decode(&c); err != nil {
+		return nil, &validation.Error{Field: "cursor", Message: "malformed cursor"}
+	}
+	if c.Order != orderKey(order) || len(c.Values) != len(order) {
+		return nil, &validation.Error{Field: "cursor", Message: "cursor was issued for another sort order"}
+	}
+	for i, v := range c.Values {
+		if n, ok := v.(json.Number); ok {
+			if c.Values[i], err = n.Int64(); err != nil {
+				c.Values[i], _ = n.Float64()
+			}
+		}
+	}
+
+	k.cursor = &c
+	return k, nil
+}
+
+// FetchPage выбирает страницу из query и формирует курсоры соседних страниц.
+// values возвращает значения колонок сортировки строки в порядке Order
+func FetchPage[T any](k *Keyset, query *gorm.DB, limit int, values func(T) []interface{}) ([]T, string, string, error) {
+	order := k.order
+	backward := k.cursor != nil && k.cursor.Backward
+	if backward {
+		// Предыдущая страница — это следующая при обратной сортировке
+		order = reverseOrder(order)
+	}
+
+	if k.cursor != nil {
+		condition, params := keysetCondition(order, k.cursor.Values)
+		query = query.Where(condition, params...)
+	}
+
+	var rows []T
+	if err := query.Order(OrderClause(order)).Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, "", "", err
+	}
+
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, "", "", nil
+	}
+
+	var next, prev string
+	var err error
+	if hasMore || backward {
+		if next, err = k.encode(values(rows[len(rows)-1]), false); err != nil {
+			return nil, "", "", err
+		}
+	}
+	if k.cursor != nil && (hasMore || !backward) {
+		if prev, err = k.encode(values(rows[0]), true); err != nil {
+			return nil, "", "", err
+		}
+	}
+	return rows, next, prev, nil
+}
+
+func (k *Keyset) encode(values []interface{}, backward bool) (string, error) {
+	raw, err := json.Marshal(keysetCursor{Order: orderKey(k.order), Values: values, Backward: backward})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// keysetCondition — строки строго после граничной в порядке order:
+// (c1 после v1) OR (c1 = v1 AND c2 после v2) OR ...
+// NULL в Postgres больше любого значения: при ASC они в конце, при DESC — в начале
+func keysetCondition(order []models.OrderColumn, values []interface{}) (string, []interface{}) {
+	var alternatives []string
+	var params []interface{}
+	for i, col := range order {
+		var parts []string
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				parts = append(parts, order[j].Column+" IS NULL")
+			} else {
+				parts = append(parts, order[j].Column+" = ?")
+				params = append(params, values[j])
+			}
+		}
+
+		switch {
+		case values[i] == nil && col.Desc:
+			parts = append(parts, col.Column+" IS NOT NULL")
+		case values[i] == nil:
+			// После NULL при ASC ничего нет
+			continue
+		case col.Desc:
+			parts = append(parts, col.Column+" < ?")
+			params = append(params, values[i])
+		default:
+			parts = append(parts, "("+col.Column+" > ? OR "+col.Column+" IS NULL)")
+			params = append(params, values[i])
+		}
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if len(alternatives) == 0 {
+		return "FALSE", nil
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", params
+}
+
+// OrderClause собирает ORDER BY из колонок
+func OrderClause(order []models.OrderColumn) string {
+	parts := make([]string, len(order))
+	for i, col := range order {
+		if col.Desc {
+			parts[i] = col.Column + " DESC"
+		} else {
+			parts[i] = col.Column + " ASC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func reverseOrder(order []models.OrderColumn) []models.OrderColumn {
+	reversed := make([]models.OrderColumn, len(order))
+	for i, col := range order {
+		reversed[i] = models.OrderColumn{Column: col.Column, Desc: !col.Desc}
+	}
+	return reversed
+}
+
+func orderKey(order []models.OrderColumn) string {
+	return OrderClause(order)
+}
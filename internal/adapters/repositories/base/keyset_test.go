@@ -0,0 +1,151 @@
+package base
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+)
+
+var testOrder = []models.OrderColumn{
+	{Column: "full_name"},
+	{Column: "birth_date", Desc: true},
+	{Column: "id"},
+}
+
+func TestKeysetCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []interface{}
+		backward bool
+	}{
+		{"strings and integer", []interface{}{"Иванов", "1980-01-01", int64(42)}, false},
+		{"null value", []interface{}{"Иванов", nil, int64(42)}, true},
+		{"float", []interface{}{"Петров", "1990-05-05", 1.5}, false},
+	}
+
+	k := &Keyset{order: testOrder}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := k.encode(tt.values, tt.backward)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			decoded, err := NewKeyset(testOrder, cursor)
+			if err != nil {
+				t.Fatalf("NewKeyset error: %v", err)
+			}
+			if !reflect.DeepEqual(decoded.cursor.Values, tt.values) {
+				t.Errorf("values = %#v, want %#v", decoded.cursor.Values, tt.values)
+			}
+			if decoded.cursor.Backward != tt.backward {
+				t.Errorf("backward = %v, want %v", decoded.cursor.Backward, tt.backward)
+			}
+		})
+	}
+}
+
+func TestNewKeysetErrors(t *testing.T) {
+	k := &Keyset{order: testOrder}
+	otherOrder, _ := (&Keyset{order: testOrder[:2]}).encode([]interface{}{"a", "b"}, false)
+	shortValues, _ := k.encode([]interface{}{"a"}, false)
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not json", "bm90IGpzb24"},
+		{"another sort order", otherOrder},
+		{"wrong number of values", shortValues},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyset(testOrder, tt.cursor)
+			var vErr *validation.Error
+			if !errors.As(err, &vErr) || vErr.Field != "cursor" {
+				t.Errorf("NewKeyset(%q) error = %v, want validation error for cursor", tt.cursor, err)
+			}
+		})
+	}
+
+	if k, err := NewKeyset(testOrder, ""); err != nil || k.cursor != nil {
+		t.Errorf("NewKeyset(empty) = %+v, %v, want first page", k, err)
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		order     []models.OrderColumn
+		values    []interface{}
+		condition string
+		params    []interface{}
+	}{
+		{
+			name:      "single asc",
+			order:     []models.OrderColumn{{Column: "id"}},
+			values:    []interface{}{int64(5)},
+			condition: "(((id > ? OR id IS NULL)))",
+			params:    []interface{}{int64(5)},
+		},
+		{
+			name:      "single desc",
+			order:     []models.OrderColumn{{Column: "id", Desc: true}},
+			values:    []interface{}{int64(5)},
+			condition: "((id < ?))",
+			params:    []interface{}{int64(5)},
+		},
+		{
+			name:      "two columns",
+			order:     []models.OrderColumn{{Column: "full_name"}, {Column: "id"}},
+			values:    []interface{}{"A", int64(5)},
+			condition: "(((full_name > ? OR full_name IS NULL)) OR (full_name = ? AND (id > ? OR id IS NULL)))",
+			params:    []interface{}{"A", "A", int64(5)},
+		},
+		{
+			name:      "null in asc column",
+			order:     []models.OrderColumn{{Column: "birth_date"}, {Column: "id"}},
+			values:    []interface{}{nil, int64(5)},
+			condition: "((birth_date IS NULL AND (id > ? OR id IS NULL)))",
+			params:    []interface{}{int64(5)},
+		},
+		{
+			name:      "null in desc column",
+			order:     []models.OrderColumn{{Column: "birth_date", Desc: true}, {Column: "id"}},
+			values:    []interface{}{nil, int64(5)},
+			condition: "((birth_date IS NOT NULL) OR (birth_date IS NULL AND (id > ? OR id IS NULL)))",
+			params:    []interface{}{int64(5)},
+		},
+		{
+			name:      "nothing after null",
+			order:     []models.OrderColumn{{Column: "birth_date"}},
+			values:    []interface{}{nil},
+			condition: "FALSE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, params := keysetCondition(tt.order, tt.values)
+			if condition != tt.condition {
+				t.Errorf("condition = %q, want %q", condition, tt.condition)
+			}
+			if !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("params = %#v, want %#v", params, tt.params)
+			}
+		})
+	}
+}
+
+func TestOrderClause(t *testing.T) {
+	if got := OrderClause(testOrder); got != "full_name ASC, birth_date DESC, id ASC" {
+		t.Errorf("OrderClause = %q", got)
+	}
+	if got := OrderClause(reverseOrder(testOrder)); got != "full_name DESC, birth_date ASC, id DESC" {
+		t.Errorf("OrderClause(reversed) = %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+package changefeed
+
+import (
+	"context"
+	"sort"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// GetChanges возвращает не больше limit изменений с номером больше since, видимых в scope.
+// Каждая таблица читается отдельно, затем берутся limit изменений с наименьшими номерами:
+// всё, что осталось за ними, придёт в следующей порции
+func (r *ChangeFeedRepository) GetChanges(ctx context.Context, since int64, limit int, scope models.ChangeScope) (*models.ChangeSet, error) {
+	db := r.db.GetDB(ctx).WithContext(ctx)
+
+	highWater, err := base.ChangeFeedHighWater(db)
+	if err != nil {
+		return nil, err
+	}
+	if since > highWater {
+		return &models.ChangeSet{Seq: highWater, Reset: true}, nil
+	}
+
+	window := func(query *gorm.DB) *gorm.DB {
+		return query.
+			Where("change_seq > ? AND change_seq <= ?", since, highWater).
+			Order("change_seq").
+			Limit(limit + 1)
+	}
+	set := &models.ChangeSet{Seq: highWater}
+
+	if err := window(db.Model(&entities.OneCPatientListItem{})).Find(&set.Patients).Error; err != nil {
+		return nil, err
+	}
+
+	deletedEntities := []entities.SyncEntity{entities.SyncEntityPatient}
+	if scope.MedicalCards {
+		query := db.Model(&entities.OneCMedicalCard{})
+		if !scope.ClinicWide {
+			query = query.Where("patient_id IN ?", visibleCards(db, scope))
+		}
+		if err := window(query).Find(&set.MedicalCards).Error; err != nil {
+			return nil, err
+		}
+		deletedEntities = append(deletedEntities, entities.SyncEntityMedicalCard)
+	}
+
+	if scope.Calls {
+		query := db.Model(&entities.OneCReception{})
+		if !scope.ClinicWide {
+			query = query.Where("call_id IN (SELECT call_id FROM call_assignments WHERE login = ?)", scope.Login)
+		}
+		if err := window(query).Find(&set.Calls).Error; err != nil {
+			return nil, err
+		}
+		deletedEntities = append(deletedEntities, entities.SyncEntityCall)
+	}
+
+	// Уход записи из области видимости отдаётся, только если она не осталась видна по другой причине
+	tombstonesQuery := db.Model(&entities.SyncTombstone{}).Where("entity IN ?", deletedEntities)
+	if scope.ClinicWide {
+		tombstonesQuery = tombstonesQuery.Where("login = ''")
+	} else {
+		tombstonesQuery = tombstonesQuery.Where(`(login = '' OR (login = ?
+			AND NOT (entity = ? AND entity_id IN (?))
+			AND NOT (entity = ? AND entity_id IN (SELECT call_id FROM call_assignments WHERE login = ?))))`,
+			scope.Login,
+			entities.SyncEntityMedicalCard, visibleCards(db, scope),
+			entities.SyncEntityCall, scope.Login)
+	}
+	var tombstones []entities.SyncTombstone
+	if err := window(tombstonesQuery).Find(&tombstones).Error; err != nil {
+		return nil, err
+	}
+
+	// Граница порции — limit-й по порядку номер среди всех прочитанных изменений
+	var seqs []int64
+	for _, p := range set.Patients {
+		seqs = append(seqs, p.ChangeSeq)
+	}
+	for _, c := range set.MedicalCards {
+		seqs = append(seqs, c.ChangeSeq)
+	}
+	for _, c := range set.Calls {
+		seqs = append(seqs, c.ChangeSeq)
+	}
+	for _, t := range tombstones {
+		seqs = append(seqs, t.ChangeSeq)
+	}
+	if len(seqs) > limit {
+		sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+		set.Seq = seqs[limit-1]
+		set.HasMore = true
+	}
+
+	set.Patients = upTo(set.Patients, set.Seq, func(p entities.OneCPatientListItem) int64 { return p.ChangeSeq })
+	set.MedicalCards = upTo(set.MedicalCards, set.Seq, func(c entities.OneCMedicalCard) int64 { return c.ChangeSeq })
+	set.Calls = upTo(set.Calls, set.Seq, func(c entities.OneCReception) int64 { return c.ChangeSeq })
+	for _, t := range upTo(tombstones, set.Seq, func(t entities.SyncTombstone) int64 { return t.ChangeSeq }) {
+		set.Deleted = append(set.Deleted, models.SyncDeletion{Entity: t.Entity, ID: t.EntityID})
+	}
+
+	return set, nil
+}
+
+// visibleCards — подзапрос patient_id медкарт, которые пользователь видит без доступа ко всей клинике
+func visibleCards(db *gorm.DB, scope models.ChangeScope) *gorm.DB {
+	return db.Model(&entities.OneCMedicalCard{}).
+		Select("patient_id").
+		Where(`(doctor_policy_or_cert_number <> '' AND doctor_policy_or_cert_number = ?)
+			OR patient_id IN (SELECT patient_id FROM call_assignments WHERE login = ?)`,
+			scope.DoctorCertNumber, scope.Login)
+}
+
+// upTo оставляет записи с номером не больше seq. Записи уже отсортированы по номеру
+func upTo[T any](rows []T, seq int64, changeSeq func(T) int64) []T {
+	for i, row := range rows {
+		if changeSeq(row) > seq {
+			return rows[:i]
+		}
+	}
+	return rows
+}
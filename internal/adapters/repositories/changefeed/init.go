@@ -0,0 +1,15 @@
+package changefeed
+
+import (
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type ChangeFeedRepository struct {
+	db *base.BaseRepository
+}
+
+func NewChangeFeedRepository(db *gorm.DB) interfaces.ChangeFeedRepository {
+	return &ChangeFeedRepository{db: base.NewBaseRepository(db)}
+}
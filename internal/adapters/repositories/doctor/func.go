@@ -5,25 +5,35 @@ import (
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
 	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"gorm.io/gorm"
 )
 
-func (r *DoctorRepository) GetDoctorByID(ctx context.Context, id uint) (entities.Doctor, error) {
-	var doctor entities.Doctor
+// GetDoctorByID возвращает профиль сотрудника по ID пользователя. Если профиль из 1С ещё не пришёл — nil
+func (r *DoctorRepository) GetDoctorByID(ctx context.Context, userID uint) (*entities.DoctorProfile, error) {
+	var doctor entities.DoctorProfile
 	db := r.db.GetDB(ctx)
-	if err := db.
-		Preload("Specialization").
-		First(&doctor, id).
-		Error; err != nil {
-		return entities.Doctor{}, errors.NewDBError("Error Get Doctor By Id", err)
+	err := db.WithContext(ctx).Where("user_id = ?", userID).First(&doctor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
 	}
-	return doctor, nil
+	if err != nil {
+		return nil, errors.NewDBError("Error Get Doctor By Id", err)
+	}
+	return &doctor, nil
 }
 
-func (r *DoctorRepository) GetDoctorByLogin(ctx context.Context, login string) (entities.Doctor, error) {
-	var doctor entities.Doctor
+func (r *DoctorRepository) GetDoctorByLogin(ctx context.Context, login string) (*entities.DoctorProfile, error) {
+	var doctor entities.DoctorProfile
 	db := r.db.GetDB(ctx)
-	if err := db.Where("login = ?", login).First(&doctor).Error; err != nil {
-		return entities.Doctor{}, errors.NewDBError("Error Get Doctor By Login", err)
+	err := db.WithContext(ctx).
+		Joins("JOIN auth_users ON auth_users.id = doctor_profiles.user_id").
+		Where("auth_users.login = ?", login).
+		First(&doctor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDBError("Error Get Doctor By Login", err)
 	}
-	return doctor, nil
+	return &doctor, nil
 }
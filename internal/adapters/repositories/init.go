@@ -7,14 +7,24 @@ import (
 	"time"
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/medcard"
+	medcardEdit "github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/medcard_edit"
+	patientAccess "github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/patient_access"
+	patientDuplicate "github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/patient_duplicate"
+	quickAccess "github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/quick_access"
 	receptionSmp "github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/reception_smp"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/tx"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/auth"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/changefeed"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/doctor"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/mfa"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/patient"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/security"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/session"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/token"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/config"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
 	"gorm.io/driver/postgres"
@@ -24,10 +34,19 @@ import (
 
 type Repository struct {
 	interfaces.AuthRepository
+	interfaces.TokenRepository
 	interfaces.DoctorRepository
 	interfaces.PatientRepository
 	interfaces.ReceptionSmpRepository
 	interfaces.MedicalCardRepository
+	interfaces.MedCardEditRepository
+	interfaces.PatientAccessRepository
+	interfaces.SecurityRepository
+	interfaces.MFARepository
+	interfaces.SessionRepository
+	interfaces.ChangeFeedRepository
+	interfaces.PatientDuplicateRepository
+	interfaces.QuickAccessRepository
 	interfaces.TxManager
 }
 
@@ -70,10 +89,19 @@ func NewRepository(cfg *config.Config) (interfaces.Repository, error) {
 
 	return &Repository{
 		auth.NewAuthRepository(db),
+		token.NewTokenRepository(db),
 		doctor.NewDoctorRepository(db),
 		patient.NewPatientRepository(db),
 		receptionSmp.NewReceptionSmpRepository(db),
 		medcard.NewMedicalCardRepository(db),
+		medcardEdit.NewMedCardEditRepository(db),
+		patientAccess.NewPatientAccessRepository(db),
+		security.NewSecurityRepository(db),
+		mfa.NewMFARepository(db),
+		session.NewSessionRepository(db),
+		changefeed.NewChangeFeedRepository(db),
+		patientDuplicate.NewPatientDuplicateRepository(db),
+		quickAccess.NewQuickAccessRepository(db),
 		tx.NewTxManager(db),
 	}, nil
 
@@ -84,17 +112,60 @@ func autoMigrate(db *gorm.DB) error {
 	log.Println("🗑️ Dropping all tables...")
 
 	// Сначала дочерние таблицы (с FK), потом родительские
+	_ = db.Migrator().DropTable(&entities.MedCardEdit{})
+	_ = db.Migrator().DropTable(&entities.PatientAccessLog{})
+	_ = db.Migrator().DropTable(&entities.EmergencyAccessGrant{})
+	_ = db.Migrator().DropTable(&entities.LoginAttemptCounter{})
+	_ = db.Migrator().DropTable(&entities.SecurityEvent{})
+	_ = db.Migrator().DropTable(&entities.MFARecoveryCode{})
+	_ = db.Migrator().DropTable(&entities.UserMFA{})
+	_ = db.Migrator().DropTable(&entities.CallAssignment{})
 	_ = db.Migrator().DropTable(&entities.OneCMedicalCard{})
 	_ = db.Migrator().DropTable(&entities.OneCPatientListItem{})
 	_ = db.Migrator().DropTable(&entities.OneCReception{})
+	_ = db.Migrator().DropTable(&entities.RefreshToken{})
+	_ = db.Migrator().DropTable(&entities.Session{})
+	_ = db.Migrator().DropTable(&entities.Device{})
+	_ = db.Migrator().DropTable(&entities.DoctorProfile{})
+	_ = db.Migrator().DropTable(&entities.RevokedAccessToken{})
 	_ = db.Migrator().DropTable(&entities.AuthUser{})
+	_ = db.Migrator().DropTable(&entities.RolePermission{})
+	_ = db.Migrator().DropTable(&entities.SyncTombstone{})
+	_ = db.Migrator().DropTable(&entities.PatientDuplicateCandidate{})
+	_ = db.Migrator().DropTable(&entities.PatientMerge{})
+	_ = db.Migrator().DropTable(&entities.PatientView{})
+	_ = db.Migrator().DropTable(&entities.FavoritePatient{})
+	_ = db.Exec("DROP SEQUENCE IF EXISTS sync_change_seq")
 
 	log.Println("🆕 Creating tables in correct order...")
 
+	// Номера изменений для ленты синхронизации, на неё ссылаются DEFAULT колонок change_seq
+	if err := db.Exec("CREATE SEQUENCE IF NOT EXISTS sync_change_seq").Error; err != nil {
+		return fmt.Errorf("sync_change_seq: %w", err)
+	}
+
 	// Теперь создаём в правильном порядке
 	if err := db.Migrator().CreateTable(&entities.AuthUser{}); err != nil {
 		return fmt.Errorf("auth_users: %w", err)
 	}
+	if err := db.Migrator().CreateTable(&entities.DoctorProfile{}); err != nil {
+		return fmt.Errorf("doctor_profiles: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.RolePermission{}); err != nil {
+		return fmt.Errorf("role_permissions: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.RefreshToken{}); err != nil {
+		return fmt.Errorf("refresh_tokens: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.Device{}); err != nil {
+		return fmt.Errorf("devices: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.Session{}); err != nil {
+		return fmt.Errorf("sessions: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.RevokedAccessToken{}); err != nil {
+		return fmt.Errorf("revoked_access_tokens: %w", err)
+	}
 	if err := db.Migrator().CreateTable(&entities.OneCReception{}); err != nil {
 		return fmt.Errorf("receptions: %w", err)
 	}
@@ -104,6 +175,53 @@ func autoMigrate(db *gorm.DB) error {
 	if err := db.Migrator().CreateTable(&entities.OneCMedicalCard{}); err != nil {
 		return fmt.Errorf("med_cards: %w", err)
 	}
+	if err := db.Migrator().CreateTable(&entities.SyncTombstone{}); err != nil {
+		return fmt.Errorf("sync_tombstones: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.PatientDuplicateCandidate{}); err != nil {
+		return fmt.Errorf("patient_duplicate_candidates: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.PatientMerge{}); err != nil {
+		return fmt.Errorf("patient_merges: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.MedCardEdit{}); err != nil {
+		return fmt.Errorf("med_card_edits: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.CallAssignment{}); err != nil {
+		return fmt.Errorf("call_assignments: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.PatientAccessLog{}); err != nil {
+		return fmt.Errorf("patient_access_logs: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.EmergencyAccessGrant{}); err != nil {
+		return fmt.Errorf("emergency_access_grants: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.LoginAttemptCounter{}); err != nil {
+		return fmt.Errorf("login_attempt_counters: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.SecurityEvent{}); err != nil {
+		return fmt.Errorf("security_events: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.UserMFA{}); err != nil {
+		return fmt.Errorf("user_mfas: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.MFARecoveryCode{}); err != nil {
+		return fmt.Errorf("mfa_recovery_codes: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.PatientView{}); err != nil {
+		return fmt.Errorf("patient_views: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&entities.FavoritePatient{}); err != nil {
+		return fmt.Errorf("favorite_patients: %w", err)
+	}
+
+	// Триграммный индекс для нечёткого поиска по ФИО. Без прав на создание расширения
+	// поиск продолжит работать, но будет ранжировать пациентов в памяти
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("⚠️ pg_trgm is unavailable, patient search falls back to in-process ranking: %v", err)
+	} else if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_patient_list_full_name_trgm ON one_c_patient_list_items USING gin (full_name_search gin_trgm_ops)").Error; err != nil {
+		return fmt.Errorf("patient_list trigram index: %w", err)
+	}
 
 	log.Println("✅ Migrations completed")
 	return nil
@@ -136,24 +254,35 @@ func seedInitialData(db *gorm.DB, cfg *config.Config) error {
 		login := fmt.Sprintf("+7962284076%d", i)
 		patientID := fmt.Sprintf("user%d_id", i)
 		fullName := fmt.Sprintf("Пациент %d", i)
+		birthDate := fmt.Sprintf("198%d-%02d-%02d", i%9+1, i%12+1, i%28+10)
+		snilsBody := fmt.Sprintf("123456%03d", 100+i)
 
 		// 1. Пользователь аутентификации
 		authUsers = append(authUsers, entities.AuthUser{
+			OneCID:   fmt.Sprintf("user%d", i),
 			Login:    login,
 			Password: string(hash),
+			Role:     entities.RoleDoctor,
+			// Врач i — лечащий врач пациента i
+			DoctorCertNumber: fmt.Sprintf("POL%d", i),
+			Profile: &entities.DoctorProfile{
+				FullName:       fmt.Sprintf("Доктор %d", i),
+				Specialization: "Терапевт",
+				Clinic:         fmt.Sprintf("Поликлиника %d", i),
+				Crew:           fmt.Sprintf("Бригада %d", (i+1)/2),
+			},
 		})
 
 		// 2. Медицинская карта
 		medicalCards = append(medicalCards, entities.OneCMedicalCard{
 			PatientID:   patientID,
 			DisplayName: fullName,
-			Age:         fmt.Sprintf("%d", 20+i%50),
-			BirthDate:   fmt.Sprintf("198%d-0%d-1%d", i%9+1, i%12+1, i%28+1),
+			BirthDate:   birthDate,
 			MobilePhone: fmt.Sprintf("+790012345%02d", i),
 			Address:     fmt.Sprintf("г. Москва, ул. Тестовая, д. %d", i),
 			Email:       fmt.Sprintf("user%d@example.com", i),
 			Workplace:   fmt.Sprintf("ООО \"Компания %d\"", i),
-			Snils:       fmt.Sprintf("123-456-789 %d", i),
+			Snils:       validation.FormatSnils(snilsBody + validation.SnilsChecksum(snilsBody)),
 
 			LegalRepresentative: entities.ClientRef{
 				ID:   fmt.Sprintf("rep_%d", i),
@@ -171,7 +300,7 @@ func seedInitialData(db *gorm.DB, cfg *config.Config) error {
 				Clinic:             fmt.Sprintf("Поликлиника %d", i),
 			},
 			Policy: entities.Policy{
-				Number: fmt.Sprintf("POLICY%d", i),
+				Number: fmt.Sprintf("77000000000000%02d", i),
 				Type:   "ОМС",
 			},
 			Certificate: entities.Certificate{
@@ -185,14 +314,25 @@ func seedInitialData(db *gorm.DB, cfg *config.Config) error {
 			PatientID: patientID,
 			FullName:  fullName,
 			Gender:    i%2 == 0, // чередуем пол
-			BirthDate: fmt.Sprintf("198%d-0%d-1%d", i%9+1, i%12+1, i%28+1),
+			BirthDate: birthDate,
 		})
 	}
 
-	// 1. Пользователи аутентификации
+	for i := range medicalCards {
+		medicalCards[i].FillSearchColumns()
+		patientListItems[i].FillSearchColumns()
+	}
+
+	// Последний пользователь — администратор
+	authUsers[len(authUsers)-1].Role = entities.RoleAdmin
+
+	// 1. Пользователи аутентификации и права ролей
 	if err := db.CreateInBatches(authUsers, 10).Error; err != nil {
 		return fmt.Errorf("failed to seed auth users: %w", err)
 	}
+	if err := db.CreateInBatches(entities.DefaultRolePermissionRows(), 50).Error; err != nil {
+		return fmt.Errorf("failed to seed role permissions: %w", err)
+	}
 
 	// 2. Список пациентов (родительская таблица для медкарт!)
 	if err := db.CreateInBatches(patientListItems, 10).Error; err != nil {
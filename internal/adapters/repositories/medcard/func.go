@@ -2,22 +2,100 @@ package medcard
 
 import (
 	"context"
-	"errors"
 
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (r *MedicalCardRepository) SaveMedicalCard(ctx context.Context, card *entities.OneCMedicalCard) error {
+	card.FillSearchColumns()
+	// Номер изменения выдаёт DEFAULT колонки
+	card.ChangeSeq = 0
 	db := r.db.GetDB(ctx)
 	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := base.LockChangeFeed(tx); err != nil {
+			return err
+		}
+		previousDoctor, err := attendingDoctorCert(tx, card.PatientID)
+		if err != nil {
+			return err
+		}
 		if err := tx.Where("patient_id = ?", card.PatientID).Delete(&entities.OneCMedicalCard{}).Error; err != nil {
 			return err
 		}
-		return tx.Create(card).Error
+		if err := tx.Create(card).Error; err != nil {
+			return err
+		}
+		return recordDoctorChange(tx, card, previousDoctor)
 	})
 }
 
+// UpdateMedicalCard обновляет карту, только если её версия в БД равна expectedVersion.
+// При успехе версия карты увеличивается на 1, иначе возвращается errors.ErrVersionConflict
+func (r *MedicalCardRepository) UpdateMedicalCard(ctx context.Context, card *entities.OneCMedicalCard, expectedVersion uint) error {
+	db := r.db.GetDB(ctx)
+
+	previousSeq := card.ChangeSeq
+	card.Version = expectedVersion + 1
+	card.FillSearchColumns()
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := base.LockChangeFeed(tx); err != nil {
+			return err
+		}
+		seq, err := base.NextChangeSeq(tx)
+		if err != nil {
+			return err
+		}
+		card.ChangeSeq = seq
+
+		previousDoctor, err := attendingDoctorCert(tx, card.PatientID)
+		if err != nil {
+			return err
+		}
+		result := tx.
+			Model(&entities.OneCMedicalCard{}).
+			Where("patient_id = ? AND version = ?", card.PatientID, expectedVersion).
+			Select("*").
+			Omit("id").
+			Updates(card)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrVersionConflict
+		}
+		return recordDoctorChange(tx, card, previousDoctor)
+	})
+	if err != nil {
+		card.Version = expectedVersion
+		card.ChangeSeq = previousSeq
+	}
+	return err
+}
+
+// attendingDoctorCert возвращает сертификат сохранённого лечащего врача пациента (пусто, если карты нет)
+func attendingDoctorCert(tx *gorm.DB, patientID string) (string, error) {
+	var certs []string
+	err := tx.Model(&entities.OneCMedicalCard{}).
+		Where("patient_id = ?", patientID).
+		Pluck("doctor_policy_or_cert_number", &certs).Error
+	if err != nil || len(certs) == 0 {
+		return "", err
+	}
+	return certs[0], nil
+}
+
+// recordDoctorChange — при смене лечащего врача карта уходит из ленты изменений прежнего врача
+func recordDoctorChange(tx *gorm.DB, card *entities.OneCMedicalCard, previousDoctor string) error {
+	if previousDoctor == card.AttendingDoctor.PolicyOrCertNumber {
+		return nil
+	}
+	return base.RecordAttendingDoctorRemoval(tx, card.PatientID, previousDoctor)
+}
+
 func (r *MedicalCardRepository) GetMedicalCard(ctx context.Context, patientID string) (*entities.OneCMedicalCard, error) {
 	var card entities.OneCMedicalCard
 	db := r.db.GetDB(ctx)
@@ -28,7 +106,66 @@ func (r *MedicalCardRepository) GetMedicalCard(ctx context.Context, patientID st
 	return &card, err
 }
 
+// GetMedicalCardForUpdate читает карту с блокировкой строки до конца транзакции из контекста.
+// Вне транзакции блокировка снимается сразу после чтения
+func (r *MedicalCardRepository) GetMedicalCardForUpdate(ctx context.Context, patientID string) (*entities.OneCMedicalCard, error) {
+	var card entities.OneCMedicalCard
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("patient_id = ?", patientID).
+		First(&card).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &card, err
+}
+
+// GetMedicalCards возвращает сохранённые карты пациентов. Пациентов без карты в результате нет
+func (r *MedicalCardRepository) GetMedicalCards(ctx context.Context, patientIDs []string) ([]entities.OneCMedicalCard, error) {
+	var cards []entities.OneCMedicalCard
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).Where("patient_id IN ?", patientIDs).Find(&cards).Error
+	return cards, err
+}
+
+// mergeChainLimit — сколько объединений подряд проходит GetMergeSurvivor (защита от цикла)
+const mergeChainLimit = 10
+
+// GetMergeSurvivor возвращает ID записи, с которой объединён пациент. Если запись объединяли
+// несколько раз (A в B, затем B в C), возвращается последняя. Пустая строка — пациент не объединялся
+func (r *MedicalCardRepository) GetMergeSurvivor(ctx context.Context, patientID string) (string, error) {
+	db := r.db.GetDB(ctx)
+	survivor := ""
+	for i := 0; i < mergeChainLimit; i++ {
+		var next []string
+		err := db.WithContext(ctx).
+			Model(&entities.PatientMerge{}).
+			Where("merged_id = ?", patientID).
+			Order("id DESC").
+			Limit(1).
+			Pluck("survivor_id", &next).Error
+		if err != nil {
+			return "", err
+		}
+		if len(next) == 0 {
+			break
+		}
+		survivor, patientID = next[0], next[0]
+	}
+	return survivor, nil
+}
+
 func (r *MedicalCardRepository) DeleteMedicalCard(ctx context.Context, patientID string) error {
 	db := r.db.GetDB(ctx)
-	return db.WithContext(ctx).Where("patient_id = ?", patientID).Delete(&entities.OneCMedicalCard{}).Error
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := base.LockChangeFeed(tx); err != nil {
+			return err
+		}
+		result := tx.Where("patient_id = ?", patientID).Delete(&entities.OneCMedicalCard{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return base.RecordDeletions(tx, entities.SyncEntityMedicalCard, []string{patientID})
+	})
 }
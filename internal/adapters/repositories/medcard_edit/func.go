@@ -0,0 +1,119 @@
+package medcardEdit
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveMedCardEdits ставит правки в очередь. Правки, чей client_edit_id уже есть у этого пользователя, игнорируются
+func (r *MedCardEditRepository) SaveMedCardEdits(ctx context.Context, edits []entities.MedCardEdit) error {
+	if len(edits) == 0 {
+		return nil
+	}
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "client_edit_id"}},
+			DoNothing: true,
+		}).
+		Create(&edits).Error
+}
+
+// GetMedCardEditsByClientIDs возвращает правки пользователя по клиентским ID
+func (r *MedCardEditRepository) GetMedCardEditsByClientIDs(ctx context.Context, userID uint, clientEditIDs []string) ([]entities.MedCardEdit, error) {
+	var edits []entities.MedCardEdit
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Where("user_id = ? AND client_edit_id IN ?", userID, clientEditIDs).
+		Order("client_timestamp").
+		Find(&edits).Error
+	return edits, err
+}
+
+// ListMedCardEdits возвращает правки пользователя, при необходимости с фильтром по статусу
+func (r *MedCardEditRepository) ListMedCardEdits(ctx context.Context, userID uint, status entities.MedCardEditStatus, limit int) ([]entities.MedCardEdit, error) {
+	var edits []entities.MedCardEdit
+	db := r.db.GetDB(ctx)
+	query := db.WithContext(ctx).Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("client_timestamp DESC").Limit(limit).Find(&edits).Error
+	return edits, err
+}
+
+// ClaimPendingMedCardEdit захватывает самую раннюю по порядку поступления правку, которую пора отправить:
+// увеличивает число попыток и откладывает next_attempt_at на lease. Захват фиксируется сразу, и правка
+// отправляется вне транзакции; если обработчик не сохранит результат до конца lease (упал, завис),
+// правку захватит следующий проход. Правки, которые прямо сейчас захватывают другие реплики, пропускаются.
+// Правки одного пациента отправляются строго по очереди: правка не берётся, пока у пациента есть
+// более ранняя ожидающая правка. Если отправлять нечего, возвращается nil
+func (r *MedCardEditRepository) ClaimPendingMedCardEdit(ctx context.Context, now time.Time, lease time.Duration) (*entities.MedCardEdit, error) {
+	var edit entities.MedCardEdit
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entities.MedCardEditPending, now).
+			Where(`NOT EXISTS (
+				SELECT 1 FROM med_card_edits AS earlier
+				WHERE earlier.patient_id = med_card_edits.patient_id
+					AND earlier.status = ?
+					AND earlier.id < med_card_edits.id
+			)`, entities.MedCardEditPending).
+			Order("id").
+			Take(&edit).Error
+		if err != nil {
+			return err
+		}
+
+		edit.Attempts++
+		edit.NextAttemptAt = now.Add(lease)
+		return tx.Model(&edit).Select("attempts", "next_attempt_at").Updates(&edit).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &edit, nil
+}
+
+// GetLastAppliedMedCardEdit возвращает последнюю применённую правку пользователя по пациенту
+func (r *MedCardEditRepository) GetLastAppliedMedCardEdit(ctx context.Context, userID uint, patientID string) (*entities.MedCardEdit, error) {
+	var edit entities.MedCardEdit
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Where("user_id = ? AND patient_id = ? AND status = ?", userID, patientID, entities.MedCardEditApplied).
+		Order("applied_version DESC").
+		First(&edit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &edit, err
+}
+
+// UpdateMedCardEdit сохраняет результат попытки синхронизации правки.
+// Обновляется только правка, которая всё ещё ожидает отправки и не была захвачена заново
+// (число попыток не изменилось), чтобы обработчик с истёкшим lease не перезаписал чужой результат
+func (r *MedCardEditRepository) UpdateMedCardEdit(ctx context.Context, edit *entities.MedCardEdit) error {
+	db := r.db.GetDB(ctx)
+	result := db.WithContext(ctx).
+		Model(edit).
+		Where("status = ? AND attempts = ?", entities.MedCardEditPending, edit.Attempts).
+		Select("status", "next_attempt_at", "last_error", "conflict_card", "applied_version", "applied_at").
+		Updates(edit)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrEmptyAction
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package medcardEdit
+
+import (
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type MedCardEditRepository struct {
+	db *base.BaseRepository
+}
+
+func NewMedCardEditRepository(db *gorm.DB) interfaces.MedCardEditRepository {
+	return &MedCardEditRepository{db: base.NewBaseRepository(db)}
+}
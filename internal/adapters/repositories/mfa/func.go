@@ -0,0 +1,93 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *MFARepository) GetUserMFA(ctx context.Context, userID uint) (*entities.UserMFA, error) {
+	var mfa entities.UserMFA
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).First(&mfa, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &mfa, err
+}
+
+// SavePendingMFA сохраняет новый неподтверждённый секрет, заменяя предыдущий
+func (r *MFARepository) SavePendingMFA(ctx context.Context, userID uint, secret string) error {
+	mfa := entities.UserMFA{UserID: userID, Secret: secret}
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"secret":         secret,
+				"confirmed_at":   nil,
+				"last_used_step": 0,
+				"updated_at":     time.Now(),
+			}),
+		}).
+		Create(&mfa).Error
+}
+
+// UseTOTPStep запоминает принятый шаг TOTP. Возвращает false, если этот или более поздний
+// шаг уже использован (повтор кода)
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	db := r.db.GetDB(ctx)
+	result := db.WithContext(ctx).
+		Model(&entities.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ConfirmMFA завершает подключение и заменяет коды восстановления
+func (r *MFARepository) ConfirmMFA(ctx context.Context, userID uint, recoveryCodeHashes []string) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.UserMFA{}).
+			Where("user_id = ?", userID).
+			Update("confirmed_at", time.Now()).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]entities.MFARecoveryCode, 0, len(recoveryCodeHashes))
+		for _, hash := range recoveryCodeHashes {
+			codes = append(codes, entities.MFARecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode гасит код восстановления. Возвращает false, если кода нет или он уже использован
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	db := r.db.GetDB(ctx)
+	result := db.WithContext(ctx).
+		Model(&entities.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *MFARepository) DeleteUserMFA(ctx context.Context, userID uint) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&entities.UserMFA{}).Error
+	})
+}
@@ -0,0 +1,15 @@
+package mfa
+
+import (
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type MFARepository struct {
+	db *base.BaseRepository
+}
+
+func NewMFARepository(db *gorm.DB) interfaces.MFARepository {
+	return &MFARepository{db: base.NewBaseRepository(db)}
+}
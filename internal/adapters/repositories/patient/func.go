@@ -2,25 +2,61 @@ package patient
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/namesearch"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-// SavePatientList сохраняет полный список пациентов (заменяет текущий)
+// SavePatientList сохраняет полный список пациентов (заменяет текущий).
+// Изменяются только отличающиеся записи, чтобы лента синхронизации не получала весь список заново;
+// пациенты, которых нет в новом списке, удаляются и попадают в ленту как удалённые
 func (r *PatientRepositoryImpl) SavePatientList(ctx context.Context, patients []entities.OneCPatientListItem) error {
+	fillSearchColumns(patients)
 	db := r.db.GetDB(ctx)
 	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Очищаем таблицу
-		if err := tx.Delete(&entities.OneCPatientListItem{}, "1=1").Error; err != nil {
+		if err := base.LockChangeFeed(tx); err != nil {
 			return err
 		}
-		// Вставляем новые данные
-		if len(patients) > 0 {
-			return tx.CreateInBatches(patients, 100).Error
+		patients, err := withoutMergedPatients(tx, patients)
+		if err != nil {
+			return err
 		}
-		return nil
+
+		var existing []string
+		if err := tx.Model(&entities.OneCPatientListItem{}).Pluck("patient_id", &existing).Error; err != nil {
+			return err
+		}
+		incoming := make(map[string]bool, len(patients))
+		for _, p := range patients {
+			incoming[p.PatientID] = true
+		}
+		var removed []string
+		for _, id := range existing {
+			if !incoming[id] {
+				removed = append(removed, id)
+			}
+		}
+
+		// Удаляем выбывших пациентов
+		for start := 0; start < len(removed); start += 1000 {
+			batch := removed[start:min(start+1000, len(removed))]
+			if err := tx.Where("patient_id IN ?", batch).Delete(&entities.OneCPatientListItem{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := base.RecordDeletions(tx, entities.SyncEntityPatient, removed); err != nil {
+			return err
+		}
+
+		return upsertPatients(tx, patients)
 	})
 }
 
@@ -29,36 +65,405 @@ func (r *PatientRepositoryImpl) SaveOrUpdatePatientList(ctx context.Context, pat
 	if len(patients) == 0 {
 		return nil
 	}
+	fillSearchColumns(patients)
 	db := r.db.GetDB(ctx)
 	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Вставляем или обновляем существующих по PatientID
-		if err := tx.Clauses(clause.OnConflict{
-			Columns:   []clause.Column{{Name: "patient_id"}}, // уникальное поле
-			UpdateAll: true,                                  // обновляем все поля при конфликте
-		}).CreateInBatches(patients, 100).Error; err != nil {
+		if err := base.LockChangeFeed(tx); err != nil {
 			return err
 		}
-		return nil
+		patients, err := withoutMergedPatients(tx, patients)
+		if err != nil {
+			return err
+		}
+		return upsertPatients(tx, patients)
 	})
 }
 
-// GetPatientListPage возвращает страницу пациентов
-func (r *PatientRepositoryImpl) GetPatientListPage(ctx context.Context, offset, limit int) ([]entities.OneCPatientListItem, int64, error) {
-	var patients []entities.OneCPatientListItem
-	var total int64
+// withoutMergedPatients убирает записи, объединённые с другими. Пока 1С не обработала объединение,
+// она продолжает присылать обе записи, и удалённая при объединении вернулась бы в список и ленту
+func withoutMergedPatients(tx *gorm.DB, patients []entities.OneCPatientListItem) ([]entities.OneCPatientListItem, error) {
+	var merged []string
+	if err := tx.Model(&entities.PatientMerge{}).Distinct("merged_id").Pluck("merged_id", &merged).Error; err != nil {
+		return nil, err
+	}
+	if len(merged) == 0 {
+		return patients, nil
+	}
+
+	skip := make(map[string]bool, len(merged))
+	for _, id := range merged {
+		skip[id] = true
+	}
+	kept := make([]entities.OneCPatientListItem, 0, len(patients))
+	for _, p := range patients {
+		if !skip[p.PatientID] {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
+
+// upsertPatients вставляет новых пациентов и обновляет существующих по PatientID.
+// Запись без изменений не обновляется и сохраняет свой change_seq
+func upsertPatients(tx *gorm.DB, patients []entities.OneCPatientListItem) error {
+	if len(patients) == 0 {
+		return nil
+	}
+	for i := range patients {
+		// Номер изменения выдаёт DEFAULT колонки
+		patients[i].ChangeSeq = 0
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "patient_id"}}, // уникальное поле
+		DoUpdates: clause.AssignmentColumns([]string{
+			"full_name", "gender", "birth_date", "birth_date_parsed", "full_name_search", "change_seq",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: `(one_c_patient_list_items.full_name,
+			one_c_patient_list_items.gender, one_c_patient_list_items.birth_date)
+			IS DISTINCT FROM (excluded.full_name, excluded.gender, excluded.birth_date)`}}},
+	}).CreateInBatches(patients, 100).Error
+}
+
+// patientListRow — пациент с колонками подзапроса списка, по которым можно сортировать
+type patientListRow struct {
+	entities.OneCPatientListItem
+	Snils        *string
+	PolicyNumber *string
+}
+
+// orderValue — значение колонки сортировки для курсора. NULL из медкарты остаётся nil
+func (row patientListRow) orderValue(column string) interface{} {
+	switch column {
+	case "full_name":
+		return row.FullName
+	case "gender":
+		return row.Gender
+	case "birth_date_parsed":
+		if row.BirthDateParsed == nil {
+			return nil
+		}
+		return row.BirthDateParsed.Format("2006-01-02")
+	case "snils":
+		if row.Snils == nil {
+			return nil
+		}
+		return *row.Snils
+	case "policy_number":
+		if row.PolicyNumber == nil {
+			return nil
+		}
+		return *row.PolicyNumber
+	default:
+		return row.PatientID
+	}
+}
+
+// patientListQuery — отфильтрованный список пациентов.
+// Условия filter ссылаются на поля models.PatientListFields: они доступны как колонки подзапроса,
+// в котором к списку присоединены СНИЛС и полис из медкарты
+func (r *PatientRepositoryImpl) patientListQuery(ctx context.Context, filter models.SQLFilter) *gorm.DB {
 	db := r.db.GetDB(ctx)
+
+	patientList := db.WithContext(ctx).
+		Table("one_c_patient_list_items AS p").
+		Select("p.*, mc.snils_search AS snils, mc.policy_search AS policy_number").
+		Joins("LEFT JOIN one_c_medical_cards mc ON mc.patient_id = p.patient_id")
+
+	query := db.WithContext(ctx).Table("(?) AS patients", patientList)
+	if filter.Where != "" {
+		query = query.Where(filter.Where, filter.Params...)
+	}
+	// Запрос используется дважды: для подсчёта и для страницы
+	return query.Session(&gorm.Session{})
+}
+
+// patientListOrder дополняет сортировку patient_id: ID из 1С уникален и, в отличие от id,
+// не меняется при полной перезаливке списка
+func patientListOrder(order []models.OrderColumn) []models.OrderColumn {
+	if len(order) == 0 {
+		order = []models.OrderColumn{{Column: "full_name"}}
+	}
+	return append(order[:len(order):len(order)], models.OrderColumn{Column: "patient_id"})
+}
+
+// GetPatientListPage возвращает страницу пациентов по номеру и общее число найденных
+func (r *PatientRepositoryImpl) GetPatientListPage(ctx context.Context, offset, limit int, filter models.SQLFilter) ([]entities.OneCPatientListItem, int64, error) {
+	var rows []patientListRow
+	var total int64
+	query := r.patientListQuery(ctx, filter)
+
 	// Получаем общее количество
-	if err := db.WithContext(ctx).Model(&entities.OneCPatientListItem{}).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Получаем страницу
-	if err := db.WithContext(ctx).
+	if err := query.
+		Order(base.OrderClause(patientListOrder(filter.Order))).
 		Offset(offset).
 		Limit(limit).
-		Find(&patients).Error; err != nil {
+		Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 
+	patients := make([]entities.OneCPatientListItem, len(rows))
+	for i, row := range rows {
+		patients[i] = row.OneCPatientListItem
+	}
 	return patients, total, nil
 }
+
+// GetPatientListCursor возвращает страницу пациентов по курсору. Общее число считается только при page.WithTotal
+func (r *PatientRepositoryImpl) GetPatientListCursor(ctx context.Context, page models.CursorPageRequest, filter models.SQLFilter) (*models.CursorPage[[]entities.OneCPatientListItem], error) {
+	order := patientListOrder(filter.Order)
+	keyset, err := base.NewKeyset(order, page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.patientListQuery(ctx, filter)
+	result := &models.CursorPage[[]entities.OneCPatientListItem]{HitsPerPage: page.Limit}
+	if page.WithTotal {
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		result.TotalHits = &total
+	}
+
+	rows, next, prev, err := base.FetchPage(keyset, query, page.Limit, func(row patientListRow) []interface{} {
+		values := make([]interface{}, len(order))
+		for i, col := range order {
+			values[i] = row.orderValue(col.Column)
+		}
+		return values
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Hits = make([]entities.OneCPatientListItem, len(rows))
+	for i, row := range rows {
+		result.Hits[i] = row.OneCPatientListItem
+	}
+	result.NextCursor, result.PrevCursor = next, prev
+	return result, nil
+}
+
+// inProcessSearchBatch — сколько пациентов читается за один запрос при поиске без pg_trgm
+const inProcessSearchBatch = 1000
+
+// patientSearchRow — пациент с документами из медкарты и оценкой совпадения
+type patientSearchRow struct {
+	entities.OneCPatientListItem
+	SnilsSearch       string
+	PolicySearch      string
+	MobilePhoneSearch string
+	Score             float64
+	MatchedBy         string
+}
+
+// SearchPatients ищет пациентов по похожести ФИО (pg_trgm) и точному совпадению СНИЛС, полиса или телефона.
+// Точные совпадения идут первыми, остальные — по убыванию похожести
+func (r *PatientRepositoryImpl) SearchPatients(ctx context.Context, query models.PatientSearchQuery) ([]models.PatientSearchHit, error) {
+	trgm, err := r.hasTrgm(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pg_trgm: %w", err)
+	}
+	if !trgm {
+		return r.searchPatientsInProcess(ctx, query)
+	}
+
+	db := r.db.GetDB(ctx)
+
+	var conditions, matches []string
+	var conditionArgs, matchArgs []interface{}
+	if query.Name != "" {
+		conditions = append(conditions, "? <% p.full_name_search")
+		conditionArgs = append(conditionArgs, query.Name)
+	}
+	for _, doc := range []struct{ column, value, name string }{
+		{"mc.snils_search", query.Snils, "snils"},
+		{"mc.policy_search", query.Policy, "policy"},
+		{"mc.mobile_phone_search", query.Phone, "phone"},
+	} {
+		if doc.value == "" {
+			continue
+		}
+		conditions = append(conditions, doc.column+" = ?")
+		conditionArgs = append(conditionArgs, doc.value)
+		matches = append(matches, fmt.Sprintf("WHEN %s = ? THEN '%s'", doc.column, doc.name))
+		matchArgs = append(matchArgs, doc.value)
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	matchedBy := "'name'"
+	if len(matches) > 0 {
+		matchedBy = "CASE " + strings.Join(matches, " ") + " ELSE 'name' END"
+	}
+
+	var rows []patientSearchRow
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Порог для оператора <% действует только в этой транзакции
+		threshold := strconv.FormatFloat(query.MinScore, 'f', 2, 64)
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", threshold).Error; err != nil {
+			return err
+		}
+
+		args := append([]interface{}{query.Name}, matchArgs...)
+		found := tx.Table("one_c_patient_list_items AS p").
+			Select("p.*, word_similarity(?, p.full_name_search) AS score, "+matchedBy+" AS matched_by", args...).
+			Joins("LEFT JOIN one_c_medical_cards mc ON mc.patient_id = p.patient_id").
+			Where(strings.Join(conditions, " OR "), conditionArgs...)
+
+		// Сортировка по вычисленным колонкам возможна только во внешнем запросе
+		return tx.Table("(?) AS hits", found).
+			Order("matched_by = 'name', score DESC, id").
+			Limit(query.Limit).
+			Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return searchHits(rows), nil
+}
+
+// hasTrgm сообщает, установлено ли расширение pg_trgm. Запоминается только успешный ответ БД,
+// чтобы отменённый запрос или временный сбой не отключили триграммный поиск до перезапуска
+func (r *PatientRepositoryImpl) hasTrgm(ctx context.Context) (bool, error) {
+	r.trgmMu.Lock()
+	defer r.trgmMu.Unlock()
+	if r.trgmChecked {
+		return r.trgmAvailable, nil
+	}
+
+	var available bool
+	db := r.db.GetDB(ctx)
+	if err := db.WithContext(ctx).
+		Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm')").
+		Scan(&available).Error; err != nil {
+		return false, err
+	}
+	r.trgmChecked, r.trgmAvailable = true, available
+	return available, nil
+}
+
+// searchPatientsInProcess — поиск без pg_trgm: похожесть ФИО считается в памяти тем же алгоритмом.
+// Список пациентов просматривается целиком пачками по id, в памяти держатся только лучшие query.Limit
+func (r *PatientRepositoryImpl) searchPatientsInProcess(ctx context.Context, query models.PatientSearchQuery) ([]models.PatientSearchHit, error) {
+	db := r.db.GetDB(ctx)
+	var rows []patientSearchRow
+	var lastID uint
+	for {
+		var batch []patientSearchRow
+		if err := db.WithContext(ctx).
+			Table("one_c_patient_list_items AS p").
+			Select("p.*, mc.snils_search, mc.policy_search, mc.mobile_phone_search").
+			Joins("LEFT JOIN one_c_medical_cards mc ON mc.patient_id = p.patient_id").
+			Where("p.id > ?", lastID).
+			Order("p.id").
+			Limit(inProcessSearchBatch).
+			Scan(&batch).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range batch {
+			if matchPatientInProcess(&row, query) {
+				rows = append(rows, row)
+			}
+		}
+		rows = bestSearchRows(rows, query.Limit)
+
+		if len(batch) < inProcessSearchBatch {
+			break
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+
+	return searchHits(rows), nil
+}
+
+// matchPatientInProcess заполняет MatchedBy и Score и сообщает, подходит ли пациент под запрос
+func matchPatientInProcess(row *patientSearchRow, query models.PatientSearchQuery) bool {
+	switch {
+	case query.Snils != "" && row.SnilsSearch == query.Snils:
+		row.MatchedBy = "snils"
+	case query.Policy != "" && row.PolicySearch == query.Policy:
+		row.MatchedBy = "policy"
+	case query.Phone != "" && row.MobilePhoneSearch == query.Phone:
+		row.MatchedBy = "phone"
+	default:
+		row.MatchedBy = "name"
+	}
+	if query.Name != "" {
+		row.Score = namesearch.WordSimilarity(query.Name, row.FullNameSearch)
+	}
+	return row.MatchedBy != "name" || (query.Name != "" && row.Score >= query.MinScore)
+}
+
+// bestSearchRows оставляет limit лучших: точные совпадения, затем по убыванию похожести.
+// Сортировка устойчивая, поэтому при равной оценке раньше идёт пациент с меньшим id
+func bestSearchRows(rows []patientSearchRow, limit int) []patientSearchRow {
+	sort.SliceStable(rows, func(i, j int) bool {
+		exactI, exactJ := rows[i].MatchedBy != "name", rows[j].MatchedBy != "name"
+		if exactI != exactJ {
+			return exactI
+		}
+		return rows[i].Score > rows[j].Score
+	})
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+func searchHits(rows []patientSearchRow) []models.PatientSearchHit {
+	hits := make([]models.PatientSearchHit, len(rows))
+	for i, row := range rows {
+		score := row.Score
+		if row.MatchedBy != "name" {
+			score = 1
+		}
+		hits[i] = models.PatientSearchHit{
+			Patient:   row.OneCPatientListItem,
+			Score:     score,
+			MatchedBy: row.MatchedBy,
+		}
+	}
+	return hits
+}
+
+func fillSearchColumns(patients []entities.OneCPatientListItem) {
+	for i := range patients {
+		patients[i].FillSearchColumns()
+	}
+}
+
+// GetPatientListItem возвращает пациента из списка или nil, если его там нет
+func (r *PatientRepositoryImpl) GetPatientListItem(ctx context.Context, patientID string) (*entities.OneCPatientListItem, error) {
+	var patients []entities.OneCPatientListItem
+	db := r.db.GetDB(ctx)
+	if err := db.WithContext(ctx).Where("patient_id = ?", patientID).Limit(1).Find(&patients).Error; err != nil {
+		return nil, err
+	}
+	if len(patients) == 0 {
+		return nil, nil
+	}
+	return &patients[0], nil
+}
+
+// GetPatientsByPolicy возвращает пациентов из списка, чья медкарта содержит полис policy (вид validation.SearchKey)
+func (r *PatientRepositoryImpl) GetPatientsByPolicy(ctx context.Context, policy string, limit int) ([]entities.OneCPatientListItem, error) {
+	var patients []entities.OneCPatientListItem
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Where("patient_id IN (?)", db.Model(&entities.OneCMedicalCard{}).Select("patient_id").Where("policy_search = ?", policy)).
+		Order("patient_id").
+		Limit(limit).
+		Find(&patients).Error
+	return patients, err
+}
@@ -1,6 +1,9 @@
 package patient
 
 import (
+	"context"
+	"sync"
+
 	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
 	"gorm.io/gorm"
@@ -8,8 +11,16 @@ import (
 
 type PatientRepositoryImpl struct {
 	db *base.BaseRepository
+
+	// Установлено ли расширение pg_trgm. Проверяется при создании репозитория (после миграций),
+	// а если БД тогда не ответила — при каждом поиске, пока проверка не пройдёт
+	trgmMu        sync.Mutex
+	trgmChecked   bool
+	trgmAvailable bool
 }
 
 func NewPatientRepository(db *gorm.DB) interfaces.PatientRepository {
-	return &PatientRepositoryImpl{db: base.NewBaseRepository(db)}
+	r := &PatientRepositoryImpl{db: base.NewBaseRepository(db)}
+	_, _ = r.hasTrgm(context.Background())
+	return r
 }
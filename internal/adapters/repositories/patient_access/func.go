@@ -0,0 +1,69 @@
+package patientAccess
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+)
+
+func (r *PatientAccessRepository) SavePatientAccessLog(ctx context.Context, entry *entities.PatientAccessLog) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Create(entry).Error
+}
+
+// SavePatientAccessLogs записывает в журнал несколько обращений разом (например, медкарты из ленты синхронизации)
+func (r *PatientAccessRepository) SavePatientAccessLogs(ctx context.Context, entries []entities.PatientAccessLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).CreateInBatches(entries, 100).Error
+}
+
+func (r *PatientAccessRepository) SaveEmergencyAccessGrant(ctx context.Context, grant *entities.EmergencyAccessGrant) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Create(grant).Error
+}
+
+// HasActiveEmergencyAccessGrant проверяет, есть ли у пользователя действующий экстренный доступ к пациенту
+func (r *PatientAccessRepository) HasActiveEmergencyAccessGrant(ctx context.Context, userID uint, patientID string, now time.Time) (bool, error) {
+	var count int64
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Model(&entities.EmergencyAccessGrant{}).
+		Where("user_id = ? AND patient_id = ? AND expires_at > ?", userID, patientID, now).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetEmergencyAccessReport возвращает экстренные доступы с числом обращений к пациенту по каждому из них
+func (r *PatientAccessRepository) GetEmergencyAccessReport(ctx context.Context, filter models.EmergencyAccessFilter) ([]models.EmergencyAccessReportItem, error) {
+	db := r.db.GetDB(ctx)
+	query := db.WithContext(ctx).
+		Table("emergency_access_grants AS g").
+		Select(`g.*, u.login AS user_login,
+			(SELECT COUNT(*) FROM patient_access_logs l
+			 WHERE l.user_id = g.user_id AND l.patient_id = g.patient_id AND l.reason = ?
+			   AND l.created_at BETWEEN g.created_at AND g.expires_at) AS access_count`,
+			entities.AccessReasonEmergency).
+		Joins("LEFT JOIN auth_users u ON u.id = g.user_id")
+
+	if !filter.From.IsZero() {
+		query = query.Where("g.created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("g.created_at < ?", filter.To.AddDate(0, 0, 1))
+	}
+	if filter.UserID != 0 {
+		query = query.Where("g.user_id = ?", filter.UserID)
+	}
+	if filter.PatientID != "" {
+		query = query.Where("g.patient_id = ?", filter.PatientID)
+	}
+
+	var items []models.EmergencyAccessReportItem
+	err := query.Order("g.created_at DESC").Scan(&items).Error
+	return items, err
+}
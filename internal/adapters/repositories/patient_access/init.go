@@ -0,0 +1,15 @@
+package patientAccess
+
+import (
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type PatientAccessRepository struct {
+	db *base.BaseRepository
+}
+
+func NewPatientAccessRepository(db *gorm.DB) interfaces.PatientAccessRepository {
+	return &PatientAccessRepository{db: base.NewBaseRepository(db)}
+}
@@ -0,0 +1,281 @@
+package patientDuplicate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// patientRecordsQuery — пациенты из списка 1С с документами из медкарты и пациенты вызовов,
+// которых в списке нет (добавлены бригадой). Для пациента из нескольких вызовов берётся последний вызов
+const patientRecordsQuery = `
+SELECT * FROM (
+	SELECT p.patient_id, p.full_name, p.birth_date,
+		COALESCE(mc.snils, '') AS snils, COALESCE(mc.policy_number, '') AS policy, 'list' AS source
+	FROM one_c_patient_list_items p
+	LEFT JOIN one_c_medical_cards mc ON mc.patient_id = p.patient_id
+	UNION ALL
+	(SELECT DISTINCT ON (e->>'patient_id') e->>'patient_id', COALESCE(e->>'full_name', ''), COALESCE(e->>'birth_date', ''),
+		COALESCE(e->>'snils', ''), COALESCE(e->'policy'->>'number', ''), 'call'
+	FROM one_c_receptions r
+	CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(r.data) = 'array' THEN r.data ELSE '[]'::jsonb END) e
+	WHERE COALESCE(e->>'patient_id', '') <> ''
+		AND NOT EXISTS (SELECT 1 FROM one_c_patient_list_items p WHERE p.patient_id = e->>'patient_id')
+	ORDER BY e->>'patient_id', r.updated_at DESC)
+) AS records`
+
+// GetDuplicateScanRecords возвращает всех пациентов для поиска дублей
+func (r *PatientDuplicateRepository) GetDuplicateScanRecords(ctx context.Context) ([]models.PatientRecord, error) {
+	var records []models.PatientRecord
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).Raw(patientRecordsQuery).Scan(&records).Error
+	return records, err
+}
+
+// GetPatientRecords возвращает пациентов по ID
+func (r *PatientDuplicateRepository) GetPatientRecords(ctx context.Context, patientIDs []string) ([]models.PatientRecord, error) {
+	if len(patientIDs) == 0 {
+		return nil, nil
+	}
+
+	var records []models.PatientRecord
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).Raw(patientRecordsQuery+" WHERE patient_id IN ?", patientIDs).Scan(&records).Error
+	return records, err
+}
+
+// ReplacePendingDuplicateCandidates сохраняет результат проверки, начатой в scannedAt.
+// Новые пары добавляются, у ожидающих обновляется оценка, ожидающие пары, которых больше нет в результате,
+// удаляются. Решения администратора (объединены, отклонены) не меняются
+func (r *PatientDuplicateRepository) ReplacePendingDuplicateCandidates(ctx context.Context, candidates []entities.PatientDuplicateCandidate, scannedAt time.Time) error {
+	for i := range candidates {
+		candidates[i].Status = entities.DuplicatePending
+		candidates[i].UpdatedAt = scannedAt
+	}
+
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(candidates) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "patient_id"}, {Name: "other_patient_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"score", "reasons", "updated_at"}),
+				Where: clause.Where{Exprs: []clause.Expression{
+					clause.Eq{Column: clause.Column{Table: "patient_duplicate_candidates", Name: "status"}, Value: entities.DuplicatePending},
+				}},
+			}).CreateInBatches(candidates, 100).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.
+			Where("status = ? AND updated_at < ?", entities.DuplicatePending, scannedAt).
+			Delete(&entities.PatientDuplicateCandidate{}).Error
+	})
+}
+
+// ListDuplicateCandidates возвращает пары с указанным статусом, самые вероятные дубли первыми
+func (r *PatientDuplicateRepository) ListDuplicateCandidates(ctx context.Context, status entities.PatientDuplicateStatus, limit int) ([]entities.PatientDuplicateCandidate, error) {
+	var candidates []entities.PatientDuplicateCandidate
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("score DESC, id").
+		Limit(limit).
+		Find(&candidates).Error
+	return candidates, err
+}
+
+func (r *PatientDuplicateRepository) GetDuplicateCandidate(ctx context.Context, id uint) (*entities.PatientDuplicateCandidate, error) {
+	var candidate entities.PatientDuplicateCandidate
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).First(&candidate, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &candidate, err
+}
+
+// DismissDuplicateCandidate отмечает пару как разных людей. Уже рассмотренная пара — errors.ErrEmptyAction
+func (r *PatientDuplicateRepository) DismissDuplicateCandidate(ctx context.Context, id, reviewerID uint) error {
+	db := r.db.GetDB(ctx)
+	return reviewCandidate(db.WithContext(ctx), id, reviewerID, entities.DuplicateDismissed)
+}
+
+func reviewCandidate(tx *gorm.DB, id, reviewerID uint, status entities.PatientDuplicateStatus) error {
+	now := time.Now()
+	result := tx.Model(&entities.PatientDuplicateCandidate{}).
+		Where("id = ? AND status = ?", id, entities.DuplicatePending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewerID,
+			"reviewed_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: duplicate candidate %d is already reviewed", errors.ErrEmptyAction, id)
+	}
+	return nil
+}
+
+// MergePatients переносит данные merge.MergedID на merge.SurvivorID и ставит объединение в очередь отправки в 1С.
+// Переносятся медкарта (если у остающейся записи её нет), пациенты вызовов, назначения бригад,
+// история правок медкарт и экстренные доступы. Журнал доступа к пациентам не меняется — это аудит
+func (r *PatientDuplicateRepository) MergePatients(ctx context.Context, candidateID uint, merge *entities.PatientMerge) error {
+	survivor, merged := merge.SurvivorID, merge.MergedID
+	merge.Status = entities.PatientMergePendingSync
+
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := base.LockChangeFeed(tx); err != nil {
+			return err
+		}
+		if err := reviewCandidate(tx, candidateID, merge.MergedBy, entities.DuplicateMerged); err != nil {
+			return err
+		}
+
+		// Запись в списке пациентов
+		result := tx.Where("patient_id = ?", merged).Delete(&entities.OneCPatientListItem{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := base.RecordDeletions(tx, entities.SyncEntityPatient, []string{merged}); err != nil {
+				return err
+			}
+		}
+
+		if err := mergeMedicalCard(tx, survivor, merged); err != nil {
+			return err
+		}
+
+		// Назначения бригад: строка, которая после переноса совпала бы с существующей, удаляется
+		if err := tx.Exec(`DELETE FROM call_assignments ca WHERE ca.patient_id = ? AND EXISTS (
+			SELECT 1 FROM call_assignments s WHERE s.call_id = ca.call_id AND s.login = ca.login AND s.patient_id = ?)`,
+			merged, survivor).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entities.CallAssignment{}).Where("patient_id = ?", merged).
+			Update("patient_id", survivor).Error; err != nil {
+			return err
+		}
+
+		// Пациенты в JSON вызовов
+		if err := tx.Exec(`UPDATE one_c_receptions SET
+			data = (SELECT jsonb_agg(CASE WHEN e->>'patient_id' = ? THEN jsonb_set(e, '{patient_id}', to_jsonb(?::text)) ELSE e END ORDER BY ord)
+				FROM jsonb_array_elements(data) WITH ORDINALITY AS t(e, ord)),
+			change_seq = nextval('sync_change_seq'),
+			updated_at = now()
+			WHERE jsonb_typeof(data) = 'array' AND data @> jsonb_build_array(jsonb_build_object('patient_id', ?::text))`,
+			merged, survivor, merged).Error; err != nil {
+			return err
+		}
+
+		// История правок и экстренные доступы
+		if err := tx.Model(&entities.MedCardEdit{}).Where("patient_id = ?", merged).
+			Update("patient_id", survivor).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entities.EmergencyAccessGrant{}).Where("patient_id = ?", merged).
+			Update("patient_id", survivor).Error; err != nil {
+			return err
+		}
+
+		// Недавние и избранные: если у пользователя есть обе записи, остаётся одна
+		if err := tx.Exec(`UPDATE patient_views s SET
+			viewed_at = GREATEST(s.viewed_at, m.viewed_at),
+			view_count = s.view_count + m.view_count
+			FROM patient_views m
+			WHERE m.user_id = s.user_id AND s.patient_id = ? AND m.patient_id = ?`,
+			survivor, merged).Error; err != nil {
+			return err
+		}
+		for _, table := range []string{"patient_views", "favorite_patients"} {
+			if err := tx.Exec(`DELETE FROM `+table+` m WHERE m.patient_id = ? AND EXISTS (
+				SELECT 1 FROM `+table+` s WHERE s.user_id = m.user_id AND s.patient_id = ?)`,
+				merged, survivor).Error; err != nil {
+				return err
+			}
+			if err := tx.Table(table).Where("patient_id = ?", merged).
+				Update("patient_id", survivor).Error; err != nil {
+				return err
+			}
+		}
+
+		// Остальные пары с удалённой записью устарели: при следующей проверке они сравнятся с остающейся
+		if err := tx.
+			Where("status = ? AND (patient_id = ? OR other_patient_id = ?)", entities.DuplicatePending, merged, merged).
+			Delete(&entities.PatientDuplicateCandidate{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(merge).Error
+	})
+}
+
+// mergeMedicalCard оставляет медкарту остающейся записи, а если её нет — переносит медкарту объединяемой
+func mergeMedicalCard(tx *gorm.DB, survivor, merged string) error {
+	var survivorCards int64
+	if err := tx.Model(&entities.OneCMedicalCard{}).Where("patient_id = ?", survivor).Count(&survivorCards).Error; err != nil {
+		return err
+	}
+
+	var result *gorm.DB
+	if survivorCards > 0 {
+		result = tx.Where("patient_id = ?", merged).Delete(&entities.OneCMedicalCard{})
+	} else {
+		seq, err := base.NextChangeSeq(tx)
+		if err != nil {
+			return err
+		}
+		result = tx.Model(&entities.OneCMedicalCard{}).Where("patient_id = ?", merged).
+			Updates(map[string]interface{}{"patient_id": survivor, "change_seq": seq})
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	// Клиенты хранят медкарту под ID объединённой записи
+	return base.RecordDeletions(tx, entities.SyncEntityMedicalCard, []string{merged})
+}
+
+// ClaimPendingPatientMerge блокирует до конца транзакции из контекста первое после afterID объединение,
+// ещё не отправленное в 1С. Объединения, заблокированные другими репликами, пропускаются.
+// Если отправлять нечего, возвращается nil
+func (r *PatientDuplicateRepository) ClaimPendingPatientMerge(ctx context.Context, afterID uint) (*entities.PatientMerge, error) {
+	return claimPatientMerge(r.db.GetDB(ctx).WithContext(ctx).Where("id > ?", afterID))
+}
+
+// ClaimPatientMerge блокирует объединение id до конца транзакции из контекста, если оно ещё не отправлено
+// и его не отправляет другая реплика. Иначе возвращается nil
+func (r *PatientDuplicateRepository) ClaimPatientMerge(ctx context.Context, id uint) (*entities.PatientMerge, error) {
+	return claimPatientMerge(r.db.GetDB(ctx).WithContext(ctx).Where("id = ?", id))
+}
+
+func claimPatientMerge(query *gorm.DB) (*entities.PatientMerge, error) {
+	var merge entities.PatientMerge
+	err := query.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ?", entities.PatientMergePendingSync).
+		Order("id").
+		Take(&merge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &merge, err
+}
+
+func (r *PatientDuplicateRepository) UpdatePatientMerge(ctx context.Context, merge *entities.PatientMerge) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Save(merge).Error
+}
@@ -0,0 +1,15 @@
+package patientDuplicate
+
+import (
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type PatientDuplicateRepository struct {
+	db *base.BaseRepository
+}
+
+func NewPatientDuplicateRepository(db *gorm.DB) interfaces.PatientDuplicateRepository {
+	return &PatientDuplicateRepository{db: base.NewBaseRepository(db)}
+}
@@ -0,0 +1,90 @@
+package quickAccess
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// quickAccessColumns — ФИО и дата рождения из списка пациентов, а если пациента в нём нет — из медкарты
+const quickAccessColumns = `COALESCE(p.full_name, mc.display_name, '') AS full_name,
+	COALESCE(p.birth_date, mc.birth_date, '') AS birth_date`
+
+const quickAccessJoins = `LEFT JOIN one_c_patient_list_items p ON p.patient_id = %[1]s.patient_id
+	LEFT JOIN one_c_medical_cards mc ON mc.patient_id = %[1]s.patient_id`
+
+// RecordPatientView отмечает просмотр медкарты: обновляет время и увеличивает счётчик
+func (r *QuickAccessRepository) RecordPatientView(ctx context.Context, userID uint, patientID string, viewedAt time.Time) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "patient_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"viewed_at":  viewedAt,
+			"view_count": gorm.Expr("patient_views.view_count + 1"),
+		}),
+	}).Create(&entities.PatientView{UserID: userID, PatientID: patientID, ViewedAt: viewedAt, ViewCount: 1}).Error
+}
+
+// GetRecentPatients возвращает пациентов, чьи медкарты пользователь открывал после since, последние первыми
+func (r *QuickAccessRepository) GetRecentPatients(ctx context.Context, userID uint, since time.Time, limit int) ([]models.QuickAccessPatient, error) {
+	var patients []models.QuickAccessPatient
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Table("patient_views AS v").
+		Select("v.patient_id, v.viewed_at, v.view_count, f.created_at AS added_at, f.user_id IS NOT NULL AS favorite, "+quickAccessColumns).
+		Joins("LEFT JOIN favorite_patients f ON f.user_id = v.user_id AND f.patient_id = v.patient_id").
+		Joins(sprintfJoins("v")).
+		Where("v.user_id = ? AND v.viewed_at >= ?", userID, since).
+		Order("v.viewed_at DESC, v.patient_id").
+		Limit(limit).
+		Scan(&patients).Error
+	return patients, err
+}
+
+// GetFavoritePatients возвращает избранных пациентов пользователя по ФИО
+func (r *QuickAccessRepository) GetFavoritePatients(ctx context.Context, userID uint, since time.Time) ([]models.QuickAccessPatient, error) {
+	var patients []models.QuickAccessPatient
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Table("favorite_patients AS f").
+		Select("f.patient_id, f.created_at AS added_at, TRUE AS favorite, v.viewed_at, v.view_count, "+quickAccessColumns).
+		Joins("LEFT JOIN patient_views v ON v.user_id = f.user_id AND v.patient_id = f.patient_id AND v.viewed_at >= ?", since).
+		Joins(sprintfJoins("f")).
+		Where("f.user_id = ?", userID).
+		Order("full_name, f.patient_id").
+		Scan(&patients).Error
+	return patients, err
+}
+
+// AddFavoritePatient добавляет пациента в избранное. Повторное добавление ничего не меняет
+func (r *QuickAccessRepository) AddFavoritePatient(ctx context.Context, userID uint, patientID string) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entities.FavoritePatient{UserID: userID, PatientID: patientID}).Error
+}
+
+// RemoveFavoritePatient убирает пациента из избранного. false — его там не было
+func (r *QuickAccessRepository) RemoveFavoritePatient(ctx context.Context, userID uint, patientID string) (bool, error) {
+	db := r.db.GetDB(ctx)
+	result := db.WithContext(ctx).
+		Where("user_id = ? AND patient_id = ?", userID, patientID).
+		Delete(&entities.FavoritePatient{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// PruneRecentPatients удаляет просмотры старше before
+func (r *QuickAccessRepository) PruneRecentPatients(ctx context.Context, before time.Time) (int64, error) {
+	db := r.db.GetDB(ctx)
+	result := db.WithContext(ctx).Where("viewed_at < ?", before).Delete(&entities.PatientView{})
+	return result.RowsAffected, result.Error
+}
+
+func sprintfJoins(alias string) string {
+	return fmt.Sprintf(quickAccessJoins, alias)
+}
@@ -0,0 +1,15 @@
+package quickAccess
+
+import (
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type QuickAccessRepository struct {
+	db *base.BaseRepository
+}
+
+func NewQuickAccessRepository(db *gorm.DB) interfaces.QuickAccessRepository {
+	return &QuickAccessRepository{db: base.NewBaseRepository(db)}
+}
@@ -3,7 +3,9 @@ package receptionSmp
 import (
 	"context"
 	"encoding/json"
+	"time"
 
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
 	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
 	"gorm.io/gorm"
 
@@ -25,6 +27,9 @@ func (r *ReceptionSmpRepositoryImpl) SaveReceptions(ctx context.Context, callID
 	}
 	db := r.db.GetDB(ctx)
 	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := base.LockChangeFeed(tx); err != nil {
+			return err
+		}
 		// Удаляем старую запись, если есть
 		if err := tx.Where("call_id = ?", callID).Delete(&entities.OneCReception{}).Error; err != nil {
 			return err
@@ -52,3 +57,126 @@ func (r *ReceptionSmpRepositoryImpl) GetReceptions(ctx context.Context, callID s
 	}
 	return patients, nil
 }
+
+// ReplaceCallAssignments заменяет назначения бригады на вызов. Пустой список снимает все назначения.
+// Снятые назначения попадают в ленту изменений, чтобы клиенты члена бригады удалили вызов и медкарты
+// его пациентов; медкарты пациентов, назначенных впервые, отдаются в ленте заново
+func (r *ReceptionSmpRepositoryImpl) ReplaceCallAssignments(ctx context.Context, callID string, assignments []entities.CallAssignment) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := base.LockChangeFeed(tx); err != nil {
+			return err
+		}
+		var previous []entities.CallAssignment
+		if err := tx.Where("call_id = ?", callID).Find(&previous).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("call_id = ?", callID).Delete(&entities.CallAssignment{}).Error; err != nil {
+			return err
+		}
+		if len(assignments) > 0 {
+			if err := tx.Create(&assignments).Error; err != nil {
+				return err
+			}
+		}
+		return recordAssignmentChanges(tx, callID, previous, assignments)
+	})
+}
+
+// recordAssignmentChanges записывает в ленту изменений разницу между старыми и новыми назначениями вызова.
+// Останется ли медкарта видна пользователю по другому вызову, проверяется при чтении ленты
+func recordAssignmentChanges(tx *gorm.DB, callID string, previous, current []entities.CallAssignment) error {
+	type key struct{ login, patientID string }
+	was := make(map[key]bool, len(previous))
+	for _, a := range previous {
+		was[key{a.Login, a.PatientID}] = true
+	}
+	now := make(map[key]bool, len(current))
+	nowLogin := make(map[string]bool)
+	var added []string
+	for _, a := range current {
+		now[key{a.Login, a.PatientID}] = true
+		nowLogin[a.Login] = true
+		if !was[key{a.Login, a.PatientID}] {
+			added = append(added, a.PatientID)
+		}
+	}
+
+	removed := make(map[string][]string)
+	for _, a := range previous {
+		if !now[key{a.Login, a.PatientID}] {
+			removed[a.Login] = append(removed[a.Login], a.PatientID)
+		}
+	}
+	for login, patientIDs := range removed {
+		if err := base.RecordScopeRemovals(tx, entities.SyncEntityMedicalCard, login, patientIDs); err != nil {
+			return err
+		}
+		if !nowLogin[login] {
+			if err := base.RecordScopeRemovals(tx, entities.SyncEntityCall, login, []string{callID}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Номер изменения медкарты не менялся, и без нового номера назначенный член бригады её не получит
+	if len(added) == 0 {
+		return nil
+	}
+	return tx.Model(&entities.OneCMedicalCard{}).
+		Where("patient_id IN ?", added).
+		UpdateColumn("change_seq", gorm.Expr("nextval('sync_change_seq')")).Error
+}
+
+// HasCallAssignment проверяет, назначен ли пользователь на активный вызов к пациенту
+func (r *ReceptionSmpRepositoryImpl) HasCallAssignment(ctx context.Context, login, patientID string) (bool, error) {
+	var count int64
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Model(&entities.CallAssignment{}).
+		Where("login = ? AND patient_id = ?", login, patientID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// callListOrder — сначала недавно обновлённые вызовы. call_id уникален и не меняется при пересохранении из 1С
+var callListOrder = []models.OrderColumn{
+	{Column: "updated_at", Desc: true},
+	{Column: "call_id", Desc: true},
+}
+
+// ListCalls возвращает страницу вызовов по курсору. assignedUserID — только вызовы, на которые назначен
+// пользователь; 0 — все вызовы. Общее число считается только при page.WithTotal
+func (r *ReceptionSmpRepositoryImpl) ListCalls(ctx context.Context, assignedUserID uint, page models.CursorPageRequest) (*models.CursorPage[[]entities.OneCReception], error) {
+	keyset, err := base.NewKeyset(callListOrder, page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	db := r.db.GetDB(ctx)
+	query := db.WithContext(ctx).Model(&entities.OneCReception{})
+	if assignedUserID != 0 {
+		query = query.Where(`call_id IN (SELECT ca.call_id FROM call_assignments ca
+			JOIN auth_users u ON u.login = ca.login WHERE u.id = ?)`, assignedUserID)
+	}
+	query = query.Session(&gorm.Session{})
+
+	result := &models.CursorPage[[]entities.OneCReception]{HitsPerPage: page.Limit}
+	if page.WithTotal {
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		result.TotalHits = &total
+	}
+
+	calls, next, prev, err := base.FetchPage(keyset, query, page.Limit, func(call entities.OneCReception) []interface{} {
+		return []interface{}{call.UpdatedAt.UTC().Format(time.RFC3339Nano), call.CallID}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Hits, result.NextCursor, result.PrevCursor = calls, next, prev
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package security
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReserveLoginAttempt атомарно проверяет блокировку ключа и засчитывает попытку входа до проверки пароля.
+// Строка счётчика блокируется до конца транзакции, поэтому параллельные попытки видят результат друг друга
+// и не могут обойти лимит. Если ключ заблокирован, попытка не засчитывается и возвращается false.
+// Иначе счётчик увеличивается (или начинается заново, если последняя попытка была раньше windowStart),
+// и если он достиг free, ключ сразу блокируется на lockFor(failures-free): успешный вход снимет блокировку
+func (r *SecurityRepository) ReserveLoginAttempt(ctx context.Context, key string, now, windowStart time.Time, free int, lockFor func(step int) time.Duration) (entities.LoginAttemptCounter, bool, error) {
+	counter := entities.LoginAttemptCounter{Key: key, LastFailureAt: now}
+	allowed := false
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&counter).Error; err != nil {
+			return err
+		}
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).
+			Take(&counter).Error
+		if err != nil {
+			return err
+		}
+		if counter.LockedUntil != nil && counter.LockedUntil.After(now) {
+			return nil
+		}
+
+		allowed = true
+		if counter.LastFailureAt.Before(windowStart) {
+			counter.Failures = 0
+		}
+		counter.Failures++
+		counter.LastFailureAt = now
+		counter.LockedUntil = nil
+		if counter.Failures >= free {
+			// Точность timestamp в Postgres — микросекунды; ReleaseLoginAttempt сравнивает значение целиком
+			until := now.Add(lockFor(counter.Failures - free)).Truncate(time.Microsecond)
+			counter.LockedUntil = &until
+		}
+		return tx.Model(&counter).Select("failures", "last_failure_at", "locked_until").Updates(&counter).Error
+	})
+	return counter, allowed, err
+}
+
+// ReleaseLoginAttempt возвращает попытку, засчитанную ReserveLoginAttempt, после успешного входа:
+// уменьшает счётчик и снимает блокировку, если её поставила именно эта попытка (lockedUntil)
+func (r *SecurityRepository) ReleaseLoginAttempt(ctx context.Context, key string, lockedUntil *time.Time) error {
+	db := r.db.GetDB(ctx)
+	updates := map[string]interface{}{"failures": gorm.Expr("GREATEST(failures - 1, 0)")}
+	query := db.WithContext(ctx).Model(&entities.LoginAttemptCounter{}).Where("key = ?", key)
+	if lockedUntil != nil {
+		query = query.Where("locked_until = ?", *lockedUntil)
+		updates["locked_until"] = nil
+	}
+	return query.Updates(updates).Error
+}
+
+// ResetLoginCounter снимает блокировку и обнуляет счётчик. Возвращает false, если счётчика не было
+func (r *SecurityRepository) ResetLoginCounter(ctx context.Context, key string) (bool, error) {
+	db := r.db.GetDB(ctx)
+	result := db.WithContext(ctx).Where("key = ?", key).Delete(&entities.LoginAttemptCounter{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *SecurityRepository) SaveSecurityEvent(ctx context.Context, event *entities.SecurityEvent) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Create(event).Error
+}
+
+func (r *SecurityRepository) GetSecurityEvents(ctx context.Context, filter models.SecurityEventFilter) ([]entities.SecurityEvent, error) {
+	db := r.db.GetDB(ctx)
+	query := db.WithContext(ctx).Model(&entities.SecurityEvent{})
+
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To.AddDate(0, 0, 1))
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Login != "" {
+		query = query.Where("login = ?", filter.Login)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+
+	var events []entities.SecurityEvent
+	err := query.Order("created_at DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
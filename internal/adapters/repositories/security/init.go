@@ -0,0 +1,15 @@
+package security
+
+import (
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type SecurityRepository struct {
+	db *base.BaseRepository
+}
+
+func NewSecurityRepository(db *gorm.DB) interfaces.SecurityRepository {
+	return &SecurityRepository{db: base.NewBaseRepository(db)}
+}
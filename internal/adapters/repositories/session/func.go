@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UpsertDevice регистрирует устройство пользователя или обновляет его данные
+func (r *SessionRepository) UpsertDevice(ctx context.Context, device *entities.Device) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "device_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"platform", "app_version", "push_token", "last_ip", "last_seen_at", "updated_at"}),
+		}).
+		Create(device).Error
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *entities.Session) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Create(session).Error
+}
+
+// TouchSession продлевает сеанс при обновлении токенов
+func (r *SessionRepository) TouchSession(ctx context.Context, id, ip string, expiresAt time.Time) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).
+		Model(&entities.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"ip":           ip,
+			"expires_at":   expiresAt,
+			"last_used_at": time.Now(),
+		}).Error
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, id string) (*entities.Session, error) {
+	var session entities.Session
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &session, err
+}
+
+// RevokeSession помечает сеанс отозванным. Refresh-токены семейства отзываются отдельно
+func (r *SessionRepository) RevokeSession(ctx context.Context, id string) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).
+		Model(&entities.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *SessionRepository) IsSessionRevoked(ctx context.Context, id string) (bool, error) {
+	var count int64
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Model(&entities.Session{}).
+		Where("id = ? AND revoked_at IS NOT NULL", id).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetActiveSessions возвращает неотозванные и неистёкшие сеансы пользователя с данными устройств
+func (r *SessionRepository) GetActiveSessions(ctx context.Context, userID uint, now time.Time) ([]models.SessionInfo, error) {
+	var sessions []models.SessionInfo
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Table("sessions AS s").
+		Select("s.id, s.device_id, d.platform, d.app_version, s.ip, s.created_at, s.last_used_at, s.expires_at").
+		Joins("LEFT JOIN devices d ON d.user_id = s.user_id AND d.device_id = s.device_id").
+		Where("s.user_id = ? AND s.revoked_at IS NULL AND s.expires_at > ?", userID, now).
+		Order("s.last_used_at DESC").
+		Scan(&sessions).Error
+	return sessions, err
+}
@@ -0,0 +1,15 @@
+package session
+
+import (
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *base.BaseRepository
+}
+
+func NewSessionRepository(db *gorm.DB) interfaces.SessionRepository {
+	return &SessionRepository{db: base.NewBaseRepository(db)}
+}
@@ -0,0 +1,68 @@
+package token
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *TokenRepository) SaveRefreshToken(ctx context.Context, token *entities.RefreshToken) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Create(token).Error
+}
+
+func (r *TokenRepository) GetRefreshTokenByHash(ctx context.Context, hash string) (*entities.RefreshToken, error) {
+	var token entities.RefreshToken
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &token, err
+}
+
+// MarkRefreshTokenUsed помечает токен использованным. Возвращает false, если токен
+// уже был использован или отозван (например, параллельным запросом)
+func (r *TokenRepository) MarkRefreshTokenUsed(ctx context.Context, id uint) (bool, error) {
+	db := r.db.GetDB(ctx)
+	result := db.WithContext(ctx).
+		Model(&entities.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeRefreshTokenFamily отзывает все токены семейства (все устройства одного входа)
+func (r *TokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).
+		Model(&entities.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAccessToken добавляет jti в список отозванных и заодно чистит истёкшие записи
+func (r *TokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	db := r.db.GetDB(ctx)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&entities.RevokedAccessToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&entities.RevokedAccessToken{JTI: jti, ExpiresAt: expiresAt}).Error
+	})
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	db := r.db.GetDB(ctx)
+	err := db.WithContext(ctx).
+		Model(&entities.RevokedAccessToken{}).
+		Where("jti = ?", jti).
+		Count(&count).Error
+	return count > 0, err
+}
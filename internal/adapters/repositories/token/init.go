@@ -0,0 +1,15 @@
+package token
+
+import (
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type TokenRepository struct {
+	db *base.BaseRepository
+}
+
+func NewTokenRepository(db *gorm.DB) interfaces.TokenRepository {
+	return &TokenRepository{db: base.NewBaseRepository(db)}
+}
@@ -3,12 +3,22 @@ package tx
 import (
 	"context"
 
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories/base"
 	"gorm.io/gorm"
 )
 
-type contextKey string
-
-const txContextKey contextKey = "db_transaction"
+// WithinTransaction выполняет fn в транзакции. Репозитории, вызванные с переданным в fn контекстом,
+// работают в этой транзакции. Ошибка fn откатывает транзакцию. Внутри уже открытой транзакции
+// из ctx создаётся точка сохранения, а не отдельная транзакция, которая не видела бы её изменений
+func (tm *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	db := tm.db
+	if tx := tm.GetTransaction(ctx); tx != nil {
+		db = tx
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, base.TxContextKey, tx))
+	})
+}
 
 func (tm *TxManager) Commit(ctx context.Context) error {
 	tx := tm.GetTransaction(ctx)
@@ -27,7 +37,7 @@ func (tm *TxManager) Rollback(ctx context.Context) error {
 }
 
 func (tm *TxManager) GetTransaction(ctx context.Context) *gorm.DB {
-	if tx, ok := ctx.Value(txContextKey).(*gorm.DB); ok {
+	if tx, ok := ctx.Value(base.TxContextKey).(*gorm.DB); ok {
 		return tx
 	}
 	return nil
@@ -7,6 +7,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/geocoder"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/http/handlers"
 	httpClient "github.com/AlexanderMorozov1919/mobileapp/internal/adapters/http/onec"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/adapters/repositories"
@@ -30,6 +31,7 @@ func New() *fx.App {
 		),
 		LoggingModule,
 		OneCModule,
+		GeocoderModule,
 		WebsocketModule,
 		RepositoryModule,
 		ServiceModule,
@@ -134,16 +136,38 @@ var OneCModule = fx.Module("onec_module",
 		ProvideOneCClient,
 		usecases.NewOneCWebhookUsecase,
 		ProvidePatientSyncWorker,
+		ProvideMedCardSyncWorker,
+		ProvidePatientDuplicatesWorker,
+		ProvideRecentPatientsPruneWorker,
 	),
+	fx.Invoke(func(*workers.MedCardSyncWorker) {}),
+	fx.Invoke(func(*workers.PatientDuplicatesWorker) {}),
+	fx.Invoke(func(*workers.RecentPatientsPruneWorker) {}),
+)
+
+// ProvideGeocoder выбирает геокодер: офлайн-справочник, если он задан, иначе только разбор адресов
+func ProvideGeocoder(cfg *config.Config) (interfaces.Geocoder, error) {
+	if cfg.Geocoder.GazetteerPath == "" {
+		return geocoder.NewParseOnlyGeocoder(), nil
+	}
+	return geocoder.NewGazetteerGeocoder(cfg.Geocoder.GazetteerPath)
+}
+
+var GeocoderModule = fx.Module("geocoder_module",
+	fx.Provide(ProvideGeocoder),
 )
 
 var WebsocketModule = fx.Module("websocket_module",
 	fx.Provide(ProvideStdLogger,
-		websocket.NewHub,
+		ProvideHub,
 	),
 	fx.Invoke(websocket.InvokeHub),
 )
 
+func ProvideHub(logger *log.Logger, cfg *config.Config) *websocket.Hub {
+	return websocket.NewHub(logger, cfg.Server.AllowedOrigins)
+}
+
 func ProvidePatientSyncWorker(lc fx.Lifecycle, uc *usecases.OneCPatientUsecase, cfg *config.Config) *workers.PatientSyncWorker {
 	interval := time.Minute * 5
 	// if cfg.PatientSyncInterval > 0 {
@@ -169,6 +193,60 @@ func ProvidePatientSyncWorker(lc fx.Lifecycle, uc *usecases.OneCPatientUsecase,
 	return worker
 }
 
+func ProvideMedCardSyncWorker(lc fx.Lifecycle, uc interfaces.Usecases, cfg *config.Config) *workers.MedCardSyncWorker {
+	worker := workers.NewMedCardSyncWorker(uc, cfg.Workers.MedCardSyncInterval)
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// ctx из OnStart отменяется после старта приложения, воркеру нужен свой
+			worker.Start(context.Background())
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			worker.Stop()
+			return nil
+		},
+	})
+
+	return worker
+}
+
+func ProvidePatientDuplicatesWorker(lc fx.Lifecycle, uc interfaces.Usecases, cfg *config.Config) *workers.PatientDuplicatesWorker {
+	worker := workers.NewPatientDuplicatesWorker(uc, cfg.Workers.PatientDuplicatesInterval)
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// ctx из OnStart отменяется после старта приложения, воркеру нужен свой
+			worker.Start(context.Background())
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			worker.Stop()
+			return nil
+		},
+	})
+
+	return worker
+}
+
+func ProvideRecentPatientsPruneWorker(lc fx.Lifecycle, uc interfaces.Usecases, cfg *config.Config) *workers.RecentPatientsPruneWorker {
+	worker := workers.NewRecentPatientsPruneWorker(uc, cfg.Workers.RecentPatientsPruneInterval)
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// ctx из OnStart отменяется после старта приложения, воркеру нужен свой
+			worker.Start(context.Background())
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			worker.Stop()
+			return nil
+		},
+	})
+
+	return worker
+}
+
 // TODO: Может быть вынести в services
 func IntToUint(c int) uint {
 	if c < 0 {
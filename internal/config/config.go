@@ -4,14 +4,20 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/appversion"
 	"github.com/joho/godotenv"
 )
 
 type ServerConfig struct {
 	Port           string
 	AllowedOrigins []string
+
+	// IP и подсети прокси, которым доверяется X-Forwarded-For. Пусто — заголовок игнорируется,
+	// клиентом считается адрес соединения
+	TrustedProxies []string
 }
 
 type Config struct {
@@ -23,7 +29,74 @@ type Config struct {
 	Services   Services
 	Server     ServerConfig // Добавляем ServerConfig в основную структуру
 	JWTSecret  string
+	Auth       AuthConfig
 	MinIO      MinIOConfig
+	Workers    WorkersConfig
+	Clients    ClientsConfig
+	Patients   PatientsConfig
+	Geocoder   GeocoderConfig
+}
+
+// ClientsConfig — поддерживаемые версии мобильного приложения по платформам (ios, android)
+type ClientsConfig struct {
+	Platforms map[string]ClientVersionConfig
+}
+
+// ClientVersionConfig — версии ниже MinVersion получают 426, ниже RecommendedVersion — предложение обновиться.
+// Пустое значение отключает проверку
+type ClientVersionConfig struct {
+	MinVersion         string
+	RecommendedVersion string
+}
+
+type AuthConfig struct {
+	AccessTokenTTL     time.Duration // Время жизни access-токена
+	RefreshTokenTTL    time.Duration // Время жизни refresh-токена
+	EmergencyAccessTTL time.Duration // Время действия экстренного доступа к пациенту
+	Lockout            LockoutConfig
+	MFA                MFAConfig
+}
+
+// MFAConfig — второй фактор (TOTP) при входе
+type MFAConfig struct {
+	Issuer        string        // Название сервиса в приложении-аутентификаторе
+	RequiredRoles []string      // Роли, для которых второй фактор обязателен
+	ChallengeTTL  time.Duration // Время жизни MFA-токена между вводом пароля и кода
+}
+
+// LockoutConfig — защита входа от перебора паролей. После FreeAttempts неудачных попыток
+// вход блокируется на BackoffBase * 2^(n - FreeAttempts), но не дольше MaxLockout
+type LockoutConfig struct {
+	FreeAttempts   int           // Неудачных попыток на логин без задержки
+	IPFreeAttempts int           // Неудачных попыток с одного IP без задержки (за NAT много устройств)
+	BackoffBase    time.Duration // Первая задержка после исчерпания попыток
+	MaxLockout     time.Duration // Максимальная длительность блокировки
+	FailureWindow  time.Duration // Через сколько после последней ошибки счётчик сбрасывается
+}
+
+type WorkersConfig struct {
+	MedCardSyncInterval       time.Duration // Период отправки офлайн-правок медкарт в 1С
+	PasswordHashWorkers       int           // Сколько паролей хэшируется параллельно при синхронизации пользователей
+	PatientDuplicatesInterval time.Duration // Период поиска дублей пациентов и отправки объединений в 1С
+
+	// Сколько медкарт одного пакетного запроса загружается из 1С параллельно
+	MedCardFetchWorkers int
+
+	// Период удаления просмотров пациентов старше срока хранения
+	RecentPatientsPruneInterval time.Duration
+}
+
+// PatientsConfig — списки быстрого доступа к пациентам
+type PatientsConfig struct {
+	RecentRetention time.Duration // Сколько хранятся просмотры медкарт для списка недавних пациентов
+	RecentMax       int           // Максимум пациентов в списке недавних
+	// Ключ подписи QR-кодов пациентов. Смена ключа делает недействительными напечатанные браслеты
+	QRSecret string
+}
+
+// GeocoderConfig — разбор адресов и поиск координат. Без справочника адреса только разбираются на части
+type GeocoderConfig struct {
+	GazetteerPath string // JSON-справочник адресов с координатами для офлайн-геокодера
 }
 
 type MinIOConfig struct {
@@ -147,8 +220,54 @@ func LoadConfig() (*Config, error) {
 			BucketName: getEnv("MINIO_BUCKET_NAME", "default-bucket"),
 			UseSSL:     getEnvAsBool("MINIO_USE_SSL", false),
 		},
+		Auth: AuthConfig{
+			AccessTokenTTL:     getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:    getEnvAsDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			EmergencyAccessTTL: getEnvAsDuration("EMERGENCY_ACCESS_TTL", time.Hour),
+			Lockout: LockoutConfig{
+				FreeAttempts:   getEnvAsInt("LOGIN_FREE_ATTEMPTS", 5),
+				IPFreeAttempts: getEnvAsInt("LOGIN_IP_FREE_ATTEMPTS", 50),
+				BackoffBase:    getEnvAsDuration("LOGIN_BACKOFF_BASE", time.Second),
+				MaxLockout:     getEnvAsDuration("LOGIN_MAX_LOCKOUT", 15*time.Minute),
+				FailureWindow:  getEnvAsDuration("LOGIN_FAILURE_WINDOW", time.Hour),
+			},
+			MFA: MFAConfig{
+				Issuer:        getEnv("MFA_ISSUER", "MobileApp"),
+				RequiredRoles: getEnvAsList("MFA_REQUIRED_ROLES", []string{"admin"}),
+				ChallengeTTL:  getEnvAsDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+			},
+		},
+		Workers: WorkersConfig{
+			MedCardSyncInterval:       getEnvAsDuration("MEDCARD_SYNC_INTERVAL", 30*time.Second),
+			PasswordHashWorkers:       getEnvAsInt("PASSWORD_HASH_WORKERS", 4),
+			PatientDuplicatesInterval: getEnvAsDuration("PATIENT_DUPLICATES_INTERVAL", time.Hour),
+
+			MedCardFetchWorkers: getEnvAsInt("MEDCARD_FETCH_WORKERS", 8),
+
+			RecentPatientsPruneInterval: getEnvAsDuration("RECENT_PATIENTS_PRUNE_INTERVAL", time.Hour),
+		},
+		Patients: PatientsConfig{
+			RecentRetention: getEnvAsDuration("RECENT_PATIENTS_RETENTION", 30*24*time.Hour),
+			RecentMax:       getEnvAsInt("RECENT_PATIENTS_MAX", 50),
+		},
+		Geocoder: GeocoderConfig{
+			GazetteerPath: getEnv("GAZETTEER_PATH", ""),
+		},
+		Clients: ClientsConfig{
+			Platforms: map[string]ClientVersionConfig{
+				"ios": {
+					MinVersion:         getEnv("APP_MIN_VERSION_IOS", ""),
+					RecommendedVersion: getEnv("APP_RECOMMENDED_VERSION_IOS", ""),
+				},
+				"android": {
+					MinVersion:         getEnv("APP_MIN_VERSION_ANDROID", ""),
+					RecommendedVersion: getEnv("APP_RECOMMENDED_VERSION_ANDROID", ""),
+				},
+			},
+		},
 		Server: ServerConfig{ // Явно инициализируем Server
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:           getEnv("SERVER_PORT", "8080"),
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES", nil),
 			AllowedOrigins: []string{
 				"http://localhost:3000",
 				"http://localhost:5173",
@@ -160,10 +279,47 @@ func LoadConfig() (*Config, error) {
 		},
 	}
 
+	for platform, versions := range cfg.Clients.Platforms {
+		for _, v := range []string{versions.MinVersion, versions.RecommendedVersion} {
+			if v == "" {
+				continue
+			}
+			if _, err := appversion.Parse(v); err != nil {
+				return nil, fmt.Errorf("invalid app version for %s: %w", platform, err)
+			}
+		}
+	}
+
+	// Нулевой или отрицательный период воркера роняет time.NewTicker при запуске
+	for name, interval := range map[string]time.Duration{
+		"MEDCARD_SYNC_INTERVAL":          cfg.Workers.MedCardSyncInterval,
+		"PATIENT_DUPLICATES_INTERVAL":    cfg.Workers.PatientDuplicatesInterval,
+		"RECENT_PATIENTS_PRUNE_INTERVAL": cfg.Workers.RecentPatientsPruneInterval,
+	} {
+		if interval <= 0 {
+			return nil, fmt.Errorf("%s must be positive", name)
+		}
+	}
+
+	if cfg.Patients.RecentRetention <= 0 {
+		return nil, fmt.Errorf("RECENT_PATIENTS_RETENTION must be positive")
+	}
+	if cfg.Patients.RecentMax <= 0 {
+		return nil, fmt.Errorf("RECENT_PATIENTS_MAX must be positive")
+	}
+
 	cfg.JWTSecret = getEnv("JWT_SECRET", "")
 	if cfg.JWTSecret == "" {
 		return nil, fmt.Errorf("JWT_SECRET is required")
 	}
+	// Ключ QR-кодов отдельный: утечка или смена JWT_SECRET не должна затрагивать напечатанные браслеты
+	cfg.Patients.QRSecret = getEnv("PATIENT_QR_SECRET", "")
+	if cfg.Patients.QRSecret == "" {
+		return nil, fmt.Errorf("PATIENT_QR_SECRET is required")
+	}
+	if cfg.Patients.QRSecret == cfg.JWTSecret {
+		return nil, fmt.Errorf("PATIENT_QR_SECRET must differ from JWT_SECRET")
+	}
 
 	return cfg, nil
 }
@@ -185,6 +341,13 @@ func getEnvAsInt(name string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(getEnv(key, "")); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	value := os.Getenv(key)
 	if value == "" {
@@ -193,3 +356,19 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	val, _ := strconv.ParseBool(value)
 	return val
 }
+
+// getEnvAsList разбирает список через запятую. Пустое значение (VAR=) даёт пустой список
+func getEnvAsList(key string, defaultValue []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,130 @@
+package entities
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+)
+
+// Address — адрес, разобранный на части, с координатами для карты и маршрута.
+// Строка адреса для 1С хранится отдельно, структура её дополняет
+type Address struct {
+	Region       string   `gorm:"type:text" json:"region,omitempty" example:"Московская обл."`
+	City         string   `gorm:"type:text" json:"city,omitempty" example:"г. Москва"`
+	Street       string   `gorm:"type:text" json:"street,omitempty" example:"ул. Ленина"`
+	House        string   `gorm:"type:varchar(50)" json:"house,omitempty" example:"5 к2"` // С корпусом и строением
+	Flat         string   `gorm:"type:varchar(20)" json:"flat,omitempty" example:"12"`
+	Entrance     string   `gorm:"type:varchar(20)" json:"entrance,omitempty" example:"2"`
+	Floor        string   `gorm:"type:varchar(10)" json:"floor,omitempty" example:"3"`
+	IntercomCode string   `gorm:"type:varchar(20)" json:"intercom_code,omitempty" example:"12К"`
+	Lat          *float64 `json:"lat,omitempty" example:"55.7558"`
+	Lon          *float64 `json:"lon,omitempty" example:"37.6173"`
+	// Точность координат: house, street или city. Пусто, если координат нет
+	Precision string `gorm:"type:varchar(10)" json:"precision,omitempty" example:"house"`
+}
+
+// Точность координат адреса
+const (
+	AddressPrecisionHouse  = "house"
+	AddressPrecisionStreet = "street"
+	AddressPrecisionCity   = "city"
+)
+
+// IsZero — в адресе нет ни одной части и координат
+func (a *Address) IsZero() bool {
+	return a == nil || *a == Address{}
+}
+
+// SameAddress сравнивает адреса по значению; nil и пустой адрес равны
+func SameAddress(a, b *Address) bool {
+	if a.IsZero() || b.IsZero() {
+		return a.IsZero() == b.IsZero()
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// HasCoordinates — у адреса есть координаты
+func (a *Address) HasCoordinates() bool {
+	return a != nil && a.Lat != nil && a.Lon != nil
+}
+
+// Normalize убирает лишние пробелы и проверяет адрес: координаты задаются парой и в допустимых пределах,
+// этаж — целое число. Ошибки возвращаются с путём поля внутри address_details
+func (a *Address) Normalize() error {
+	for _, part := range []*string{&a.Region, &a.City, &a.Street, &a.House, &a.Flat, &a.Entrance, &a.Floor, &a.IntercomCode} {
+		*part = strings.Join(strings.Fields(*part), " ")
+	}
+
+	if (a.Lat == nil) != (a.Lon == nil) {
+		return &validation.Error{Field: "lat", Message: "lat and lon must be set together"}
+	}
+	if a.Lat != nil && (*a.Lat < -90 || *a.Lat > 90) {
+		return &validation.Error{Field: "lat", Message: "must be between -90 and 90"}
+	}
+	if a.Lon != nil && (*a.Lon < -180 || *a.Lon > 180) {
+		return &validation.Error{Field: "lon", Message: "must be between -180 and 180"}
+	}
+	if a.Floor != "" {
+		if _, err := strconv.Atoi(a.Floor); err != nil {
+			return &validation.Error{Field: "floor", Message: "must be an integer"}
+		}
+	}
+	switch a.Precision {
+	case "", AddressPrecisionHouse, AddressPrecisionStreet, AddressPrecisionCity:
+	default:
+		return &validation.Error{Field: "precision", Message: "must be house, street or city"}
+	}
+	if !a.HasCoordinates() {
+		a.Precision = ""
+	}
+	return nil
+}
+
+// DropPart очищает часть адреса, на которую указывает ошибка Normalize, и возвращает её прежнее значение.
+// Координаты очищаются парой вместе с точностью. false — поле не относится к проверяемым частям
+func (a *Address) DropPart(field string) (string, bool) {
+	var raw string
+	switch field {
+	case "lat", "lon":
+		raw = formatCoordinate(a.Lat) + "," + formatCoordinate(a.Lon)
+		a.Lat, a.Lon, a.Precision = nil, nil, ""
+	case "floor":
+		raw, a.Floor = a.Floor, ""
+	case "precision":
+		raw, a.Precision = a.Precision, ""
+	default:
+		return "", false
+	}
+	return raw, true
+}
+
+func formatCoordinate(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+// Format собирает строку адреса для 1С: "Московская обл., г. Москва, ул. Ленина, д. 5, кв. 12, под. 2, эт. 3, домофон 12К"
+func (a *Address) Format() string {
+	var parts []string
+	add := func(prefix, value string) {
+		if value != "" {
+			parts = append(parts, prefix+value)
+		}
+	}
+	// У городов федерального значения регион совпадает с городом
+	if a.Region != a.City {
+		add("", a.Region)
+	}
+	add("", a.City)
+	add("", a.Street)
+	add("д. ", a.House)
+	add("кв. ", a.Flat)
+	add("под. ", a.Entrance)
+	add("эт. ", a.Floor)
+	add("домофон ", a.IntercomCode)
+	return strings.Join(parts, ", ")
+}
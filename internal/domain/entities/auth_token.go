@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// RefreshToken — refresh-токен устройства. В БД хранится только SHA-256 хэш токена.
+// Все токены, полученные ротацией из одного входа, образуют семейство (FamilyID)
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`
+	FamilyID  string     `gorm:"type:varchar(36);not null;index"`
+	DeviceID  string     `gorm:"type:varchar(100);not null;default:''"`
+	TokenHash string     `gorm:"type:char(64);not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // Токен уже обменян на новый; повторное предъявление означает кражу
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// RevokedAccessToken — отозванный access-токен (по jti). Хранится, пока токен не истечёт
+type RevokedAccessToken struct {
+	JTI       string    `gorm:"primaryKey;type:varchar(36)"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
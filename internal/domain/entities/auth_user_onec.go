@@ -1,7 +1,22 @@
 package entities
 
+import "time"
+
 type AuthUser struct {
 	ID       uint   `gorm:"primaryKey"`
+	OneCID   string `gorm:"column:one_c_id;type:varchar(100);uniqueIndex;not null"` // Стабильный ID пользователя в 1С
 	Login    string `gorm:"uniqueIndex;not null"`
 	Password string `gorm:"not null"`
+	Role     Role   `gorm:"type:varchar(20);not null;default:'doctor'"`
+	// Номер сертификата врача — по нему врач указан лечащим (Doctor.PolicyOrCertNumber) в медкартах
+	DoctorCertNumber string `gorm:"type:varchar(100);index"`
+	// Пользователи не удаляются, а отключаются, чтобы сохранились ссылки из журналов доступа
+	DisabledAt *time.Time
+	UpdatedAt  time.Time
+
+	Profile *DoctorProfile `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (u *AuthUser) Disabled() bool {
+	return u.DisabledAt != nil
 }
@@ -0,0 +1,30 @@
+package entities
+
+import "time"
+
+// Device — устройство, с которого пользователь входит в приложение. Регистрируется при входе
+type Device struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_devices_user_device" json:"user_id"`
+	DeviceID   string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_devices_user_device" json:"device_id"` // ID, который генерирует приложение
+	Platform   string    `gorm:"type:varchar(20)" json:"platform"`                                                // ios, android
+	AppVersion string    `gorm:"type:varchar(50)" json:"app_version"`
+	PushToken  string    `gorm:"type:text" json:"-"`
+	LastIP     string    `gorm:"type:varchar(64)" json:"last_ip"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"-"`
+}
+
+// Session — сеанс входа на устройстве. ID совпадает с семейством refresh-токенов
+// и claim "sid" access-токенов; отзыв сеанса делает недействительными все его токены
+type Session struct {
+	ID         string     `gorm:"primaryKey;type:varchar(36)"`
+	UserID     uint       `gorm:"not null;index"`
+	DeviceID   string     `gorm:"type:varchar(100);not null"`
+	IP         string     `gorm:"type:varchar(64)"`
+	ExpiresAt  time.Time  `gorm:"not null"` // Истечение последнего refresh-токена сеанса
+	LastUsedAt time.Time  `gorm:"not null"`
+	RevokedAt  *time.Time `gorm:"index"`
+	CreatedAt  time.Time
+}
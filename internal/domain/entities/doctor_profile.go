@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// DoctorProfile — данные сотрудника из 1С для отображения в приложении.
+// Не путать с Doctor — лечащим врачом, который хранится внутри медкарты
+type DoctorProfile struct {
+	UserID         uint      `gorm:"primaryKey" json:"user_id"`
+	FullName       string    `gorm:"type:text" json:"full_name"`
+	Specialization string    `gorm:"type:varchar(255)" json:"specialization"`
+	Clinic         string    `gorm:"type:text" json:"clinic"`
+	Crew           string    `gorm:"type:varchar(100)" json:"crew"` // Бригада СМП, в которой работает сотрудник
+	UpdatedAt      time.Time `json:"-"`
+}
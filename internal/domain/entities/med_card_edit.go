@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MedCardEditStatus — статус синхронизации офлайн-правки карты с 1С
+type MedCardEditStatus string
+
+const (
+	MedCardEditPending  MedCardEditStatus = "pending"  // Ожидает отправки в 1С
+	MedCardEditApplied  MedCardEditStatus = "applied"  // Применена в 1С и в БД
+	MedCardEditConflict MedCardEditStatus = "conflict" // Карта изменилась, нужно решение врача
+	MedCardEditRejected MedCardEditStatus = "rejected" // Правка некорректна и не будет применена
+)
+
+// MedCardEdit — правка медкарты, сделанная на устройстве (в том числе без связи)
+// и поставленная в очередь на отправку в 1С
+type MedCardEdit struct {
+	ID           uint   `gorm:"primaryKey" json:"-"`
+	ClientEditID string `gorm:"type:varchar(64);not null;uniqueIndex:idx_med_card_edits_user_client,priority:2" json:"client_edit_id"` // ID, сгенерированный клиентом, уникален в пределах пользователя
+	UserID       uint   `gorm:"not null;uniqueIndex:idx_med_card_edits_user_client,priority:1" json:"user_id"`
+	PatientID    string `gorm:"not null;index" json:"patient_id"`
+
+	BaseVersion     uint              `gorm:"not null" json:"base_version"`                          // Версия карты, на которой сделана правка
+	Patch           json.RawMessage   `gorm:"type:jsonb;not null" json:"patch" swaggertype:"object"` // JSON Merge Patch
+	ClientTimestamp time.Time         `gorm:"not null;index" json:"client_timestamp"`                // Время правки по часам устройства, только для показа: правки отправляются в порядке поступления
+	Status          MedCardEditStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+
+	Attempts       int             `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time       `gorm:"not null;index" json:"next_attempt_at"` // Раньше этого времени правка не отправляется
+	LastError      string          `gorm:"type:text" json:"last_error,omitempty"`
+	ConflictCard   json.RawMessage `gorm:"type:jsonb" json:"conflict_card,omitempty" swaggertype:"object"` // Актуальная карта на момент конфликта
+	AppliedVersion uint            `json:"applied_version,omitempty"`
+	AppliedAt      *time.Time      `json:"applied_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
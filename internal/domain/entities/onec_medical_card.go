@@ -1,21 +1,29 @@
 // internal/domain/entities/onec_medical_card.go
 package entities
 
+import (
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+	"gorm.io/gorm"
+)
+
 // OneCMedicalCard — мед карта пациента,  используется и для БД, и для JSON
 type OneCMedicalCard struct {
 	ID        uint   `gorm:"primaryKey" json:"-"`
 	PatientID string `gorm:"not null;uniqueIndex" json:"patient_id"`
+	Version   uint   `gorm:"not null;default:1" json:"version"` // Версия карты для оптимистичной блокировки (ETag)
 
 	// Основные поля
-	DisplayName     string `gorm:"type:text" json:"display_name"`
-	Age             string `gorm:"type:varchar(50)" json:"age"`
-	BirthDate       string `gorm:"type:varchar(20)" json:"birth_date"`
-	MobilePhone     string `gorm:"type:varchar(20)" json:"mobile_phone"`
-	AdditionalPhone string `gorm:"type:varchar(20)" json:"additional_phone"`
-	Address         string `gorm:"type:text" json:"address"`
-	Email           string `gorm:"type:varchar(100)" json:"email"`
-	Workplace       string `gorm:"type:text" json:"workplace"`
-	Snils           string `gorm:"type:varchar(20);index" json:"snils"`
+	DisplayName     string      `gorm:"type:text" json:"display_name"`
+	Age             *PatientAge `gorm:"-" json:"age,omitempty"` // Вычисляется из даты рождения, см. FillAge
+	BirthDate       string      `gorm:"type:varchar(20)" json:"birth_date"`
+	MobilePhone     string      `gorm:"type:varchar(20)" json:"mobile_phone"`
+	AdditionalPhone string      `gorm:"type:varchar(20)" json:"additional_phone"`
+	Address         string      `gorm:"type:text" json:"address"`
+	Email           string      `gorm:"type:varchar(100)" json:"email"`
+	Workplace       string      `gorm:"type:text" json:"workplace"`
+	Snils           string      `gorm:"type:varchar(20);index" json:"snils"`
 
 	// Вложенные структуры
 	LegalRepresentative ClientRef   `gorm:"embedded;embeddedPrefix:legal_rep_" json:"legal_representative,omitempty"`
@@ -23,6 +31,44 @@ type OneCMedicalCard struct {
 	AttendingDoctor     Doctor      `gorm:"embedded;embeddedPrefix:doctor_" json:"attending_doctor"`
 	Policy              Policy      `gorm:"embedded;embeddedPrefix:policy_" json:"policy"`
 	Certificate         Certificate `gorm:"embedded;embeddedPrefix:cert_" json:"certificate"`
+
+	// Адрес по частям с координатами. В 1С не передаётся: там остаётся строка Address
+	AddressDetails *Address `gorm:"embedded;embeddedPrefix:addr_" json:"address_details,omitempty"`
+
+	// Нормализованные колонки для поиска, заполняются FillSearchColumns
+	SnilsSearch       string     `gorm:"type:varchar(20);index" json:"-"`
+	PolicySearch      string     `gorm:"type:varchar(50);index" json:"-"`
+	MobilePhoneSearch string     `gorm:"type:varchar(20);index" json:"-"`
+	BirthDateParsed   *time.Time `gorm:"type:date;index" json:"-"`
+
+	// Номер последнего изменения для ленты синхронизации
+	ChangeSeq int64 `gorm:"not null;default:nextval('sync_change_seq');index" json:"-"`
+}
+
+// FillSearchColumns пересчитывает нормализованные колонки для поиска.
+// Значения, не прошедшие проверку, сохраняются в упрощённом виде, чтобы по ним всё равно можно было искать
+func (c *OneCMedicalCard) FillSearchColumns() {
+	c.SnilsSearch = validation.DigitsOnly(c.Snils)
+	c.PolicySearch = validation.SearchKey(c.Policy.Number)
+
+	if phone, err := validation.NormalizePhone(c.MobilePhone); err == nil {
+		c.MobilePhoneSearch = phone
+	} else {
+		c.MobilePhoneSearch = validation.DigitsOnly(c.MobilePhone)
+	}
+
+	c.BirthDateParsed = nil
+	if birthDate, err := validation.ParseDate(c.BirthDate); err == nil {
+		c.BirthDateParsed = &birthDate
+	}
+}
+
+// AfterFind убирает пустой адрес: gorm создаёт вложенную структуру и для карт без частей адреса
+func (c *OneCMedicalCard) AfterFind(*gorm.DB) error {
+	if c.AddressDetails.IsZero() {
+		c.AddressDetails = nil
+	}
+	return nil
 }
 
 type ClientRef struct {
@@ -52,3 +98,17 @@ type Certificate struct {
 	Number string `gorm:"type:varchar(50)" json:"number"`
 	Date   string `gorm:"type:varchar(20)" json:"date"`
 }
+
+// FillAge вычисляет возраст пациента на дату now
+func (c *OneCMedicalCard) FillAge(now time.Time) {
+	c.Age = nil
+	birthDate := c.BirthDateParsed
+	if birthDate == nil {
+		if parsed, err := validation.ParseDate(c.BirthDate); err == nil {
+			birthDate = &parsed
+		}
+	}
+	if birthDate != nil {
+		c.Age = NewPatientAge(*birthDate, now)
+	}
+}
@@ -1,5 +1,12 @@
 package entities
 
+import (
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/namesearch"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+)
+
 type PatientListUpdate struct {
 	Patients []OneCPatientListItem `json:"patients"`
 }
@@ -12,5 +19,35 @@ type OneCPatientListItem struct {
 	Gender    bool   // true — мужской
 	BirthDate string // в формате "YYYY-MM-DD"
 
+	BirthDateParsed *time.Time  `gorm:"type:date;index" json:"-"` // Заполняется FillSearchColumns
+	FullNameSearch  string      `gorm:"type:text" json:"-"`       // ФИО после namesearch.Normalize, по нему триграммный индекс
+	Age             *PatientAge `gorm:"-" json:"age,omitempty"`   // Вычисляется из даты рождения, см. FillAge
+
+	// Номер последнего изменения для ленты синхронизации
+	ChangeSeq int64 `gorm:"not null;default:nextval('sync_change_seq');index" json:"-"`
+
 	MedicalCard *OneCMedicalCard `gorm:"foreignKey:PatientID;references:PatientID" json:"medical_card,omitempty"`
 }
+
+// FillSearchColumns пересчитывает нормализованные колонки для поиска
+func (p *OneCPatientListItem) FillSearchColumns() {
+	p.FullNameSearch = namesearch.Normalize(p.FullName)
+	p.BirthDateParsed = nil
+	if birthDate, err := validation.ParseDate(p.BirthDate); err == nil {
+		p.BirthDateParsed = &birthDate
+	}
+}
+
+// FillAge вычисляет возраст пациента на дату now
+func (p *OneCPatientListItem) FillAge(now time.Time) {
+	p.Age = nil
+	birthDate := p.BirthDateParsed
+	if birthDate == nil {
+		if parsed, err := validation.ParseDate(p.BirthDate); err == nil {
+			birthDate = &parsed
+		}
+	}
+	if birthDate != nil {
+		p.Age = NewPatientAge(*birthDate, now)
+	}
+}
@@ -10,4 +10,7 @@ type OneCReception struct {
 	Data      []byte `gorm:"type:jsonb"`  // Вся структура от 1С (включая пациента, услуги и т.д.)
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	// Номер последнего изменения для ленты синхронизации
+	ChangeSeq int64 `gorm:"not null;default:nextval('sync_change_seq');index"`
 }
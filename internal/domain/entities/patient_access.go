@@ -0,0 +1,53 @@
+package entities
+
+import "time"
+
+// PatientAccessAction — действие с данными пациента
+type PatientAccessAction string
+
+const (
+	PatientAccessRead  PatientAccessAction = "read"
+	PatientAccessWrite PatientAccessAction = "write"
+)
+
+// PatientAccessReason — основание, по которому доступ разрешён или запрещён
+type PatientAccessReason string
+
+const (
+	AccessReasonClinicWide      PatientAccessReason = "clinic_wide"      // Роль с доступом ко всем пациентам
+	AccessReasonAttendingDoctor PatientAccessReason = "attending_doctor" // Лечащий врач по медкарте
+	AccessReasonAssignedCall    PatientAccessReason = "assigned_call"    // Бригада активного вызова к пациенту
+	AccessReasonEmergency       PatientAccessReason = "emergency_grant"  // Экстренный доступ с обоснованием
+	AccessReasonDenied          PatientAccessReason = "denied"
+)
+
+// PatientAccessLog — журнал обращений к данным пациентов (и разрешённых, и запрещённых)
+type PatientAccessLog struct {
+	ID        uint                `gorm:"primaryKey" json:"id"`
+	UserID    uint                `gorm:"not null;index" json:"user_id"`
+	PatientID string              `gorm:"type:varchar(100);not null;index" json:"patient_id"`
+	Action    PatientAccessAction `gorm:"type:varchar(10);not null" json:"action"`
+	Granted   bool                `gorm:"not null" json:"granted"`
+	Reason    PatientAccessReason `gorm:"type:varchar(30);not null" json:"reason"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// EmergencyAccessGrant — экстренный (break-the-glass) доступ пользователя к одному пациенту
+// на ограниченное время. Выдаётся по запросу с обязательным обоснованием и проверяется администратором
+type EmergencyAccessGrant struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	PatientID string    `gorm:"type:varchar(100);not null;index" json:"patient_id"`
+	Reason    string    `gorm:"type:text;not null" json:"reason"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// CallAssignment — член бригады, назначенный на активный вызов к пациенту.
+// Строки вызова заменяются при каждом обновлении из 1С и удаляются после его завершения
+type CallAssignment struct {
+	CallID    string `gorm:"primaryKey;type:varchar(100)"`
+	Login     string `gorm:"primaryKey;type:varchar(100)"` // Логин члена бригады (auth_users.login)
+	PatientID string `gorm:"primaryKey;type:varchar(100);index"`
+	CreatedAt time.Time
+}
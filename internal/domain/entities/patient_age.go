@@ -0,0 +1,98 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PatientAge — возраст пациента, вычисляемый из даты рождения в момент чтения.
+// Для детей до 3 лет важны месяцы и дни (дозировки в педиатрии), поэтому они всегда заполнены
+// @Description Возраст пациента на текущую дату
+type PatientAge struct {
+	Years   int    `json:"years" example:"1"`
+	Months  int    `json:"months" example:"3"`               // Полных месяцев после последнего дня рождения
+	Days    int    `json:"days" example:"12"`                // Дней после последнего полного месяца
+	Display string `json:"display" example:"1 год 3 месяца"` // Строка для отображения
+}
+
+// NewPatientAge вычисляет возраст на дату now. Для даты рождения в будущем возвращает nil
+func NewPatientAge(birthDate, now time.Time) *PatientAge {
+	birth := time.Date(birthDate.Year(), birthDate.Month(), birthDate.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if birth.After(today) {
+		return nil
+	}
+
+	years := today.Year() - birth.Year()
+	if addMonths(birth, years*12).After(today) {
+		years--
+	}
+
+	months := 0
+	for !addMonths(birth, years*12+months+1).After(today) {
+		months++
+	}
+	days := int(today.Sub(addMonths(birth, years*12+months)).Hours() / 24)
+
+	age := &PatientAge{Years: years, Months: months, Days: days}
+	age.Display = age.display()
+	return age
+}
+
+// addMonths сдвигает дату на months месяцев. Если в месяце нет такого числа, берётся его последний день
+// (ст. 192 ГК РФ): 31 января + 1 месяц — 28 февраля, а не 3 марта, как у time.AddDate
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	day := t.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// display формирует строку возраста: до месяца — в днях, до года — в месяцах и днях,
+// до трёх лет — в годах и месяцах, далее — в годах
+func (a *PatientAge) display() string {
+	switch {
+	case a.Years == 0 && a.Months == 0:
+		return pluralRu(a.Days, "день", "дня", "дней")
+	case a.Years == 0:
+		parts := []string{pluralRu(a.Months, "месяц", "месяца", "месяцев")}
+		if a.Days > 0 {
+			parts = append(parts, pluralRu(a.Days, "день", "дня", "дней"))
+		}
+		return strings.Join(parts, " ")
+	case a.Years < 3:
+		parts := []string{pluralRu(a.Years, "год", "года", "лет")}
+		if a.Months > 0 {
+			parts = append(parts, pluralRu(a.Months, "месяц", "месяца", "месяцев"))
+		}
+		return strings.Join(parts, " ")
+	default:
+		return pluralRu(a.Years, "год", "года", "лет")
+	}
+}
+
+// UnmarshalJSON пропускает строковый возраст, который присылают старые версии 1С:
+// возраст всегда пересчитывается из даты рождения
+func (a *PatientAge) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return nil
+	}
+	type plain PatientAge
+	return json.Unmarshal(data, (*plain)(a))
+}
+
+// pluralRu выбирает форму слова для числа: 1 год, 2 года, 5 лет
+func pluralRu(n int, one, few, many string) string {
+	form := many
+	switch mod10, mod100 := n%10, n%100; {
+	case mod10 == 1 && mod100 != 11:
+		form = one
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		form = few
+	}
+	return fmt.Sprintf("%d %s", n, form)
+}
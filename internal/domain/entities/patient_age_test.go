@@ -0,0 +1,97 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNewPatientAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		birth   time.Time
+		now     time.Time
+		years   int
+		months  int
+		days    int
+		display string
+	}{
+		{"day of birth", date(2024, 3, 10), date(2024, 3, 10), 0, 0, 0, "0 дней"},
+		{"one day", date(2024, 3, 10), date(2024, 3, 11), 0, 0, 1, "1 день"},
+		{"day before first month", date(2024, 3, 10), date(2024, 4, 9), 0, 0, 30, "30 дней"},
+		{"first month", date(2024, 3, 10), date(2024, 4, 10), 0, 1, 0, "1 месяц"},
+		{"months and days", date(2024, 3, 10), date(2024, 6, 12), 0, 3, 2, "3 месяца 2 дня"},
+		{"day before first year", date(2023, 3, 10), date(2024, 3, 9), 0, 11, 28, "11 месяцев 28 дней"},
+		{"first year", date(2023, 3, 10), date(2024, 3, 10), 1, 0, 0, "1 год"},
+		{"years and months", date(2022, 3, 10), date(2024, 5, 11), 2, 2, 1, "2 года 2 месяца"},
+		{"day before third year", date(2021, 3, 10), date(2024, 3, 9), 2, 11, 28, "2 года 11 месяцев"},
+		{"third year", date(2021, 3, 10), date(2024, 3, 10), 3, 0, 0, "3 года"},
+		{"adult", date(1980, 12, 31), date(2024, 6, 1), 43, 5, 1, "43 года"},
+		{"time of day ignored", date(2024, 3, 10).Add(23 * time.Hour), date(2024, 3, 11).Add(time.Hour), 0, 0, 1, "1 день"},
+
+		// Конец месяца: в коротком месяце день рождения приходится на его последний день
+		{"jan 31 on feb 28", date(2023, 1, 31), date(2023, 2, 28), 0, 1, 0, "1 месяц"},
+		{"jan 31 on feb 27", date(2023, 1, 31), date(2023, 2, 27), 0, 0, 27, "27 дней"},
+		{"jan 31 on mar 2", date(2023, 1, 31), date(2023, 3, 2), 0, 1, 2, "1 месяц 2 дня"},
+		{"jan 31 on mar 31", date(2023, 1, 31), date(2023, 3, 31), 0, 2, 0, "2 месяца"},
+		{"jan 31 on apr 30", date(2023, 1, 31), date(2023, 4, 30), 0, 3, 0, "3 месяца"},
+		{"jan 31 leap year", date(2024, 1, 31), date(2024, 2, 29), 0, 1, 0, "1 месяц"},
+		{"aug 31 on sep 30", date(2023, 8, 31), date(2023, 9, 30), 0, 1, 0, "1 месяц"},
+
+		// 29 февраля: в невисокосный год день рождения — 28 февраля
+		{"feb 29 on feb 28", date(2020, 2, 29), date(2021, 2, 28), 1, 0, 0, "1 год"},
+		{"feb 29 on feb 27", date(2020, 2, 29), date(2021, 2, 27), 0, 11, 29, "11 месяцев 29 дней"},
+		{"feb 29 on mar 1", date(2020, 2, 29), date(2021, 3, 1), 1, 0, 1, "1 год"},
+		{"feb 29 on leap feb 29", date(2020, 2, 29), date(2024, 2, 29), 4, 0, 0, "4 года"},
+		{"feb 29 on mar 29", date(2020, 2, 29), date(2020, 3, 29), 0, 1, 0, "1 месяц"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewPatientAge(tt.birth, tt.now)
+			if got == nil {
+				t.Fatalf("NewPatientAge(%s, %s) = nil", tt.birth, tt.now)
+			}
+			if got.Years != tt.years || got.Months != tt.months || got.Days != tt.days || got.Display != tt.display {
+				t.Errorf("NewPatientAge(%s, %s) = %d/%d/%d %q, want %d/%d/%d %q",
+					tt.birth.Format(time.DateOnly), tt.now.Format(time.DateOnly),
+					got.Years, got.Months, got.Days, got.Display,
+					tt.years, tt.months, tt.days, tt.display)
+			}
+		})
+	}
+}
+
+func TestNewPatientAgeFutureBirth(t *testing.T) {
+	if got := NewPatientAge(date(2024, 3, 11), date(2024, 3, 10)); got != nil {
+		t.Errorf("NewPatientAge for future birth = %+v, want nil", got)
+	}
+}
+
+func TestPluralRu(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0 лет"},
+		{1, "1 год"},
+		{2, "2 года"},
+		{4, "4 года"},
+		{5, "5 лет"},
+		{11, "11 лет"},
+		{12, "12 лет"},
+		{14, "14 лет"},
+		{21, "21 год"},
+		{22, "22 года"},
+		{111, "111 лет"},
+	}
+
+	for _, tt := range tests {
+		if got := pluralRu(tt.n, "год", "года", "лет"); got != tt.want {
+			t.Errorf("pluralRu(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package entities
+
+import "time"
+
+// PatientDuplicateStatus — состояние пары в очереди проверки дублей
+type PatientDuplicateStatus string
+
+const (
+	DuplicatePending   PatientDuplicateStatus = "pending"   // Ждёт решения администратора
+	DuplicateMerged    PatientDuplicateStatus = "merged"    // Записи объединены
+	DuplicateDismissed PatientDuplicateStatus = "dismissed" // Разные люди, повторно не предлагается
+)
+
+// PatientDuplicateCandidate — пара записей, похожих на одного человека.
+// PatientID всегда меньше OtherPatientID, чтобы пара хранилась один раз
+type PatientDuplicateCandidate struct {
+	ID             uint                   `gorm:"primaryKey" json:"id"`
+	PatientID      string                 `gorm:"type:varchar(100);not null;uniqueIndex:idx_duplicate_pair" json:"patient_id"`
+	OtherPatientID string                 `gorm:"type:varchar(100);not null;uniqueIndex:idx_duplicate_pair;index" json:"other_patient_id"`
+	Score          float64                `gorm:"not null" json:"score"`                     // От 0 до 1
+	Reasons        string                 `gorm:"type:varchar(100);not null" json:"reasons"` // Совпавшие признаки через запятую: snils, policy, name, birth_date
+	Status         PatientDuplicateStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	ReviewedBy     *uint                  `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time             `json:"reviewed_at,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+}
+
+// PatientMergeStatus — отправлено ли объединение в 1С
+type PatientMergeStatus string
+
+const (
+	PatientMergePendingSync PatientMergeStatus = "pending_sync"
+	PatientMergeSynced      PatientMergeStatus = "synced"
+)
+
+// PatientMerge — объединение двух записей пациента. Данные MergedID перенесены на SurvivorID;
+// 1С узнаёт об объединении из очереди, пока отправка не удастся
+type PatientMerge struct {
+	ID         uint               `gorm:"primaryKey" json:"id"`
+	SurvivorID string             `gorm:"type:varchar(100);not null;index" json:"survivor_id"`
+	MergedID   string             `gorm:"type:varchar(100);not null;index" json:"merged_id"`
+	MergedBy   uint               `gorm:"not null" json:"merged_by"`
+	Status     PatientMergeStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Attempts   int                `gorm:"not null;default:0" json:"attempts"`
+	LastError  string             `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ReportedAt *time.Time         `json:"reported_at,omitempty"`
+}
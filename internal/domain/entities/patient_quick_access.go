@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// PatientView — последний просмотр медкарты пациента пользователем. Строка на пару пользователь–пациент;
+// хранится, пока не истечёт срок хранения недавних пациентов
+type PatientView struct {
+	UserID    uint      `gorm:"primaryKey"`
+	PatientID string    `gorm:"primaryKey;type:varchar(100);index"`
+	ViewedAt  time.Time `gorm:"not null;index"`
+	ViewCount int       `gorm:"not null;default:1"`
+}
+
+// FavoritePatient — пациент, добавленный пользователем в избранное
+type FavoritePatient struct {
+	UserID    uint   `gorm:"primaryKey"`
+	PatientID string `gorm:"primaryKey;type:varchar(100);index"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,95 @@
+package entities
+
+// Role — роль пользователя. Приходит из 1С вместе со списком пользователей
+type Role string
+
+const (
+	RoleDoctor      Role = "doctor"
+	RoleParamedic   Role = "paramedic"
+	RoleDispatcher  Role = "dispatcher"
+	RoleAdmin       Role = "admin"
+	RoleIntegration Role = "integration" // Технический пользователь 1С для вебхуков
+)
+
+// Permission — право на группу маршрутов API. Кладётся в claims access-токена
+type Permission string
+
+const (
+	PermPatientsRead   Permission = "patients:read"
+	PermPatientsAll    Permission = "patients:all" // Доступ к любому пациенту клиники, а не только к своим
+	PermMedCardRead    Permission = "medcard:read"
+	PermMedCardWrite   Permission = "medcard:write"
+	PermEmergencyRead  Permission = "emergency:read"
+	PermEmergencyWrite Permission = "emergency:write"
+	PermOneCWebhook    Permission = "onec:webhook"
+	PermAuditRead      Permission = "audit:read"     // Отчёты для проверки доступа к пациентам
+	PermUsersManage    Permission = "users:manage"   // Разблокировка учётных записей
+	PermPatientsMerge  Permission = "patients:merge" // Проверка и объединение дублей пациентов
+)
+
+// Roles — все известные роли
+var Roles = []Role{RoleDoctor, RoleParamedic, RoleDispatcher, RoleAdmin, RoleIntegration}
+
+// Permissions — все известные права
+var Permissions = []Permission{
+	PermPatientsRead,
+	PermPatientsAll,
+	PermMedCardRead,
+	PermMedCardWrite,
+	PermEmergencyRead,
+	PermEmergencyWrite,
+	PermOneCWebhook,
+	PermAuditRead,
+	PermUsersManage,
+	PermPatientsMerge,
+}
+
+// PinnedRolePermissions — права, которые синхронизация из 1С не может отнять. Без onec:webhook 1С
+// не смогла бы прислать и исправленное соответствие ролей
+var PinnedRolePermissions = map[Role][]Permission{
+	RoleIntegration: {PermOneCWebhook},
+}
+
+// DefaultRolePermissions — соответствие ролей и прав до первой синхронизации с 1С
+var DefaultRolePermissions = map[Role][]Permission{
+	RoleDoctor:      {PermPatientsRead, PermMedCardRead, PermMedCardWrite, PermEmergencyRead, PermEmergencyWrite},
+	RoleParamedic:   {PermPatientsRead, PermMedCardRead, PermEmergencyRead, PermEmergencyWrite},
+	RoleDispatcher:  {PermPatientsRead, PermEmergencyRead},
+	RoleAdmin:       Permissions,
+	RoleIntegration: {PermOneCWebhook},
+}
+
+func (r Role) Valid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Permission) Valid() bool {
+	for _, perm := range Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermission — строка таблицы соответствия ролей и прав (синхронизируется из 1С)
+type RolePermission struct {
+	Role       Role       `gorm:"primaryKey;type:varchar(20)"`
+	Permission Permission `gorm:"primaryKey;type:varchar(50)"`
+}
+
+// DefaultRolePermissionRows разворачивает DefaultRolePermissions в строки таблицы
+func DefaultRolePermissionRows() []RolePermission {
+	var rows []RolePermission
+	for _, role := range Roles {
+		for _, perm := range DefaultRolePermissions[role] {
+			rows = append(rows, RolePermission{Role: role, Permission: perm})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,33 @@
+package entities
+
+import "time"
+
+// LoginAttemptCounter — счётчик неудачных входов по ключу ("login:<телефон>" или "ip:<адрес>").
+// Хранится в Postgres, чтобы блокировка действовала на всех репликах
+type LoginAttemptCounter struct {
+	Key           string     `gorm:"primaryKey;type:varchar(150)" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	LastFailureAt time.Time  `gorm:"not null" json:"last_failure_at"`
+}
+
+// SecurityEventType — тип события безопасности
+type SecurityEventType string
+
+const (
+	SecurityEventLoginFailed   SecurityEventType = "login_failed"
+	SecurityEventLoginLocked   SecurityEventType = "login_locked"  // Ключ заблокирован после очередной ошибки
+	SecurityEventLoginBlocked  SecurityEventType = "login_blocked" // Попытка входа во время блокировки
+	SecurityEventLoginUnlocked SecurityEventType = "login_unlocked"
+)
+
+// SecurityEvent — событие безопасности для проверки администратором
+type SecurityEvent struct {
+	ID        uint              `gorm:"primaryKey" json:"id"`
+	Type      SecurityEventType `gorm:"type:varchar(30);not null;index" json:"type"`
+	Login     string            `gorm:"type:varchar(100);index" json:"login"`
+	IP        string            `gorm:"type:varchar(64)" json:"ip"`
+	UserID    *uint             `json:"user_id,omitempty"` // Администратор для ручных действий
+	Details   string            `gorm:"type:text" json:"details"`
+	CreatedAt time.Time         `gorm:"index" json:"created_at"`
+}
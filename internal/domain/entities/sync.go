@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// SyncEntity — тип записи в ленте изменений для мобильных клиентов
+type SyncEntity string
+
+const (
+	SyncEntityPatient     SyncEntity = "patient"
+	SyncEntityMedicalCard SyncEntity = "medical_card"
+	SyncEntityCall        SyncEntity = "call"
+)
+
+// SyncTombstone — удалённая запись. Хранится, чтобы офлайн-клиенты узнали об удалении при следующей синхронизации.
+// ChangeSeq берётся из той же последовательности, что и у живых записей.
+// Запись с Login не удалена, а ушла из области видимости этого пользователя (вызов завершён, сменился лечащий врач)
+type SyncTombstone struct {
+	ID        uint       `gorm:"primaryKey"`
+	ChangeSeq int64      `gorm:"not null;default:nextval('sync_change_seq');index"`
+	Entity    SyncEntity `gorm:"type:varchar(20);not null"`
+	EntityID  string     `gorm:"type:varchar(100);not null"` // patient_id или call_id
+	Login     string     `gorm:"type:varchar(100);not null;default:''"`
+	DeletedAt time.Time  `gorm:"not null"`
+}
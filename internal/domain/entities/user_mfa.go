@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+// UserMFA — второй фактор (TOTP) пользователя. Пока ConfirmedAt пуст, подключение не завершено
+// и при входе код не требуется (если роль не требует MFA)
+type UserMFA struct {
+	UserID       uint   `gorm:"primaryKey"`
+	Secret       string `gorm:"type:varchar(64);not null"` // base32
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 `gorm:"not null;default:0"` // Последний принятый шаг TOTP — защита от повторного использования кода
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (m *UserMFA) Confirmed() bool {
+	return m != nil && m.ConfirmedAt != nil
+}
+
+// MFARecoveryCode — одноразовый код восстановления на случай потери телефона. Хранится хэш
+type MFARecoveryCode struct {
+	ID       uint   `gorm:"primaryKey"`
+	UserID   uint   `gorm:"not null;index"`
+	CodeHash string `gorm:"type:char(64);not null;uniqueIndex"`
+	UsedAt   *time.Time
+}
@@ -1,15 +1,85 @@
 package models
 
+import (
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+)
+
 // DoctorLoginRequest - запрос на авторизацию врача
 // @Description Запрос для входа врача в систему
 type DoctorLoginRequest struct {
-	Phone    string `json:"phone" binding:"required" example:"+79622840765"`   // Логин (телефон)
-	Password string `json:"password" binding:"required" example:"password123"` // Пароль
+	Phone      string `json:"phone" binding:"required" example:"+79622840765"`   // Логин (телефон)
+	Password   string `json:"password" binding:"required" example:"password123"` // Пароль
+	DeviceID   string `json:"device_id" binding:"required" example:"a1b2c3d4"`   // Идентификатор устройства, токены привязываются к нему
+	Platform   string `json:"platform" example:"android"`                        // ios, android
+	AppVersion string `json:"app_version" example:"1.2.3"`                       // Версия приложения
+	PushToken  string `json:"push_token" example:"fcm:APA91b..."`                // Токен push-уведомлений
+	ClientIP   string `json:"-"`                                                 // IP клиента, заполняется обработчиком
 }
 
 // DoctorAuthResponse - ответ на авторизацию врача
 // @Description Ответ с данными авторизованного врача
 type DoctorAuthResponse struct {
-	ID    uint   `json:"id" example:"1"`                // ID врача
-	Token string `json:"token" example:"eyJhbGciOi..."` // JWT токен
+	ID               uint                  `json:"id" example:"1"`                        // ID врача
+	Token            string                `json:"token" example:"eyJhbGciOi..."`         // Access-токен (JWT)
+	ExpiresAt        int64                 `json:"expires_at" example:"1715783400"`       // Время истечения access-токена (unix)
+	Role             entities.Role         `json:"role" example:"doctor"`                 // Роль пользователя
+	Permissions      []entities.Permission `json:"permissions" example:"medcard:read"`    // Права, зашитые в access-токен
+	RefreshToken     string                `json:"refresh_token" example:"Zk9yZXZlci..."` // Refresh-токен, меняется при каждом обновлении
+	RefreshExpiresAt int64                 `json:"refresh_expires_at" example:"1718375400"`
+	Doctor           *DoctorInfoResponse   `json:"doctor,omitempty"` // Профиль пользователя, только при входе
+
+	// Второй фактор: если MFARequired, токенов нет — нужно вызвать /auth/mfa/verify с MFAToken
+	MFARequired   bool           `json:"mfa_required,omitempty" example:"false"`
+	MFAToken      string         `json:"mfa_token,omitempty" example:"eyJhbGciOi..."`
+	MFAEnrollment *MFAEnrollment `json:"mfa_enrollment,omitempty"` // Роль требует MFA, а он ещё не подключён
+	RecoveryCodes []string       `json:"recovery_codes,omitempty"` // Показываются один раз после подключения MFA
+}
+
+// MFAEnrollment - данные для подключения приложения-аутентификатора
+type MFAEnrollment struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`                      // Секрет для ручного ввода
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/MobileApp:%2B79622840761?..."` // Содержимое QR-кода
+}
+
+// MFAVerifyRequest - второй шаг входа
+// @Description Передаётся код из приложения (code) или одноразовый код восстановления (recovery_code)
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required" example:"eyJhbGciOi..."`
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recovery_code" example:"ABCDE-FGHIJ"`
+	ClientIP     string `json:"-"`
+}
+
+// MFACodeRequest - подтверждение подключения или отключение MFA кодом из приложения
+type MFACodeRequest struct {
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recovery_code" example:"ABCDE-FGHIJ"` // Только для отключения
+}
+
+// MFARecoveryCodesResponse - коды восстановления, выдаются один раз
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"ABCDE-FGHIJ"`
+}
+
+// RefreshTokenRequest - запрос на обновление пары токенов
+// @Description Refresh-токен одноразовый: повторное использование отзывает все токены этого входа
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"Zk9yZXZlci..."`
+	DeviceID     string `json:"device_id" binding:"required" example:"a1b2c3d4"` // Должен совпадать с устройством входа
+	ClientIP     string `json:"-"`
+}
+
+// TokenSession - данные access-токена, по которым выполняется выход
+type TokenSession struct {
+	JTI       string    // ID access-токена
+	FamilyID  string    // ID входа (семейства refresh-токенов), claim "sid"
+	ExpiresAt time.Time // Время истечения access-токена
+}
+
+// AccessSubject - пользователь, запрашивающий данные пациента
+type AccessSubject struct {
+	UserID     uint
+	ClinicWide bool // В токене есть право patients:all
 }
@@ -1,5 +1,7 @@
 package models
 
+import "github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+
 // DoctorResponse - полная информация о враче
 // @Description Содержит все данные о враче включая идентификационные и контактные данные
 type DoctorResponse struct {
@@ -10,10 +12,16 @@ type DoctorResponse struct {
 	SpecializationID uint   `json:"specialization_id" example:"1"`            // Медицинская специализация
 }
 
+// DoctorInfoResponse - текущий пользователь и его профиль из 1С
+// @Description Возвращается в GET /me и в ответе на вход
 type DoctorInfoResponse struct {
-	DoctorID       uint   `json:"doctor_id" example: "1"`
-	FullName       string `json:"full_name" example:"Иванов Иван Иванович"` // Полное имя врача
-	Specialization string `json:"specialization"`
+	DoctorID       uint          `json:"doctor_id" example:"1"`
+	Login          string        `json:"login" example:"+79622840761"`
+	Role           entities.Role `json:"role" example:"doctor"`
+	FullName       string        `json:"full_name" example:"Иванов Иван Иванович"` // Полное имя врача
+	Specialization string        `json:"specialization" example:"Терапевт"`
+	Clinic         string        `json:"clinic" example:"Поликлиника 1"`
+	Crew           string        `json:"crew" example:"Бригада 3"` // Бригада СМП
 }
 
 // CreateDoctorRequest - запрос на создание врача
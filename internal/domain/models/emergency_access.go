@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+)
+
+// EmergencyAccessRequest - запрос экстренного доступа к пациенту
+// @Description Обоснование обязательно: все экстренные доступы проверяются администратором
+type EmergencyAccessRequest struct {
+	Reason string `json:"reason" binding:"required,min=10,max=1000" example:"Пациент без сознания, бригада на месте, карта нужна для анамнеза"`
+}
+
+// EmergencyAccessFilter - фильтр отчёта по экстренным доступам
+type EmergencyAccessFilter struct {
+	From      time.Time `form:"from" time_format:"2006-01-02"` // С даты (включительно)
+	To        time.Time `form:"to" time_format:"2006-01-02"`   // По дату (включительно)
+	UserID    uint      `form:"user_id"`
+	PatientID string    `form:"patient_id"`
+}
+
+// EmergencyAccessReportItem - строка отчёта по экстренным доступам
+type EmergencyAccessReportItem struct {
+	entities.EmergencyAccessGrant
+	UserLogin   string `json:"user_login" example:"+79622840761"`
+	AccessCount int64  `json:"access_count" example:"3"` // Сколько раз данные пациента открывались по этому доступу
+}
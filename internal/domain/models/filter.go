@@ -10,6 +10,43 @@ type FilterResponse[T any] struct {
 	HitsPerPage int `json:"hitsPerPage"`
 }
 
+// FilterField - поле, разрешённое в filter и order: колонка SQL и тип значения
+// (имя Go-типа: string, bool, uint, int, float64, Time)
+type FilterField struct {
+	Column string
+	Type   string
+}
+
+// SQLFilter - условия WHERE и ORDER BY, собранные FilterBuilder из строк filter и order
+type SQLFilter struct {
+	Where  string
+	Params []interface{}
+	Order  []OrderColumn
+}
+
+// OrderColumn - колонка сортировки
+type OrderColumn struct {
+	Column string
+	Desc   bool
+}
+
+// CursorPageRequest - запрос страницы по курсору (keyset pagination)
+type CursorPageRequest struct {
+	Cursor    string // next_cursor или prev_cursor из предыдущего ответа; пустой — первая страница
+	Limit     int
+	WithTotal bool // Посчитать общее количество — отдельный COUNT, только по запросу
+}
+
+// CursorPage - страница списка с курсорами соседних страниц
+// @Description Курсоры непрозрачны и не сдвигаются при добавлении и удалении записей между запросами
+type CursorPage[T any] struct {
+	Hits        T      `json:"hits"`
+	NextCursor  string `json:"next_cursor,omitempty"` // Нет — это последняя страница
+	PrevCursor  string `json:"prev_cursor,omitempty"` // Нет — это первая страница
+	HitsPerPage int    `json:"hitsPerPage"`
+	TotalHits   *int64 `json:"totalHits,omitempty"` // Только при with_total=true
+}
+
 type MedServicesListResponse struct {
 	Hits      []MedServicesResponse `json:"hits"`
 	TotalHits int                   `json:"totalHits"`
@@ -0,0 +1,17 @@
+package models
+
+import "github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+
+// MedCardBatchRequest - пациенты, чьи медкарты нужны одним запросом (например, все пострадавшие вызова)
+type MedCardBatchRequest struct {
+	PatientIDs []string `json:"patient_ids" binding:"required,min=1,max=50,dive,required" example:"user1_id,user2_id"`
+}
+
+// MedCardBatchItem - результат по одному пациенту пакета
+// @Description Либо card, либо error; status — HTTP-код, который вернул бы GET /medcard/{pat_id}
+type MedCardBatchItem struct {
+	PatientID string                    `json:"patient_id" example:"user1_id"`
+	Status    int                       `json:"status" example:"200"`
+	Card      *entities.OneCMedicalCard `json:"card,omitempty"`
+	Error     string                    `json:"error,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MedCardEditRequest - правка медкарты, сделанная на устройстве
+// @Description Правка с клиентским ID; повторная отправка с тем же ID не создаёт дубликат
+type MedCardEditRequest struct {
+	ClientEditID    string          `json:"client_edit_id" binding:"required,max=64" example:"5f0c6a1e-7d2b-4c1a-9b1e-2a7f3c9d8e11"`
+	PatientID       string          `json:"patient_id" binding:"required" example:"user1_id"`
+	BaseVersion     uint            `json:"base_version" binding:"required" example:"3"`                        // Версия карты, на которой сделана правка
+	ClientTimestamp time.Time       `json:"client_timestamp" binding:"required" example:"2024-05-15T14:30:00Z"` // Время правки на устройстве, только для показа
+	Patch           json.RawMessage `json:"patch" binding:"required" swaggertype:"object"`                      // JSON Merge Patch (RFC 7396)
+}
+
+// MedCardEditBatchRequest - пакет офлайн-правок медкарт
+type MedCardEditBatchRequest struct {
+	Edits []MedCardEditRequest `json:"edits" binding:"required,min=1,max=100,dive"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+import (
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+)
 
 // Call — основная структура вызова из 1С
 type Call struct {
@@ -9,7 +13,14 @@ type Call struct {
 	Phone        string     `json:"phone"`         // Телефон пациента
 	PatientCount int        `json:"patient_count"` // Кол-во пациентов
 	Status       CallStatus `json:"status"`        // Статус вызова
+	Crew         []string   `json:"crew"`          // Логины членов бригады, назначенной на вызов
 	Patients     []Patient  `json:"patients"`      // Данные пациентов
+
+	// Адрес по частям с координатами, если 1С его передаёт. Address остаётся основным
+	AddressDetails *entities.Address `json:"address_details,omitempty"`
+
+	// Поля, не прошедшие проверку: имя поля → значение, как его передала 1С. Само поле очищается
+	InvalidFields map[string]string `json:"invalid_fields,omitempty"`
 }
 
 // CallStatus — статус вызова
@@ -22,6 +33,7 @@ const (
 
 // Patient — данные пациента
 type Patient struct {
+	PatientID   string               `json:"patient_id"`  // ID пациента в 1С (если пациент известен)
 	FullName    string               `json:"full_name"`   // ФИО
 	BirthDate   string               `json:"birth_date"`  // Дата рождения
 	Age         string               `json:"age"`         // Возраст
@@ -30,4 +42,15 @@ type Patient struct {
 	Snils       string               `json:"snils"`       // СНИЛС
 	Policy      entities.Policy      `json:"policy"`      // Полис
 	Certificate entities.Certificate `json:"certificate"` // Сертификат
+
+	// Поля, не прошедшие проверку: имя поля → значение, как его передала 1С. Само поле очищается
+	InvalidFields map[string]string `json:"invalid_fields,omitempty"`
+}
+
+// CallListItem — вызов в списке вызовов
+type CallListItem struct {
+	CallID    string    `json:"call_id"`
+	Status    string    `json:"status"` // Статус синхронизации с 1С: received, edited, pending_sync, synced, sync_failed
+	Patients  []Patient `json:"patients"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
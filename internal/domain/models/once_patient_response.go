@@ -1,7 +1,58 @@
 package models
 
-import "github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+import (
+	"time"
 
-type PatientListResponse struct {
-	Patient []entities.OneCPatientListItem
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+)
+
+// PatientListQuery - параметры запроса списка пациентов
+type PatientListQuery struct {
+	Page      int    // Номер страницы для постраничного вывода через OFFSET; 0 — по курсору
+	Cursor    string // next_cursor или prev_cursor из предыдущего ответа
+	WithTotal bool
+	Limit     int
+	Filter    string // Например: full_name.like.Иванов&$gender.eq.true
+	Order     string // Например: birth_date.desc&$full_name.asc
+}
+
+// PatientListFields - поля списка пациентов, доступные в filter и order.
+// Ключ — JSON-тег, тип поля определяет допустимые операторы. Поля вне этой структуры отклоняются
+type PatientListFields struct {
+	FullName  string            `json:"full_name"`
+	BirthDate time.Time         `gorm:"column:birth_date_parsed" json:"birth_date"`
+	Gender    bool              `json:"gender"`
+	Snils     string            `json:"snils"` // Только цифры, из медкарты
+	Policy    PatientListPolicy `gorm:"embedded;embeddedPrefix:policy_" json:"policy"`
 }
+
+// PatientListPolicy - полис из медкарты, в фильтре policy.number
+type PatientListPolicy struct {
+	Number string `json:"number"` // Без пробелов и дефисов
+}
+
+// PatientSearchQuery - нормализованный поисковый запрос. Пустые поля не участвуют в поиске
+type PatientSearchQuery struct {
+	Name     string  // ФИО после namesearch.Normalize
+	Snils    string  // 11 цифр
+	Policy   string  // validation.SearchKey
+	Phone    string  // E.164
+	MinScore float64 // Минимальная похожесть ФИО (0..1)
+	Limit    int
+}
+
+// PatientSearchHit - найденный пациент
+// @Description Сначала идут точные совпадения по СНИЛС, полису или телефону, затем — по похожести ФИО
+type PatientSearchHit struct {
+	Patient   entities.OneCPatientListItem `json:"patient"`
+	Score     float64                      `json:"score" example:"0.72"`      // Похожесть ФИО от 0 до 1, у точных совпадений 1
+	MatchedBy string                       `json:"matched_by" example:"name"` // name, snils, policy, phone
+}
+
+// QRFormat - формат изображения QR-кода пациента
+type QRFormat string
+
+const (
+	QRFormatPNG QRFormat = "png"
+	QRFormatSVG QRFormat = "svg"
+)
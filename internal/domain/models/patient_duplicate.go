@@ -0,0 +1,33 @@
+package models
+
+import "github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+
+// PatientRecordSource — откуда взята запись пациента
+type PatientRecordSource string
+
+const (
+	PatientSourceList PatientRecordSource = "list" // Список пациентов из 1С (с документами из медкарты)
+	PatientSourceCall PatientRecordSource = "call" // Пациент, добавленный бригадой в вызов, которого нет в списке
+)
+
+// PatientRecord - данные пациента для поиска дублей
+type PatientRecord struct {
+	PatientID string              `json:"patient_id"`
+	FullName  string              `json:"full_name"`
+	BirthDate string              `json:"birth_date"`
+	Snils     string              `json:"snils"`
+	Policy    string              `json:"policy"`
+	Source    PatientRecordSource `json:"source"`
+}
+
+// DuplicateCandidateView - пара в очереди проверки вместе с данными обеих записей
+type DuplicateCandidateView struct {
+	entities.PatientDuplicateCandidate
+	Patient *PatientRecord `json:"patient"` // nil — запись уже удалена
+	Other   *PatientRecord `json:"other"`
+}
+
+// MergePatientsRequest - объединение пары дублей
+type MergePatientsRequest struct {
+	SurvivorID string `json:"survivor_id" binding:"required"` // Запись, которая остаётся; вторая запись пары переносится в неё
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+)
+
+// QuickAccessPatient - пациент в списке недавних или избранных
+type QuickAccessPatient struct {
+	PatientID string               `json:"patient_id"`
+	FullName  string               `json:"full_name"` // Из списка пациентов, для пациентов вне списка — из медкарты
+	BirthDate string               `json:"birth_date"`
+	Age       *entities.PatientAge `json:"age,omitempty" gorm:"-"`
+	Favorite  bool                 `json:"favorite"`
+	ViewedAt  *time.Time           `json:"viewed_at,omitempty"`  // Последний просмотр медкарты
+	ViewCount int                  `json:"view_count,omitempty"` // Просмотров за срок хранения
+	AddedAt   *time.Time           `json:"added_at,omitempty"`   // Когда добавлен в избранное
+}
+
+// FillAge вычисляет возраст пациента на дату now
+func (p *QuickAccessPatient) FillAge(now time.Time) {
+	p.Age = nil
+	if birthDate, err := validation.ParseDate(p.BirthDate); err == nil {
+		p.Age = entities.NewPatientAge(birthDate, now)
+	}
+}
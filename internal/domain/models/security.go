@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+)
+
+// SecurityEventFilter - фильтр журнала событий безопасности
+type SecurityEventFilter struct {
+	From  time.Time                  `form:"from" time_format:"2006-01-02"` // С даты (включительно)
+	To    time.Time                  `form:"to" time_format:"2006-01-02"`   // По дату (включительно)
+	Type  entities.SecurityEventType `form:"type"`
+	Login string                     `form:"login"`
+	IP    string                     `form:"ip"`
+	Limit int                        `form:"limit"` // По умолчанию 200, не больше 1000
+}
+
+// UnlockLoginRequest - снятие блокировки входа
+// @Description Передаётся логин, IP-адрес или оба
+type UnlockLoginRequest struct {
+	Login string `json:"login" example:"+79622840761"`
+	IP    string `json:"ip" example:"10.0.0.15"`
+}
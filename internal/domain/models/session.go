@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SessionInfo - активный сеанс пользователя
+// @Description Сеанс соответствует входу на одном устройстве
+type SessionInfo struct {
+	ID         string    `json:"id" example:"3f1c2d4e-5a6b-4c7d-8e9f-0a1b2c3d4e5f"`
+	DeviceID   string    `json:"device_id" example:"a1b2c3d4"`
+	Platform   string    `json:"platform" example:"android"`
+	AppVersion string    `json:"app_version" example:"1.2.3"`
+	IP         string    `json:"ip" example:"10.0.0.15"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current" example:"true"` // Сеанс, из которого сделан запрос
+}
@@ -0,0 +1,54 @@
+package models
+
+import "github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+
+// SyncRequest - запрос ленты изменений
+type SyncRequest struct {
+	Since        string // Токен из предыдущего ответа; пустой — полная синхронизация
+	Limit        int    // Максимум изменений в ответе
+	Subject      AccessSubject
+	MedicalCards bool // Есть право medcard:read
+	Calls        bool // Есть право emergency:read
+}
+
+// ChangeScope - какие изменения видит пользователь
+type ChangeScope struct {
+	ClinicWide       bool   // Все медкарты и вызовы
+	Login            string // Вызовы, на которые назначен пользователь, и медкарты их пациентов
+	DoctorCertNumber string // Медкарты, где пользователь — лечащий врач
+	MedicalCards     bool
+	Calls            bool
+}
+
+// ChangeSet - изменения с номерами от since (не включая) до Seq
+type ChangeSet struct {
+	Seq          int64
+	HasMore      bool
+	Reset        bool // Номер since больше выданных сервером — база пересоздана
+	Patients     []entities.OneCPatientListItem
+	MedicalCards []entities.OneCMedicalCard
+	Calls        []entities.OneCReception
+	Deleted      []SyncDeletion
+}
+
+// SyncDeletion - удалённая запись или запись, которая больше не видна пользователю
+// @Description Медкарта или вызов удаляются с устройства и тогда, когда пользователь потерял к ним доступ:
+// @Description вызов завершён или сменился лечащий врач
+type SyncDeletion struct {
+	Entity entities.SyncEntity `json:"entity"` // patient, medical_card, call
+	ID     string              `json:"id"`     // patient_id или call_id
+}
+
+// SyncResponse - изменения с момента выдачи токена since
+// @Description Клиент сначала применяет deleted, затем добавляет или заменяет записи из остальных списков.
+// @Description Если has_more, следующую порцию нужно запросить сразу с новым token.
+// @Description При reset клиент очищает локальные данные и синхронизируется заново с пустым since
+type SyncResponse struct {
+	Token        string                         `json:"token"` // since для следующего запроса
+	HasMore      bool                           `json:"has_more"`
+	Reset        bool                           `json:"reset,omitempty"`
+	Patients     []entities.OneCPatientListItem `json:"patients"`
+	MedicalCards []entities.OneCMedicalCard     `json:"medical_cards"`
+	Calls        []CallListItem                 `json:"calls"`
+	Deleted      []SyncDeletion                 `json:"deleted"`
+}
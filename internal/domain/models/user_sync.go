@@ -0,0 +1,37 @@
+package models
+
+// UserSyncResult - итог синхронизации пользователей из 1С
+type UserSyncResult struct {
+	Created  int   `json:"created" example:"2"`
+	Updated  int   `json:"updated" example:"15"`
+	Disabled int64 `json:"disabled" example:"1"` // Отключены явно или отсутствуют в полной выгрузке
+	// Отозванные сеансы отключённых пользователей: их websocket-соединения нужно закрыть
+	RevokedSessionIDs []string `json:"-"`
+}
+
+// UserSyncRequest - пользователи из 1С
+// @Description Без full передаются только изменённые пользователи. С full=true список полный,
+// @Description и все отсутствующие в нём пользователи отключаются
+type UserSyncRequest struct {
+	Users []OneCUser `json:"users" binding:"dive"`
+	Full  bool       `json:"full" example:"false"`
+}
+
+// OneCUser - пользователь 1С. Пароль передаётся либо открытым (password), либо bcrypt-хэшем
+// (password_hash). Если не передано ни то, ни другое, сохранённый пароль не меняется
+type OneCUser struct {
+	ID           string `json:"id" binding:"required" example:"a3f1c2d4-0001"` // Стабильный ID пользователя в 1С
+	Login        string `json:"login" binding:"required" example:"doc1"`
+	Password     string `json:"password,omitempty" example:"secret123"`
+	PasswordHash string `json:"password_hash,omitempty" example:"$2a$10$N9qo8uLOickgx2ZMRZoMye..."`
+	Role         string `json:"role" example:"doctor"` // doctor, paramedic, dispatcher, admin, integration; новым — doctor, у существующих пустая роль не меняется
+	Disabled     bool   `json:"disabled" example:"false"`
+	// Номер сертификата врача, по которому он указан лечащим в медкартах
+	DoctorCertNumber string `json:"doctor_cert_number" example:"POL1"`
+
+	// Профиль сотрудника, показывается в приложении (GET /me)
+	FullName       string `json:"full_name" example:"Иванов Иван Иванович"`
+	Specialization string `json:"specialization" example:"Терапевт"`
+	Clinic         string `json:"clinic" example:"Поликлиника 1"`
+	Crew           string `json:"crew" example:"Бригада 3"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// VersionResponse - версия сервера и матрица совместимости мобильного приложения
+type VersionResponse struct {
+	Version string                    `json:"version" example:"1.0.0"`
+	Clients map[string]ClientVersions `json:"clients"` // Ключ — платформа: ios, android
+}
+
+// ClientVersions - поддерживаемые версии приложения на платформе. Пустая строка — без ограничений
+type ClientVersions struct {
+	MinVersion         string `json:"min_version" example:"1.4.0"`
+	RecommendedVersion string `json:"recommended_version" example:"1.6.0"`
+}
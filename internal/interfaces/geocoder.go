@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+)
+
+// Geocoder разбирает строку адреса на части и находит координаты.
+// Адрес без координат (не найден) — не ошибка: возвращаются разобранные части с пустыми Lat/Lon.
+// Ошибка означает, что геокодер недоступен
+type Geocoder interface {
+	Geocode(ctx context.Context, raw string) (*entities.Address, error)
+}
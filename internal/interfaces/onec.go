@@ -5,4 +5,6 @@ import "github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
 type OneCClient interface {
 	GetMedCardByPatientID(patientID string) (*entities.OneCMedicalCard, error)
 	UpdateMedCardByPatientID(patientID string, card *entities.OneCMedicalCard) error
+	PatchMedCardByPatientID(patientID string, changes map[string]interface{}) error
+	ReportPatientMerge(survivorID, mergedID string) error
 }
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
@@ -10,14 +11,24 @@ import (
 
 type Repository interface {
 	AuthRepository
+	TokenRepository
 	DoctorRepository
 	PatientRepository
 	ReceptionSmpRepository
 	MedicalCardRepository
+	MedCardEditRepository
+	PatientAccessRepository
+	SecurityRepository
+	MFARepository
+	SessionRepository
+	ChangeFeedRepository
+	PatientDuplicateRepository
+	QuickAccessRepository
 	TxManager
 }
 
 type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 	Rollback(ctx context.Context) error
 	Commit(ctx context.Context) error
 	GetTransaction(ctx context.Context) *gorm.DB
@@ -26,13 +37,27 @@ type TxManager interface {
 type MedicalCardRepository interface {
 	SaveMedicalCard(ctx context.Context, card *entities.OneCMedicalCard) error
 	GetMedicalCard(ctx context.Context, patientID string) (*entities.OneCMedicalCard, error)
+	GetMedicalCardForUpdate(ctx context.Context, patientID string) (*entities.OneCMedicalCard, error)
+	GetMedicalCards(ctx context.Context, patientIDs []string) ([]entities.OneCMedicalCard, error)
+	UpdateMedicalCard(ctx context.Context, card *entities.OneCMedicalCard, expectedVersion uint) error
 	DeleteMedicalCard(ctx context.Context, patientID string) error
+	GetMergeSurvivor(ctx context.Context, patientID string) (string, error)
 }
 
-// updated to match the new structure
+// MedCardEditRepository — очередь офлайн-правок медкарт
+type MedCardEditRepository interface {
+	SaveMedCardEdits(ctx context.Context, edits []entities.MedCardEdit) error
+	GetMedCardEditsByClientIDs(ctx context.Context, userID uint, clientEditIDs []string) ([]entities.MedCardEdit, error)
+	ListMedCardEdits(ctx context.Context, userID uint, status entities.MedCardEditStatus, limit int) ([]entities.MedCardEdit, error)
+	ClaimPendingMedCardEdit(ctx context.Context, now time.Time, lease time.Duration) (*entities.MedCardEdit, error)
+	GetLastAppliedMedCardEdit(ctx context.Context, userID uint, patientID string) (*entities.MedCardEdit, error)
+	UpdateMedCardEdit(ctx context.Context, edit *entities.MedCardEdit) error
+}
+
+// DoctorRepository — профили сотрудников, синхронизированные из 1С
 type DoctorRepository interface {
-	GetDoctorByID(ctx context.Context, id uint) (entities.Doctor, error)
-	GetDoctorByLogin(ctx context.Context, login string) (entities.Doctor, error)
+	GetDoctorByID(ctx context.Context, userID uint) (*entities.DoctorProfile, error)
+	GetDoctorByLogin(ctx context.Context, login string) (*entities.DoctorProfile, error)
 }
 
 // updated to match the new structure
@@ -40,6 +65,77 @@ type ReceptionSmpRepository interface {
 	// Вызовы (скорая)
 	SaveReceptions(ctx context.Context, callID string, receptions []models.Patient) error
 	GetReceptions(ctx context.Context, callID string) ([]models.Patient, error)
+	ReplaceCallAssignments(ctx context.Context, callID string, assignments []entities.CallAssignment) error
+	HasCallAssignment(ctx context.Context, login, patientID string) (bool, error)
+	ListCalls(ctx context.Context, assignedUserID uint, page models.CursorPageRequest) (*models.CursorPage[[]entities.OneCReception], error)
+}
+
+// SessionRepository — устройства и сеансы входа
+type SessionRepository interface {
+	UpsertDevice(ctx context.Context, device *entities.Device) error
+	CreateSession(ctx context.Context, session *entities.Session) error
+	TouchSession(ctx context.Context, id, ip string, expiresAt time.Time) error
+	GetSession(ctx context.Context, id string) (*entities.Session, error)
+	RevokeSession(ctx context.Context, id string) error
+	IsSessionRevoked(ctx context.Context, id string) (bool, error)
+	GetActiveSessions(ctx context.Context, userID uint, now time.Time) ([]models.SessionInfo, error)
+}
+
+// ChangeFeedRepository — лента изменений для синхронизации мобильных клиентов
+type ChangeFeedRepository interface {
+	GetChanges(ctx context.Context, since int64, limit int, scope models.ChangeScope) (*models.ChangeSet, error)
+}
+
+// PatientDuplicateRepository — поиск и объединение дублей пациентов
+type PatientDuplicateRepository interface {
+	GetDuplicateScanRecords(ctx context.Context) ([]models.PatientRecord, error)
+	GetPatientRecords(ctx context.Context, patientIDs []string) ([]models.PatientRecord, error)
+	ReplacePendingDuplicateCandidates(ctx context.Context, candidates []entities.PatientDuplicateCandidate, scannedAt time.Time) error
+	ListDuplicateCandidates(ctx context.Context, status entities.PatientDuplicateStatus, limit int) ([]entities.PatientDuplicateCandidate, error)
+	GetDuplicateCandidate(ctx context.Context, id uint) (*entities.PatientDuplicateCandidate, error)
+	DismissDuplicateCandidate(ctx context.Context, id, reviewerID uint) error
+	MergePatients(ctx context.Context, candidateID uint, merge *entities.PatientMerge) error
+	ClaimPendingPatientMerge(ctx context.Context, afterID uint) (*entities.PatientMerge, error)
+	ClaimPatientMerge(ctx context.Context, id uint) (*entities.PatientMerge, error)
+	UpdatePatientMerge(ctx context.Context, merge *entities.PatientMerge) error
+}
+
+// QuickAccessRepository — недавно просмотренные и избранные пациенты пользователя
+type QuickAccessRepository interface {
+	RecordPatientView(ctx context.Context, userID uint, patientID string, viewedAt time.Time) error
+	GetRecentPatients(ctx context.Context, userID uint, since time.Time, limit int) ([]models.QuickAccessPatient, error)
+	GetFavoritePatients(ctx context.Context, userID uint, since time.Time) ([]models.QuickAccessPatient, error)
+	AddFavoritePatient(ctx context.Context, userID uint, patientID string) error
+	RemoveFavoritePatient(ctx context.Context, userID uint, patientID string) (bool, error)
+	PruneRecentPatients(ctx context.Context, before time.Time) (int64, error)
+}
+
+// MFARepository — второй фактор (TOTP) и коды восстановления
+type MFARepository interface {
+	GetUserMFA(ctx context.Context, userID uint) (*entities.UserMFA, error)
+	SavePendingMFA(ctx context.Context, userID uint, secret string) error
+	UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	ConfirmMFA(ctx context.Context, userID uint, recoveryCodeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+	DeleteUserMFA(ctx context.Context, userID uint) error
+}
+
+// SecurityRepository — счётчики неудачных входов и журнал событий безопасности
+type SecurityRepository interface {
+	ReserveLoginAttempt(ctx context.Context, key string, now, windowStart time.Time, free int, lockFor func(step int) time.Duration) (entities.LoginAttemptCounter, bool, error)
+	ReleaseLoginAttempt(ctx context.Context, key string, lockedUntil *time.Time) error
+	ResetLoginCounter(ctx context.Context, key string) (bool, error)
+	SaveSecurityEvent(ctx context.Context, event *entities.SecurityEvent) error
+	GetSecurityEvents(ctx context.Context, filter models.SecurityEventFilter) ([]entities.SecurityEvent, error)
+}
+
+// PatientAccessRepository — журнал доступа к данным пациентов
+type PatientAccessRepository interface {
+	SavePatientAccessLog(ctx context.Context, entry *entities.PatientAccessLog) error
+	SavePatientAccessLogs(ctx context.Context, entries []entities.PatientAccessLog) error
+	SaveEmergencyAccessGrant(ctx context.Context, grant *entities.EmergencyAccessGrant) error
+	HasActiveEmergencyAccessGrant(ctx context.Context, userID uint, patientID string, now time.Time) (bool, error)
+	GetEmergencyAccessReport(ctx context.Context, filter models.EmergencyAccessFilter) ([]models.EmergencyAccessReportItem, error)
 }
 
 // updated to match the new structured
@@ -47,10 +143,28 @@ type PatientRepository interface {
 	// Список пациентов
 	SavePatientList(ctx context.Context, patients []entities.OneCPatientListItem) error
 	SaveOrUpdatePatientList(ctx context.Context, patients []entities.OneCPatientListItem) error
-	GetPatientListPage(ctx context.Context, offset, limit int) ([]entities.OneCPatientListItem, int64, error)
+	GetPatientListPage(ctx context.Context, offset, limit int, filter models.SQLFilter) ([]entities.OneCPatientListItem, int64, error)
+	GetPatientListCursor(ctx context.Context, page models.CursorPageRequest, filter models.SQLFilter) (*models.CursorPage[[]entities.OneCPatientListItem], error)
+	SearchPatients(ctx context.Context, query models.PatientSearchQuery) ([]models.PatientSearchHit, error)
+	GetPatientListItem(ctx context.Context, patientID string) (*entities.OneCPatientListItem, error)
+	GetPatientsByPolicy(ctx context.Context, policy string, limit int) ([]entities.OneCPatientListItem, error)
 }
 
 type AuthRepository interface {
-	SaveUsers(ctx context.Context, users []entities.AuthUser) error
+	SyncUsers(ctx context.Context, users []entities.AuthUser, disableMissing bool) (models.UserSyncResult, error)
 	GetUserByLogin(ctx context.Context, login string) (*entities.AuthUser, error)
+	GetUserByID(ctx context.Context, id uint) (*entities.AuthUser, error)
+	GetUserByDoctorCertNumber(ctx context.Context, certNumber string) (*entities.AuthUser, error)
+	SaveRolePermissions(ctx context.Context, rows []entities.RolePermission) error
+	GetRolePermissions(ctx context.Context, role entities.Role) ([]entities.Permission, error)
+}
+
+// TokenRepository — refresh-токены и список отозванных access-токенов
+type TokenRepository interface {
+	SaveRefreshToken(ctx context.Context, token *entities.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, hash string) (*entities.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id uint) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
 }
@@ -1,6 +1,10 @@
 package interfaces
 
-import "time"
+import (
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+)
 
 type Service interface {
 	ParamsParserService
@@ -20,6 +24,6 @@ type ParamsParserService interface {
 }
 
 type FilterBuilderService interface {
-	ParseFilterString(filterStr string, modelFields map[string]string) (string, []interface{}, error)
-	ParseOrderString(orderStr string, modelFields map[string]string) (string, error)
+	ParseFilterString(filterStr string, modelFields map[string]models.FilterField) (string, []interface{}, error)
+	ParseOrderString(orderStr string, modelFields map[string]models.FilterField) ([]models.OrderColumn, error)
 }
@@ -11,14 +11,22 @@ import (
 type Usecases interface {
 	ReceptionSmpUsecase
 	MedCardUsecase
+	MedCardSyncUsecase
+	PatientAccessUsecase
 	AuthUsecase
 	OneCWebhookUsecase
 	OneCPatientUsecase
+	SyncUsecase
+	PatientDuplicateUsecase
+	QuickAccessUsecase
+	PatientCodeUsecase
 }
 
 type OneCPatientUsecase interface {
 	HandlePatientListUpdate(ctx context.Context, update entities.PatientListUpdate) error
-	GetPatientListPage(ctx context.Context, offset, limit int) ([]entities.OneCPatientListItem, error)
+	GetPatientListPage(ctx context.Context, query models.PatientListQuery) (*models.FilterResponse[[]entities.OneCPatientListItem], error)
+	GetPatientListCursor(ctx context.Context, query models.PatientListQuery) (*models.CursorPage[[]entities.OneCPatientListItem], error)
+	SearchPatients(ctx context.Context, q string, limit int) ([]models.PatientSearchHit, error)
 }
 
 type OneCWebhookUsecase interface {
@@ -27,14 +35,74 @@ type OneCWebhookUsecase interface {
 }
 
 type ReceptionSmpUsecase interface {
+	ListCalls(ctx context.Context, subject models.AccessSubject, page models.CursorPageRequest) (*models.CursorPage[[]models.CallListItem], error)
+}
+
+// SyncUsecase — лента изменений для офлайн-синхронизации мобильных клиентов
+type SyncUsecase interface {
+	GetChanges(ctx context.Context, req models.SyncRequest) (*models.SyncResponse, error)
+}
+
+// PatientDuplicateUsecase — поиск дублей пациентов, очередь проверки и объединение
+type PatientDuplicateUsecase interface {
+	ScanPatientDuplicates(ctx context.Context) (int, error)
+	ListPatientDuplicates(ctx context.Context, status entities.PatientDuplicateStatus, limit int) ([]models.DuplicateCandidateView, error)
+	DismissPatientDuplicate(ctx context.Context, id, reviewerID uint) error
+	MergePatientDuplicate(ctx context.Context, id uint, survivorID string, userID uint) (*entities.PatientMerge, error)
+	ReportPendingPatientMerges(ctx context.Context) error
+}
+
+// PatientCodeUsecase — идентификация пациента по QR-коду браслета или штрихкоду полиса ОМС
+type PatientCodeUsecase interface {
+	GetPatientQR(ctx context.Context, patientID string, format models.QRFormat, size int) ([]byte, error)
+	ResolvePatientCode(ctx context.Context, code string) (*entities.OneCPatientListItem, error)
+}
+
+// QuickAccessUsecase — недавно просмотренные и избранные пациенты пользователя
+type QuickAccessUsecase interface {
+	RecordPatientView(ctx context.Context, userID uint, patientID string) error
+	GetRecentPatients(ctx context.Context, userID uint, limit int) ([]models.QuickAccessPatient, error)
+	GetFavoritePatients(ctx context.Context, userID uint) ([]models.QuickAccessPatient, error)
+	AddFavoritePatient(ctx context.Context, userID uint, patientID string) error
+	RemoveFavoritePatient(ctx context.Context, userID uint, patientID string) error
+	PruneRecentPatients(ctx context.Context) (int64, error)
 }
 
 type MedCardUsecase interface {
 	GetMedCardByPatientID(ctx context.Context, patientID string) (*entities.OneCMedicalCard, error)
-	UpdateMedicalCard(ctx context.Context, card *entities.OneCMedicalCard) error
+	GetMedCardsBatch(ctx context.Context, patientIDs []string) []models.MedCardBatchItem
+	UpdateMedicalCard(ctx context.Context, card *entities.OneCMedicalCard, expectedVersion uint) (uint, error)
+	PatchMedicalCard(ctx context.Context, patientID string, patch []byte, expectedVersion uint) (*entities.OneCMedicalCard, error)
+}
+
+// PatientAccessUsecase — политика доступа к данным конкретного пациента
+type PatientAccessUsecase interface {
+	AuthorizePatientAccess(ctx context.Context, subject models.AccessSubject, patientID string, action entities.PatientAccessAction) error
+	AuthorizeCachedPatientAccess(ctx context.Context, subject models.AccessSubject, patientID string, action entities.PatientAccessAction) error
+	RequestEmergencyAccess(ctx context.Context, userID uint, patientID, reason string) (*entities.EmergencyAccessGrant, error)
+	GetEmergencyAccessReport(ctx context.Context, filter models.EmergencyAccessFilter) ([]models.EmergencyAccessReportItem, error)
+}
+
+type MedCardSyncUsecase interface {
+	EnqueueMedCardEdits(ctx context.Context, userID uint, edits []models.MedCardEditRequest) ([]entities.MedCardEdit, error)
+	GetMedCardEdits(ctx context.Context, userID uint, clientEditIDs []string, status entities.MedCardEditStatus) ([]entities.MedCardEdit, error)
+	ProcessPendingMedCardEdits(ctx context.Context) error
 }
 
 type AuthUsecase interface {
-	SyncUsers(ctx context.Context, users []entities.AuthUser) error
-	LoginDoctor(ctx context.Context, phone, password string) (*models.DoctorAuthResponse, *errors.AppError)
+	SyncUsers(ctx context.Context, req models.UserSyncRequest) (models.UserSyncResult, error)
+	SyncRolePermissions(ctx context.Context, mapping map[entities.Role][]entities.Permission) error
+	LoginDoctor(ctx context.Context, req models.DoctorLoginRequest) (*models.DoctorAuthResponse, *errors.AppError)
+	RefreshTokens(ctx context.Context, req models.RefreshTokenRequest) (*models.DoctorAuthResponse, *errors.AppError)
+	Logout(ctx context.Context, session models.TokenSession) *errors.AppError
+	IsAccessTokenRevoked(ctx context.Context, jti, sid string) (bool, error)
+	GetCurrentUser(ctx context.Context, userID uint) (*models.DoctorInfoResponse, *errors.AppError)
+	ListSessions(ctx context.Context, userID uint, currentSID string) ([]models.SessionInfo, error)
+	RevokeSession(ctx context.Context, userID uint, sessionID string) *errors.AppError
+	UnlockLogin(ctx context.Context, adminID uint, req models.UnlockLoginRequest) error
+	VerifyMFA(ctx context.Context, req models.MFAVerifyRequest) (*models.DoctorAuthResponse, *errors.AppError)
+	EnrollMFA(ctx context.Context, userID uint) (*models.MFAEnrollment, *errors.AppError)
+	ConfirmMFA(ctx context.Context, userID uint, req models.MFACodeRequest) ([]string, *errors.AppError)
+	DisableMFA(ctx context.Context, userID uint, req models.MFACodeRequest) *errors.AppError
+	GetSecurityEvents(ctx context.Context, filter models.SecurityEventFilter) ([]entities.SecurityEvent, error)
 }
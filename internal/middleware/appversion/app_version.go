@@ -0,0 +1,67 @@
+package appversion
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/config"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/appversion"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	PlatformHeader = "X-App-Platform" // ios, android
+	VersionHeader  = "X-App-Version"  // Версия сборки приложения, например 1.4.2
+	// UpdateRecommendedHeader выставляется в ответе, если версия ниже рекомендуемой
+	UpdateRecommendedHeader = "X-App-Update-Recommended"
+)
+
+// RequireSupported отклоняет запросы устаревших сборок приложения с кодом 426.
+// Запросы без заголовков версии (веб-клиенты, 1С) и с неизвестной платформой пропускаются
+func RequireSupported(cfg config.ClientsConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := c.GetHeader(VersionHeader)
+		platform := strings.ToLower(c.GetHeader(PlatformHeader))
+		versions, ok := cfg.Platforms[platform]
+		if version == "" || !ok {
+			c.Next()
+			return
+		}
+
+		if versions.MinVersion != "" {
+			outdated, err := appversion.Less(version, versions.MinVersion)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"status": "error",
+					"error": gin.H{
+						"code":    http.StatusBadRequest,
+						"message": "Invalid " + VersionHeader + " header",
+					},
+				})
+				return
+			}
+			if outdated {
+				c.AbortWithStatusJSON(http.StatusUpgradeRequired, gin.H{
+					"status": "error",
+					"error": gin.H{
+						"code":                http.StatusUpgradeRequired,
+						"message":             "Upgrade required",
+						"platform":            platform,
+						"current_version":     version,
+						"min_version":         versions.MinVersion,
+						"recommended_version": versions.RecommendedVersion,
+					},
+				})
+				return
+			}
+		}
+
+		if versions.RecommendedVersion != "" {
+			if outdated, err := appversion.Less(version, versions.RecommendedVersion); err == nil && outdated {
+				c.Header(UpdateRecommendedHeader, "true")
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package appversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireSupported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.ClientsConfig{Platforms: map[string]config.ClientVersionConfig{
+		"ios":     {MinVersion: "1.4.0", RecommendedVersion: "1.6.0"},
+		"android": {},
+	}}
+
+	tests := []struct {
+		name       string
+		platform   string
+		version    string
+		wantStatus int
+		wantUpdate bool
+	}{
+		{"no headers", "", "", http.StatusOK, false},
+		{"no version header", "ios", "", http.StatusOK, false},
+		{"no platform header", "", "1.0.0", http.StatusOK, false},
+		{"unknown platform", "web", "0.1", http.StatusOK, false},
+		{"platform without limits", "android", "0.1", http.StatusOK, false},
+		{"platform case", "IOS", "1.0.0", http.StatusUpgradeRequired, false},
+		{"below minimum", "ios", "1.3.9", http.StatusUpgradeRequired, false},
+		{"pre-release of minimum", "ios", "1.4.0-beta", http.StatusOK, true},
+		{"minimum", "ios", "1.4.0", http.StatusOK, true},
+		{"build suffix", "ios", "1.5.2+301", http.StatusOK, true},
+		{"recommended", "ios", "1.6", http.StatusOK, false},
+		{"newer", "ios", "2.0.0", http.StatusOK, false},
+		{"malformed", "ios", "latest", http.StatusBadRequest, false},
+		{"malformed component", "ios", "1..4", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(RequireSupported(cfg))
+			r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.platform != "" {
+				req.Header.Set(PlatformHeader, tt.platform)
+			}
+			if tt.version != "" {
+				req.Header.Set(VersionHeader, tt.version)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get(UpdateRecommendedHeader) == "true"; got != tt.wantUpdate {
+				t.Errorf("%s = %v, want %v", UpdateRecommendedHeader, got, tt.wantUpdate)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -8,7 +9,41 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func JWTAuth(secretKey string) gin.HandlerFunc {
+// DeviceIDHeader — заголовок с ID устройства, к которому привязан токен (claim "dev")
+const DeviceIDHeader = "X-Device-ID"
+
+// Параметры запроса с токеном и ID устройства для запросов без своих заголовков (WebSocket из браузера)
+const (
+	AccessTokenQueryParam = "access_token"
+	DeviceIDQueryParam    = "device_id"
+)
+
+// RevocationChecker проверяет, не отозван ли access-токен или его сеанс (выход, удалённое завершение)
+type RevocationChecker interface {
+	IsAccessTokenRevoked(ctx context.Context, jti, sid string) (bool, error)
+}
+
+// TokenFromQuery переносит токен и ID устройства из параметров запроса в заголовки, если заголовков нет.
+// Токен убирается из URL, чтобы не попасть в журналы дальше по цепочке.
+// Ставится перед JWTAuth только на маршруты, которые клиент не может открыть с заголовками
+func TokenFromQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if token := query.Get(AccessTokenQueryParam); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		if device := query.Get(DeviceIDQueryParam); device != "" && c.GetHeader(DeviceIDHeader) == "" {
+			c.Request.Header.Set(DeviceIDHeader, device)
+		}
+		if query.Has(AccessTokenQueryParam) {
+			query.Del(AccessTokenQueryParam)
+			c.Request.URL.RawQuery = query.Encode()
+		}
+		c.Next()
+	}
+}
+
+func JWTAuth(secretKey string, checker RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 1. Берём токен из заголовка
 		authHeader := c.GetHeader("Authorization")
@@ -54,8 +89,46 @@ func JWTAuth(secretKey string) gin.HandlerFunc {
 		}
 
 		// 4. Достаём claims и сохраняем в контекст
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			c.Set("user_id", claims["user_id"])
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		// 5. Проверяем, что токен не отозван
+		jti, _ := claims["jti"].(string)
+		if jti == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+		// Токен, выпущенный для устройства, принимается только с этого устройства
+		if dev, _ := claims["dev"].(string); dev != "" && c.GetHeader(DeviceIDHeader) != dev {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is bound to another device"})
+			c.Abort()
+			return
+		}
+		sid, _ := claims["sid"].(string)
+		revoked, err := checker.IsAccessTokenRevoked(c.Request.Context(), jti, sid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check token"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims["user_id"])
+		c.Set("jti", jti)
+		c.Set("sid", sid)
+		c.Set("role", claims["role"])
+		c.Set("permissions", permissionsFromClaims(claims))
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			c.Set("exp", exp.Time)
 		}
 
 		// Пускаем дальше
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RequirePermission пропускает запрос, только если в access-токене есть право permission.
+// Должен стоять после JWTAuth
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions", "required": permission})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasPermission проверяет право из access-токена текущего запроса
+func HasPermission(c *gin.Context, permission string) bool {
+	value, _ := c.Get("permissions")
+	permissions, _ := value.(map[string]bool)
+	return permissions[permission]
+}
+
+// permissionsFromClaims достаёт список прав из claims (JSON-массив приходит как []interface{})
+func permissionsFromClaims(claims jwt.MapClaims) map[string]bool {
+	permissions := map[string]bool{}
+	list, _ := claims["permissions"].([]interface{})
+	for _, item := range list {
+		if perm, ok := item.(string); ok {
+			permissions[perm] = true
+		}
+	}
+	return permissions
+}
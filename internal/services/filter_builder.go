@@ -1,7 +1,3 @@
-// TODO:
-// Сейчас номер страницы работает как их кол-во (3, то выведутся записи 1-3)
-// Количество записей как offset (если 3, то выводим с 4-й записи и далее)
-// Фильтр вроде норм работает
 package services
 
 import (
@@ -9,11 +5,33 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
 )
 
+// Грамматика фильтра:
+//
+//	expr      = and { "|" and }             — OR
+//	and       = primary { "&$" primary }    — AND (в URL "%26$")
+//	primary   = "(" expr ")" | condition
+//	condition = field "." op "." value
+//
+// field — ключ из modelFields, может быть вложенным (policy.number). Точки в value допустимы.
+// Списки для in/nin/between разделяются запятыми. Символы \ . , | ( ) & экранируются обратным слэшем.
+// В SQL попадают только колонки из modelFields, значения всегда передаются параметрами
+
+const (
+	filterAnd    = "&$"
+	filterOr     = '|'
+	filterEscape = '\\'
+
+	// Ограничения против слишком тяжёлых запросов
+	maxFilterDepth      = 5
+	maxFilterConditions = 50
+	maxFilterListLength = 100
+)
+
 // FilterBuilder отвечает за построение SQL-фильтров по строкам фильтра
 type FilterBuilder struct {
 	paramsParser interfaces.ParamsParserService
@@ -23,179 +41,430 @@ func NewFilterBuilder(parser interfaces.ParamsParserService) interfaces.FilterBu
 	return &FilterBuilder{paramsParser: parser}
 }
 
-// ParseFilterString — разбирает строку фильтрации вида "name.eq.John&$created_at.eq.2023-07-01"
+// ParseFilterString — разбирает строку фильтрации вида "name.eq.John&$(age.gt.25|age.in.10,20)"
 // Возвращает SQL-условие (`WHERE ...`) и список параметров для подстановки
-
-func (s *FilterBuilder) ParseFilterString(filterStr string, modelFields map[string]string) (string, []interface{}, error) {
-
+func (s *FilterBuilder) ParseFilterString(filterStr string, modelFields map[string]models.FilterField) (string, []interface{}, error) {
 	// Если строка фильтра пустая — ошибка
 	if len(filterStr) == 0 {
 		return "", nil, errors.New("filter parameter is empty")
 	}
 
-	var query string         // Финальная SQL-строка
-	var params []interface{} // Массив параметров, которые подставятся
-	fmt.Println("Raw filter string:", filterStr)
-	// Разделение условий по спец-разделителю '&$' — например, name.eq.John&$age.eq.25
-	// В URL нужно писать %26$
-	conditions := strings.Split(filterStr, "&$")
+	p := &filterParser{input: filterStr, fields: modelFields, builder: s}
+	query, err := p.parseExpr(0)
+	if err != nil {
+		return "", nil, err
+	}
+	if p.pos < len(p.input) {
+		return "", nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
 
-	// Обработка каждого условия
-	for i, cond := range conditions {
-		fmt.Println("Cond:", cond)
-		// Условие должно быть вида "field.compare.value"
-		parts := strings.Split(cond, ".")
-		if len(parts) != 3 {
-			return "", nil, fmt.Errorf("invalid filter format: %s", cond)
-		}
+	return query, p.params, nil
+}
 
-		field, compare, value := parts[0], parts[1], parts[2]
+// filterParser — рекурсивный разбор строки фильтра
+type filterParser struct {
+	input      string
+	pos        int
+	fields     map[string]models.FilterField
+	builder    *FilterBuilder
+	params     []interface{}
+	conditions int
+}
 
-		var fieldType string
+func (p *filterParser) parseExpr(depth int) (string, error) {
+	if depth > maxFilterDepth {
+		return "", fmt.Errorf("filter nesting is deeper than %d", maxFilterDepth)
+	}
 
-		// Определение типа поля: сначала ищем в modelFields
-		if t, ok := modelFields[field]; ok {
-			fieldType = t
-		} else {
-			return "", nil, fmt.Errorf("invalid filter key: %s", field)
+	parts := []string{}
+	for {
+		part, err := p.parseAnd(depth)
+		if err != nil {
+			return "", err
 		}
+		parts = append(parts, part)
+
+		if p.pos < len(p.input) && p.input[p.pos] == filterOr {
+			p.pos++
+			continue
+		}
+		break
+	}
 
-		// Построение SQL-условия по полю, значению и типу
-		subQuery, param, err := s.buildCondition(field, value, compare, fieldType)
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", nil
+}
+
+func (p *filterParser) parseAnd(depth int) (string, error) {
+	parts := []string{}
+	for {
+		part, err := p.parsePrimary(depth)
 		if err != nil {
-			return "", nil, err
+			return "", err
 		}
+		parts = append(parts, part)
 
-		// Объединяем условия через AND
-		if i > 0 {
-			query += " AND "
+		if strings.HasPrefix(p.input[p.pos:], filterAnd) {
+			p.pos += len(filterAnd)
+			continue
 		}
-		query += subQuery
+		break
+	}
+	return strings.Join(parts, " AND "), nil
+}
 
-		// Добавляем параметры, если они есть (например, для '?')
-		if param != nil {
-			params = append(params, param)
+func (p *filterParser) parsePrimary(depth int) (string, error) {
+	if p.pos < len(p.input) && p.input[p.pos] == '(' {
+		p.pos++
+		query, err := p.parseExpr(depth + 1)
+		if err != nil {
+			return "", err
+		}
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return "", fmt.Errorf("missing ')' at position %d", p.pos)
 		}
+		p.pos++
+		return "(" + query + ")", nil
+	}
+
+	raw, err := p.readCondition()
+	if err != nil {
+		return "", err
+	}
 
+	p.conditions++
+	if p.conditions > maxFilterConditions {
+		return "", fmt.Errorf("too many filter conditions, max %d", maxFilterConditions)
 	}
 
-	return query, params, nil
+	query, params, err := p.builder.parseCondition(raw, p.fields)
+	if err != nil {
+		return "", err
+	}
+	p.params = append(p.params, params...)
+	return query, nil
 }
 
-// buildCondition — создает отдельное условие SQL по типу поля и оператору сравнения
-func (s *FilterBuilder) buildCondition(key, value, compare, fieldType string) (string, interface{}, error) {
+// readCondition читает условие до неэкранированного "|", ")" или "&$". Экранирование сохраняется
+func (p *filterParser) readCondition() (string, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c == filterEscape {
+			if p.pos+1 >= len(p.input) {
+				return "", errors.New("dangling escape at the end of filter")
+			}
+			p.pos += 2
+			continue
+		}
+		if c == filterOr || c == ')' || c == '(' || strings.HasPrefix(p.input[p.pos:], filterAnd) {
+			break
+		}
+		p.pos++
+	}
+
+	if p.pos < len(p.input) && p.input[p.pos] == '(' {
+		return "", fmt.Errorf("unexpected '(' at position %d, escape it as \\(", p.pos)
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("empty condition at position %d", p.pos)
+	}
+	return p.input[start:p.pos], nil
+}
+
+// parseCondition разбирает "field.op.value". Поле может содержать точки, поэтому берётся
+// самый короткий префикс, который есть в modelFields и за которым следует оператор
+func (s *FilterBuilder) parseCondition(raw string, modelFields map[string]models.FilterField) (string, []interface{}, error) {
+	parts := splitUnescaped(raw, '.')
+	if len(parts) < 3 {
+		return "", nil, fmt.Errorf("invalid filter format: %s", raw)
+	}
+
+	for i := 1; i < len(parts)-1; i++ {
+		key := unescape(strings.Join(parts[:i], "."))
+		field, ok := modelFields[key]
+		if !ok {
+			continue
+		}
+		compare := unescape(parts[i])
+		value := strings.Join(parts[i+1:], ".")
+		return s.buildCondition(field, value, compare)
+	}
+
+	return "", nil, fmt.Errorf("invalid filter key: %s", unescape(parts[0]))
+}
+
+// buildCondition — создает отдельное условие SQL по типу поля и оператору сравнения.
+// value ещё содержит экранирование, чтобы списки можно было разделить по запятым
+func (s *FilterBuilder) buildCondition(field models.FilterField, value, compare string) (string, []interface{}, error) {
+	column := field.Column
+
 	if value == "NULL" {
 		switch compare {
 		case "eq":
-			return fmt.Sprintf("%s IS NULL", key), nil, nil
+			return fmt.Sprintf("%s IS NULL", column), nil, nil
 		case "ne":
-			return fmt.Sprintf("%s IS NOT NULL", key), nil, nil
+			return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
 		default:
 			return "", nil, fmt.Errorf("unsupported compare for NULL: %s", compare)
 		}
 	}
 
+	if !allowedCompare(field.Type, compare) {
+		return "", nil, fmt.Errorf("unsupported %s compare: %s", field.Type, compare)
+	}
+
+	// Для дат и времени сравнивается приведённое значение колонки
+	var values []interface{}
+	var err error
+	if field.Type == "Time" {
+		column, values, err = s.parseTimeValues(column, value, compare)
+	} else {
+		values, err = parseValues(field.Type, value, compare)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch compare {
+	case "eq":
+		return fmt.Sprintf("%s = ?", column), values, nil
+	case "ne":
+		return fmt.Sprintf("%s <> ?", column), values, nil
+	case "gt":
+		return fmt.Sprintf("%s > ?", column), values, nil
+	case "gte":
+		return fmt.Sprintf("%s >= ?", column), values, nil
+	case "lt":
+		return fmt.Sprintf("%s < ?", column), values, nil
+	case "lte":
+		return fmt.Sprintf("%s <= ?", column), values, nil
+	case "between":
+		return fmt.Sprintf("%s BETWEEN ? AND ?", column), values, nil
+	case "like":
+		// Подстрока
+		return fmt.Sprintf("%s ILIKE ?", column), values, nil
+	case "in":
+		return fmt.Sprintf("%s IN ?", column), []interface{}{values}, nil
+	case "nin":
+		return fmt.Sprintf("%s NOT IN ?", column), []interface{}{values}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported compare: %s", compare)
+	}
+}
+
+// allowedCompare — какие операторы имеют смысл для типа поля
+func allowedCompare(fieldType, compare string) bool {
 	switch fieldType {
 	case "string":
 		switch compare {
-		case "eq":
-			return fmt.Sprintf("%s = ?", key), value, nil
-		case "like":
-			// Подстрока
-			return fmt.Sprintf("%s ILIKE ?", key), "%" + value + "%", nil
-
-		default:
-			return "", nil, fmt.Errorf("unsupported string compare: %s", compare)
+		case "eq", "ne", "like", "in", "nin":
+			return true
 		}
-
-	case "uint":
+	case "int", "int32", "int64", "uint", "uint32", "uint64", "float32", "float64":
 		switch compare {
-		case "eq":
-			return fmt.Sprintf("%s = ?", key), value, nil
-
-		default:
-			return "", nil, fmt.Errorf("unsupported uint compare: %s", compare)
+		case "eq", "ne", "gt", "gte", "lt", "lte", "between", "in", "nin":
+			return true
 		}
-
 	case "bool":
 		switch compare {
-		case "eq":
-			boolVal, err := strconv.ParseBool(value)
+		case "eq", "ne":
+			return true
+		}
+	case "Time":
+		switch compare {
+		case "eq", "ne", "gt", "gte", "lt", "lte", "between":
+			return true
+		}
+	}
+	return false
+}
+
+// parseValues разбирает значение (или список значений) и приводит к типу поля
+func parseValues(fieldType, value, compare string) ([]interface{}, error) {
+	raw, err := valueList(value, compare)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(raw))
+	for i, v := range raw {
+		switch fieldType {
+		case "string":
+			if compare == "like" {
+				values[i] = "%" + escapeLike(v) + "%"
+			} else {
+				values[i] = v
+			}
+		case "bool":
+			boolVal, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid boolean value: %s", v)
+			}
+			values[i] = boolVal
+		case "uint", "uint32", "uint64":
+			n, err := strconv.ParseUint(v, 10, 64)
 			if err != nil {
-				return "", nil, fmt.Errorf("invalid boolean value: %s", value)
+				return nil, fmt.Errorf("invalid unsigned integer value: %s", v)
 			}
-			return fmt.Sprintf("%s = ?", key), boolVal, nil
+			values[i] = n
+		case "int", "int32", "int64":
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer value: %s", v)
+			}
+			values[i] = n
+		case "float32", "float64":
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number value: %s", v)
+			}
+			values[i] = n
 		default:
-			return "", nil, fmt.Errorf("unsupported bool compare: %s", compare)
+			return nil, fmt.Errorf("unsupported type: %s", fieldType)
 		}
+	}
+	return values, nil
+}
 
-	case "Time":
-		if datePattern.MatchString(value) {
-			t, err := time.Parse(DATE_LAYOUT, value)
+// parseTimeValues разбирает даты (YYYY-MM-DD) или время (HH:MM[:SS]). Все значения должны быть
+// одного вида, колонка приводится к ::date или ::time
+func (s *FilterBuilder) parseTimeValues(column, value, compare string) (string, []interface{}, error) {
+	raw, err := valueList(value, compare)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var cast string
+	values := make([]interface{}, len(raw))
+	for i, v := range raw {
+		switch {
+		case datePattern.MatchString(v):
+			t, err := s.paramsParser.ParseDateString(v)
 			if err != nil {
 				return "", nil, fmt.Errorf("invalid date: %v", err)
 			}
-			if compare == "eq" {
-				return fmt.Sprintf("%s::date = ?", key), t, nil
+			if cast == "time" {
+				return "", nil, errors.New("cannot mix date and time values")
 			}
-			return "", nil, fmt.Errorf("only 'eq' supported for date, got: %s", compare)
-
-		} else if timePattern.MatchString(value) {
-			t, err := time.Parse(TIME_LAYOUT, value)
+			cast, values[i] = "date", t
+		case timePattern.MatchString(v):
+			if len(v) == len("15:04") {
+				v += ":00"
+			}
+			t, err := s.paramsParser.ParseTimeString(v)
 			if err != nil {
 				return "", nil, fmt.Errorf("invalid time: %v", err)
-
 			}
-			if compare == "eq" {
-				return fmt.Sprintf("%s::time = ?", key), t.Format("15:04:05"), nil
+			if cast == "date" {
+				return "", nil, errors.New("cannot mix date and time values")
 			}
-			return "", nil, fmt.Errorf("only 'eq' supported for time, got: %s", compare)
-
-		} else {
-			return "", nil, fmt.Errorf("unrecognized time/date format: %s", value)
+			cast, values[i] = "time", s.paramsParser.FormatTimeToString(t)
+		default:
+			return "", nil, fmt.Errorf("unrecognized time/date format: %s", v)
 		}
+	}
+
+	return fmt.Sprintf("%s::%s", column, cast), values, nil
+}
 
+// valueList снимает экранирование и для in/nin/between разделяет значение по запятым
+func valueList(value, compare string) ([]string, error) {
+	switch compare {
+	case "in", "nin", "between":
 	default:
-		return "", nil, fmt.Errorf("unsupported type: %s", fieldType)
+		return []string{unescape(value)}, nil
 	}
-}
 
-// ParseOrderString - создает отдельное условие SQL сортировки
-func (s *FilterBuilder) ParseOrderString(orderStr string, modelFields map[string]string) (string, error) {
-	if len(orderStr) == 0 {
-		return "", nil
+	items := splitUnescaped(value, ',')
+	if compare == "between" && len(items) != 2 {
+		return nil, fmt.Errorf("between expects 2 values, got %d", len(items))
+	}
+	if len(items) > maxFilterListLength {
+		return nil, fmt.Errorf("too many values in list, max %d", maxFilterListLength)
 	}
 
-	pairs := strings.Split(orderStr, "&$")
-	if len(pairs) < 1 {
-		return "", fmt.Errorf("count order parameters must be greater than 0, order string: %s", orderStr)
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = unescape(item)
 	}
+	return values, nil
+}
 
-	var query string
+// splitUnescaped делит строку по sep, пропуская экранированные символы. Экранирование не снимается
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case filterEscape:
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
 
-	for i, pair := range pairs {
-		kv := strings.Split(pair, ".")
-		if len(kv) != 2 {
-			return "", fmt.Errorf("invalid order format: %s, expected 'key.value'", pair)
+// unescape снимает экранирование: \x -> x
+func unescape(s string) string {
+	if !strings.ContainsRune(s, filterEscape) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == filterEscape && i+1 < len(s) {
+			i++
 		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
 
-		field := kv[0]
+// escapeLike экранирует спецсимволы ILIKE, чтобы like искал подстроку буквально
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
 
-		if _, ok := modelFields[field]; !ok {
-			return "", fmt.Errorf("invalid order key: %s", field)
+// ParseOrderString - разбирает строку сортировки вида "birth_date.desc&$full_name.asc" в колонки SQL
+func (s *FilterBuilder) ParseOrderString(orderStr string, modelFields map[string]models.FilterField) ([]models.OrderColumn, error) {
+	if len(orderStr) == 0 {
+		return nil, nil
+	}
+
+	pairs := strings.Split(orderStr, filterAnd)
+	if len(pairs) > maxFilterConditions {
+		return nil, fmt.Errorf("too many order parameters, max %d", maxFilterConditions)
+	}
+
+	columns := make([]models.OrderColumn, 0, len(pairs))
+	seen := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		// Направление — последний сегмент, поле может быть вложенным (policy.number.asc)
+		i := strings.LastIndex(pair, ".")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid order format: %s, expected 'key.value'", pair)
 		}
+		key, direction := pair[:i], pair[i+1:]
 
-		if kv[1] != "desc" && kv[1] != "asc" {
-			return "", fmt.Errorf("invalid order parameter: %s", kv[1])
+		field, ok := modelFields[key]
+		if !ok {
+			return nil, fmt.Errorf("invalid order key: %s", key)
 		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate order key: %s", key)
+		}
+		seen[key] = true
 
-		// Комбинируем условия
-		if i+1 < len(pairs) {
-			query += fmt.Sprintf("%s %s, ", field, kv[1]) // Если это не первый и не последний элемент, то добавляем запятую
-		} else {
-			query += fmt.Sprintf("%s %s ", field, kv[1]) // Если это первый или последний фильтр, то запятая не нужна
+		if direction != "desc" && direction != "asc" {
+			return nil, fmt.Errorf("invalid order parameter: %s", direction)
 		}
+
+		columns = append(columns, models.OrderColumn{Column: field.Column, Desc: direction == "desc"})
 	}
 
-	return query, nil
+	return columns, nil
 }
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+)
+
+var testFilterFields = map[string]models.FilterField{
+	"full_name":     {Column: "full_name", Type: "string"},
+	"age":           {Column: "age", Type: "int"},
+	"version":       {Column: "version", Type: "uint"},
+	"weight":        {Column: "weight", Type: "float64"},
+	"is_male":       {Column: "is_male", Type: "bool"},
+	"birth_date":    {Column: "birth_date", Type: "Time"},
+	"policy.number": {Column: "policy_number", Type: "string"},
+	"policy.type":   {Column: "policy_type", Type: "string"},
+}
+
+// sqlKeywords — всё, кроме колонок, что построитель может вставить в текст условия
+var sqlKeywords = map[string]bool{
+	"(": true, ")": true, "?": true,
+	"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true,
+	"IN": true, "BETWEEN": true, "ILIKE": true,
+	"=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true,
+}
+
+func newTestFilterBuilder() *FilterBuilder {
+	return &FilterBuilder{paramsParser: NewParamsParser()}
+}
+
+func TestParseFilterString(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		query  string
+		params []interface{}
+	}{
+		{
+			name:   "eq",
+			filter: "full_name.eq.Иванов",
+			query:  "full_name = ?",
+			params: []interface{}{"Иванов"},
+		},
+		{
+			name:   "and",
+			filter: "age.gt.25&$is_male.eq.true",
+			query:  "age > ? AND is_male = ?",
+			params: []interface{}{int64(25), true},
+		},
+		{
+			name:   "or in parentheses",
+			filter: "full_name.eq.A&$(age.lt.10|age.gte.60)",
+			query:  "full_name = ? AND ((age < ? OR age >= ?))",
+			params: []interface{}{"A", int64(10), int64(60)},
+		},
+		{
+			name:   "nested parentheses",
+			filter: "((age.eq.1|age.eq.2)&$version.ne.3)",
+			query:  "(((age = ? OR age = ?)) AND version <> ?)",
+			params: []interface{}{int64(1), int64(2), uint64(3)},
+		},
+		{
+			name:   "in list",
+			filter: "age.in.10,20,30",
+			query:  "age IN ?",
+			params: []interface{}{[]interface{}{int64(10), int64(20), int64(30)}},
+		},
+		{
+			name:   "nin list of strings with escaped comma",
+			filter: `full_name.nin.a\,b,c`,
+			query:  "full_name NOT IN ?",
+			params: []interface{}{[]interface{}{"a,b", "c"}},
+		},
+		{
+			name:   "between numbers",
+			filter: "weight.between.50.5,80",
+			query:  "weight BETWEEN ? AND ?",
+			params: []interface{}{50.5, float64(80)},
+		},
+		{
+			name:   "between dates",
+			filter: "birth_date.between.1980-01-01,1990-12-31",
+			query:  "birth_date::date BETWEEN ? AND ?",
+		},
+		{
+			name:   "nested field with dotted value",
+			filter: "policy.number.eq.12.34.56",
+			query:  "policy_number = ?",
+			params: []interface{}{"12.34.56"},
+		},
+		{
+			name:   "escaped separators in value",
+			filter: `full_name.eq.a\|b\&$c\(d\)`,
+			query:  "full_name = ?",
+			params: []interface{}{"a|b&$c(d)"},
+		},
+		{
+			name:   "like escapes wildcards",
+			filter: "full_name.like.50%_off",
+			query:  "full_name ILIKE ?",
+			params: []interface{}{`%50\%\_off%`},
+		},
+		{
+			name:   "null",
+			filter: "policy.type.ne.NULL",
+			query:  "policy_type IS NOT NULL",
+		},
+	}
+
+	b := newTestFilterBuilder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, params, err := b.ParseFilterString(tt.filter, testFilterFields)
+			if err != nil {
+				t.Fatalf("ParseFilterString(%q) error: %v", tt.filter, err)
+			}
+			if query != tt.query {
+				t.Errorf("query = %q, want %q", query, tt.query)
+			}
+			if tt.params != nil && !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("params = %#v, want %#v", params, tt.params)
+			}
+		})
+	}
+}
+
+func TestParseFilterStringErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+	}{
+		{"empty", ""},
+		{"unknown field", "password.eq.x"},
+		{"unknown operator", "age.like.1"},
+		{"operator not allowed for type", "is_male.gt.true"},
+		{"missing value", "age.eq"},
+		{"invalid integer", "age.eq.1;DROP TABLE users"},
+		{"unbalanced open", "(age.eq.1"},
+		{"unbalanced close", "age.eq.1)"},
+		{"unescaped open", "full_name.eq.a(b"},
+		{"empty condition", "age.eq.1|"},
+		{"between needs two values", "age.between.1,2,3"},
+		{"dangling escape", `full_name.eq.a\`},
+		{"mixed date and time", "birth_date.between.2000-01-01,10:00"},
+		{"too deep", "((((((age.eq.1))))))"},
+	}
+
+	b := newTestFilterBuilder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if query, _, err := b.ParseFilterString(tt.filter, testFilterFields); err == nil {
+				t.Errorf("ParseFilterString(%q) = %q, want error", tt.filter, query)
+			}
+		})
+	}
+}
+
+func TestParseOrderString(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   string
+		want    []models.OrderColumn
+		wantErr bool
+	}{
+		{name: "empty", order: ""},
+		{
+			name:  "two columns",
+			order: "birth_date.desc&$full_name.asc",
+			want:  []models.OrderColumn{{Column: "birth_date", Desc: true}, {Column: "full_name"}},
+		},
+		{
+			name:  "nested field",
+			order: "policy.number.asc",
+			want:  []models.OrderColumn{{Column: "policy_number"}},
+		},
+		{name: "unknown field", order: "password.asc", wantErr: true},
+		{name: "unknown direction", order: "age.up", wantErr: true},
+		{name: "no direction", order: "age", wantErr: true},
+		{name: "duplicate field", order: "age.asc&$age.desc", wantErr: true},
+	}
+
+	b := newTestFilterBuilder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := b.ParseOrderString(tt.order, testFilterFields)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseOrderString(%q) = %v, want error", tt.order, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOrderString(%q) error: %v", tt.order, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOrderString(%q) = %v, want %v", tt.order, got, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzParseFilterString проверяет, что в SQL попадают только колонки из modelFields и ключевые слова
+// построителя, а всё, что пришло из строки фильтра, передаётся параметрами
+func FuzzParseFilterString(f *testing.F) {
+	seeds := []string{
+		"full_name.eq.Иванов",
+		"age.gt.25&$is_male.eq.true",
+		"full_name.eq.A&$(age.lt.10|age.gte.60)",
+		"((age.eq.1|age.eq.2)&$version.ne.3)",
+		"age.in.10,20,30",
+		`full_name.nin.a\,b,c`,
+		"weight.between.50.5,80",
+		"birth_date.between.1980-01-01,1990-12-31",
+		"birth_date.gte.08:30",
+		"policy.number.eq.12.34.56",
+		`full_name.eq.a\|b\&$c\(d\)`,
+		`full_name.eq.x'\)\|1=1--`,
+		"full_name.eq.' OR '1'='1",
+		`full_name.like.50%_off\\`,
+		"policy.type.ne.NULL",
+		"full_name.eq.a) OR (1=1",
+		"age.eq.1|full_name.eq.;DROP TABLE users",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	columns := make(map[string]bool, len(testFilterFields))
+	for _, field := range testFilterFields {
+		columns[field.Column] = true
+	}
+
+	b := newTestFilterBuilder()
+	f.Fuzz(func(t *testing.T, filter string) {
+		query, params, err := b.ParseFilterString(filter, testFilterFields)
+		if err != nil {
+			return
+		}
+
+		tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(query))
+		for _, token := range tokens {
+			if sqlKeywords[token] {
+				continue
+			}
+			column := strings.TrimSuffix(strings.TrimSuffix(token, "::date"), "::time")
+			if !columns[column] {
+				t.Fatalf("filter %q produced unexpected SQL token %q in %q", filter, token, query)
+			}
+		}
+
+		if placeholders := strings.Count(query, "?"); placeholders != len(params) {
+			t.Fatalf("filter %q: %d placeholders but %d params in %q", filter, placeholders, len(params), query)
+		}
+	})
+}
@@ -9,19 +9,21 @@ import (
 )
 
 type Client struct {
-	userID uint
-	conn   *websocket.Conn
-	send   chan models.Message
+	userID    uint
+	sessionID string // Сеанс входа, при его отзыве соединение закрывается
+	conn      *websocket.Conn
+	send      chan models.Message
 
 	logger *log.Logger
 }
 
-func NewClient(conn *websocket.Conn, logger *log.Logger, userID uint) *Client {
+func NewClient(conn *websocket.Conn, logger *log.Logger, userID uint, sessionID string) *Client {
 	return &Client{
-		userID: userID,
-		conn:   conn,
-		send:   make(chan models.Message, 256),
-		logger: logger,
+		userID:    userID,
+		sessionID: sessionID,
+		conn:      conn,
+		send:      make(chan models.Message, 256),
+		logger:    logger,
 	}
 }
 
@@ -10,23 +10,41 @@ import (
 )
 
 type Hub struct {
-	clients    map[uint]*Client
-	broadcast  chan models.Message
-	register   chan *Client
-	unregister chan *Client
-	mutex      sync.Mutex
-
-	logger *log.Logger
+	// У пользователя может быть несколько подключений — по одному на устройство
+	clients      map[uint]map[*Client]bool
+	broadcast    chan models.Message
+	register     chan *Client
+	unregister   chan *Client
+	closeSession chan string
+	mutex        sync.Mutex
+
+	logger   *log.Logger
+	upgrader websocket.Upgrader
 }
 
-func NewHub(logger *log.Logger) *Hub {
+// NewHub создаёт хаб. Соединения из браузера принимаются только с allowedOrigins:
+// иначе любой сайт с украденным токеном мог бы подписаться на уведомления
+func NewHub(logger *log.Logger, allowedOrigins []string) *Hub {
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origins[origin] = true
+	}
+
 	return &Hub{
-		broadcast:  make(chan models.Message),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		clients:    make(map[uint]*Client),
+		broadcast:    make(chan models.Message),
+		register:     make(chan *Client),
+		unregister:   make(chan *Client),
+		closeSession: make(chan string),
+		clients:      make(map[uint]map[*Client]bool),
 
 		logger: logger,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				// Мобильные приложения не передают Origin
+				origin := r.Header.Get("Origin")
+				return origin == "" || origins[origin]
+			},
+		},
 	}
 }
 
@@ -35,23 +53,37 @@ func (h *Hub) run() {
 		select {
 		case client := <-h.register:
 			h.mutex.Lock()
-			h.clients[client.userID] = client
+			if h.clients[client.userID] == nil {
+				h.clients[client.userID] = make(map[*Client]bool)
+			}
+			h.clients[client.userID][client] = true
 			h.mutex.Unlock()
 		case client := <-h.unregister:
 			h.mutex.Lock()
-			if _, ok := h.clients[client.userID]; ok {
-				delete(h.clients, client.userID)
-				close(client.send)
+			h.removeClient(client)
+			h.mutex.Unlock()
+		case sessionID := <-h.closeSession:
+			h.mutex.Lock()
+			for _, clients := range h.clients {
+				for client := range clients {
+					if client.sessionID == sessionID {
+						h.removeClient(client)
+					}
+				}
 			}
 			h.mutex.Unlock()
 		case message := <-h.broadcast:
 			h.mutex.Lock()
-			for _, client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client.userID)
+			for userID, clients := range h.clients {
+				if !isRecipient(message, userID) {
+					continue
+				}
+				for client := range clients {
+					select {
+					case client.send <- message:
+					default:
+						h.removeClient(client)
+					}
 				}
 			}
 			h.mutex.Unlock()
@@ -59,21 +91,29 @@ func (h *Hub) run() {
 	}
 }
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // В продакшене следует проверять origin!
-	},
+// removeClient закрывает канал отправки клиента, writePump после этого закрывает соединение.
+// Вызывается под h.mutex
+func (h *Hub) removeClient(client *Client) {
+	clients, ok := h.clients[client.userID]
+	if !ok || !clients[client] {
+		return
+	}
+	delete(clients, client)
+	close(client.send)
+	if len(clients) == 0 {
+		delete(h.clients, client.userID)
+	}
 }
 
-func (h *Hub) ServeRegister(w http.ResponseWriter, r *http.Request, userId uint) error {
-	conn, err := upgrader.Upgrade(w, r, nil)
+func (h *Hub) ServeRegister(w http.ResponseWriter, r *http.Request, userId uint, sessionID string) error {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		h.logger.Printf("cant upgrade request to ws: %s", err)
 		return err
 	}
 	h.logger.Printf("upgrade to websocket")
 
-	client := NewClient(conn, h.logger, userId)
+	client := NewClient(conn, h.logger, userId, sessionID)
 
 	h.register <- client
 
@@ -87,15 +127,52 @@ func (h *Hub) ServeRegister(w http.ResponseWriter, r *http.Request, userId uint)
 }
 
 func (h *Hub) ServeUnregister(w http.ResponseWriter, r *http.Request, userId uint) {
-	if _, ok := h.clients[userId]; ok {
-		h.unregister <- h.clients[userId]
+	h.mutex.Lock()
+	clients := make([]*Client, 0, len(h.clients[userId]))
+	for client := range h.clients[userId] {
+		clients = append(clients, client)
+	}
+	h.mutex.Unlock()
+
+	for _, client := range clients {
+		h.unregister <- client
 	}
 }
 
+// CloseSession закрывает websocket-соединения отозванного сеанса входа
+func (h *Hub) CloseSession(sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	h.closeSession <- sessionID
+}
+
 func (h *Hub) AddBroadcastMessage(message models.Message) {
 	h.broadcast <- message
 }
 
+// SendToUsers отправляет сообщение только указанным пользователям
+func (h *Hub) SendToUsers(message models.Message, userIDs ...uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	message.GroupIDs = userIDs
+	h.broadcast <- message
+}
+
+// isRecipient — сообщение без GroupIDs получают все подключённые пользователи
+func isRecipient(message models.Message, userID uint) bool {
+	if len(message.GroupIDs) == 0 {
+		return true
+	}
+	for _, id := range message.GroupIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
 func InvokeHub(hub *Hub) {
 	go hub.run()
 }
@@ -0,0 +1,54 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+)
+
+type MedCardSyncWorker struct {
+	Usecase  interfaces.MedCardSyncUsecase
+	Interval time.Duration
+	Cancel   context.CancelFunc
+}
+
+func NewMedCardSyncWorker(usecase interfaces.MedCardSyncUsecase, interval time.Duration) *MedCardSyncWorker {
+	return &MedCardSyncWorker{
+		Usecase:  usecase,
+		Interval: interval,
+	}
+}
+
+// Start запускает воркер, который периодически отправляет в 1С офлайн-правки медкарт
+func (w *MedCardSyncWorker) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	w.Cancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+
+		log.Printf("[MedCardSync] started, interval = %v", w.Interval)
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.Usecase.ProcessPendingMedCardEdits(ctx); err != nil {
+					log.Printf("[MedCardSync] sync failed: %v", err)
+				}
+			case <-ctx.Done():
+				log.Println("[MedCardSync] stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Stop завершает работу воркера
+func (w *MedCardSyncWorker) Stop() {
+	if w.Cancel != nil {
+		w.Cancel()
+	}
+}
@@ -0,0 +1,60 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+)
+
+type PatientDuplicatesWorker struct {
+	Usecase  interfaces.PatientDuplicateUsecase
+	Interval time.Duration
+	Cancel   context.CancelFunc
+}
+
+func NewPatientDuplicatesWorker(usecase interfaces.PatientDuplicateUsecase, interval time.Duration) *PatientDuplicatesWorker {
+	return &PatientDuplicatesWorker{
+		Usecase:  usecase,
+		Interval: interval,
+	}
+}
+
+// Start запускает воркер, который периодически ищет дубли пациентов
+// и повторяет отправку в 1С объединений, не доставленных сразу
+func (w *PatientDuplicatesWorker) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	w.Cancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+
+		log.Printf("[PatientDuplicates] started, interval = %v", w.Interval)
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.Usecase.ReportPendingPatientMerges(ctx); err != nil {
+					log.Printf("[PatientDuplicates] merge report failed: %v", err)
+				}
+				if found, err := w.Usecase.ScanPatientDuplicates(ctx); err != nil {
+					log.Printf("[PatientDuplicates] scan failed: %v", err)
+				} else {
+					log.Printf("[PatientDuplicates] %d pairs pending review", found)
+				}
+			case <-ctx.Done():
+				log.Println("[PatientDuplicates] stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Stop завершает работу воркера
+func (w *PatientDuplicatesWorker) Stop() {
+	if w.Cancel != nil {
+		w.Cancel()
+	}
+}
@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+)
+
+type RecentPatientsPruneWorker struct {
+	Usecase  interfaces.QuickAccessUsecase
+	Interval time.Duration
+	Cancel   context.CancelFunc
+}
+
+func NewRecentPatientsPruneWorker(usecase interfaces.QuickAccessUsecase, interval time.Duration) *RecentPatientsPruneWorker {
+	return &RecentPatientsPruneWorker{
+		Usecase:  usecase,
+		Interval: interval,
+	}
+}
+
+// Start запускает воркер, который периодически удаляет просмотры пациентов старше срока хранения
+func (w *RecentPatientsPruneWorker) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	w.Cancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+
+		log.Printf("[RecentPatientsPrune] started, interval = %v", w.Interval)
+
+		for {
+			select {
+			case <-ticker.C:
+				if pruned, err := w.Usecase.PruneRecentPatients(ctx); err != nil {
+					log.Printf("[RecentPatientsPrune] prune failed: %v", err)
+				} else if pruned > 0 {
+					log.Printf("[RecentPatientsPrune] %d old views removed", pruned)
+				}
+			case <-ctx.Done():
+				log.Println("[RecentPatientsPrune] stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Stop завершает работу воркера
+func (w *RecentPatientsPruneWorker) Stop() {
+	if w.Cancel != nil {
+		w.Cancel()
+	}
+}
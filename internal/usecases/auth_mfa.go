@@ -0,0 +1,339 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/totp"
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	mfaTokenType      = "mfa"
+	totpSkew          = 1 // Допуск ±30 секунд на расхождение часов телефона
+	recoveryCodeCount = 10
+)
+
+// recoveryAlphabet — без похожих символов (0/O, 1/I)
+const recoveryAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// mfaRequired — требует ли роль второй фактор
+func (uc *AuthUsecase) mfaRequired(role entities.Role) bool {
+	for _, r := range uc.mfa.RequiredRoles {
+		if entities.Role(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// mfaSigningKey — MFA-токены подписываются отдельным ключом, чтобы их нельзя было
+// предъявить вместо access-токена
+func (uc *AuthUsecase) mfaSigningKey() []byte {
+	return []byte(uc.secretKey + ":" + mfaTokenType)
+}
+
+// issueMFAChallenge выдаёт короткоживущий MFA-токен вместо пары access/refresh.
+// Если роль требует MFA, а он не подключён, сразу начинается подключение
+func (uc *AuthUsecase) issueMFAChallenge(ctx context.Context, op string, user *entities.AuthUser, mfa *entities.UserMFA, deviceID string) (*models.DoctorAuthResponse, *errors.AppError) {
+	response := &models.DoctorAuthResponse{
+		ID:          user.ID,
+		Role:        user.Role,
+		MFARequired: true,
+	}
+
+	if !mfa.Confirmed() {
+		enrollment, err := uc.startEnrollment(ctx, user, mfa)
+		if err != nil {
+			return nil, errors.NewInternalError(op, "failed to start mfa enrollment", err)
+		}
+		response.MFAEnrollment = enrollment
+	}
+
+	jti, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to generate token id", err)
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID,
+		"typ":     mfaTokenType,
+		"jti":     jti.String(),
+		"dev":     deviceID,
+		"iat":     now.Unix(),
+		"exp":     now.Add(uc.mfa.ChallengeTTL).Unix(),
+	})
+	response.MFAToken, err = token.SignedString(uc.mfaSigningKey())
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to generate mfa token", err)
+	}
+	return response, nil
+}
+
+// startEnrollment создаёт секрет или возвращает уже выданный, но не подтверждённый
+func (uc *AuthUsecase) startEnrollment(ctx context.Context, user *entities.AuthUser, mfa *entities.UserMFA) (*models.MFAEnrollment, error) {
+	secret := ""
+	if mfa != nil {
+		secret = mfa.Secret
+	} else {
+		var err error
+		if secret, err = totp.GenerateSecret(); err != nil {
+			return nil, err
+		}
+		if err := uc.mfaRepo.SavePendingMFA(ctx, user.ID, secret); err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(uc.mfa.Issuer, user.Login, secret),
+	}, nil
+}
+
+// VerifyMFA — второй шаг входа: проверяет MFA-токен и код, выдаёт пару access/refresh.
+// Если MFA ещё не был подтверждён, правильный код завершает подключение и в ответе приходят коды восстановления
+func (uc *AuthUsecase) VerifyMFA(ctx context.Context, req models.MFAVerifyRequest) (*models.DoctorAuthResponse, *errors.AppError) {
+	op := "usecase.Auth.VerifyMFA"
+	now := time.Now()
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(req.MFAToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return uc.mfaSigningKey(), nil
+	})
+	jti, _ := claims["jti"].(string)
+	userID, _ := claims["user_id"].(float64)
+	if err != nil || claims["typ"] != mfaTokenType || jti == "" || userID <= 0 {
+		return nil, errors.NewUnauthorizedError(op, "invalid mfa token")
+	}
+
+	used, err := uc.tokens.IsAccessTokenRevoked(ctx, jti)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to check mfa token", err)
+	}
+	if used {
+		return nil, errors.NewUnauthorizedError(op, "mfa token already used")
+	}
+
+	user, err := uc.repo.GetUserByID(ctx, uint(userID))
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get user", err)
+	}
+	if user == nil || user.Disabled() {
+		return nil, errors.NewUnauthorizedError(op, "user not found or disabled")
+	}
+
+	attempt, retryAfter, err := uc.beginLoginAttempt(ctx, user.Login, req.ClientIP, now)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to check login lock", err)
+	}
+	if retryAfter > 0 {
+		return nil, errors.NewLoginLockedError(op, retryAfter)
+	}
+
+	mfa, err := uc.mfaRepo.GetUserMFA(ctx, user.ID)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get mfa", err)
+	}
+	if mfa == nil {
+		return nil, errors.NewUnauthorizedError(op, "mfa is not enrolled")
+	}
+
+	ok, err := uc.checkSecondFactor(ctx, mfa, req.Code, req.RecoveryCode, now)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to check mfa code", err)
+	}
+	if !ok {
+		uc.failLoginAttempt(ctx, attempt, now)
+		return nil, errors.NewUnauthorizedError(op, "invalid mfa code")
+	}
+
+	var recoveryCodes []string
+	if !mfa.Confirmed() {
+		if recoveryCodes, err = uc.confirmEnrollment(ctx, user.ID); err != nil {
+			return nil, errors.NewInternalError(op, "failed to confirm mfa", err)
+		}
+	}
+
+	// MFA-токен одноразовый
+	expiresAt := now.Add(uc.mfa.ChallengeTTL)
+	if exp, ok := claims["exp"].(float64); ok {
+		expiresAt = time.Unix(int64(exp), 0)
+	}
+	if err := uc.tokens.RevokeAccessToken(ctx, jti, expiresAt); err != nil {
+		return nil, errors.NewInternalError(op, "failed to revoke mfa token", err)
+	}
+	if err := uc.succeedLoginAttempt(ctx, attempt); err != nil {
+		return nil, errors.NewInternalError(op, "failed to reset login counter", err)
+	}
+
+	deviceID, _ := claims["dev"].(string)
+
+	response, appErr := uc.startSession(ctx, op, user, deviceID, req.ClientIP)
+	if appErr != nil {
+		return nil, appErr
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// checkSecondFactor проверяет код TOTP (однократно) или код восстановления.
+// Коды восстановления принимаются только для подтверждённого MFA
+func (uc *AuthUsecase) checkSecondFactor(ctx context.Context, mfa *entities.UserMFA, code, recoveryCode string, now time.Time) (bool, error) {
+	if code != "" {
+		step, ok := totp.Validate(mfa.Secret, code, now, totpSkew)
+		if !ok {
+			return false, nil
+		}
+		return uc.mfaRepo.UseTOTPStep(ctx, mfa.UserID, step)
+	}
+
+	if recoveryCode != "" && mfa.Confirmed() {
+		return uc.mfaRepo.UseRecoveryCode(ctx, mfa.UserID, hashToken(normalizeRecoveryCode(recoveryCode)))
+	}
+	return false, nil
+}
+
+// confirmEnrollment завершает подключение MFA и возвращает новые коды восстановления
+func (uc *AuthUsecase) confirmEnrollment(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+
+	if err := uc.mfaRepo.ConfirmMFA(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// EnrollMFA начинает добровольное подключение MFA для вошедшего пользователя
+func (uc *AuthUsecase) EnrollMFA(ctx context.Context, userID uint) (*models.MFAEnrollment, *errors.AppError) {
+	op := "usecase.Auth.EnrollMFA"
+
+	user, err := uc.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get user", err)
+	}
+	if user == nil {
+		return nil, errors.NewUnauthorizedError(op, "user not found")
+	}
+
+	mfa, err := uc.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get mfa", err)
+	}
+	if mfa.Confirmed() {
+		return nil, errors.NewAppError(errors.ConflictErrorCode, op+": mfa is already enabled", nil, true)
+	}
+
+	enrollment, err := uc.startEnrollment(ctx, user, mfa)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to start mfa enrollment", err)
+	}
+	return enrollment, nil
+}
+
+// ConfirmMFA подтверждает подключение кодом из приложения и выдаёт коды восстановления
+func (uc *AuthUsecase) ConfirmMFA(ctx context.Context, userID uint, req models.MFACodeRequest) ([]string, *errors.AppError) {
+	op := "usecase.Auth.ConfirmMFA"
+
+	mfa, err := uc.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get mfa", err)
+	}
+	if mfa == nil {
+		return nil, errors.NewAppError(errors.ConflictErrorCode, op+": mfa enrollment is not started", nil, true)
+	}
+	if mfa.Confirmed() {
+		return nil, errors.NewAppError(errors.ConflictErrorCode, op+": mfa is already enabled", nil, true)
+	}
+
+	ok, err := uc.checkSecondFactor(ctx, mfa, req.Code, "", time.Now())
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to check mfa code", err)
+	}
+	if !ok {
+		return nil, errors.NewUnauthorizedError(op, "invalid mfa code")
+	}
+
+	codes, err := uc.confirmEnrollment(ctx, userID)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to confirm mfa", err)
+	}
+	return codes, nil
+}
+
+// DisableMFA отключает MFA по коду из приложения или коду восстановления.
+// Для ролей, которым MFA обязателен, отключение запрещено
+func (uc *AuthUsecase) DisableMFA(ctx context.Context, userID uint, req models.MFACodeRequest) *errors.AppError {
+	op := "usecase.Auth.DisableMFA"
+
+	user, err := uc.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return errors.NewInternalError(op, "failed to get user", err)
+	}
+	if user == nil {
+		return errors.NewUnauthorizedError(op, "user not found")
+	}
+	if uc.mfaRequired(user.Role) {
+		return errors.NewForbiddenError(op, "mfa is required for role "+string(user.Role))
+	}
+
+	mfa, err := uc.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		return errors.NewInternalError(op, "failed to get mfa", err)
+	}
+	if !mfa.Confirmed() {
+		return errors.NewAppError(errors.ConflictErrorCode, op+": mfa is not enabled", nil, true)
+	}
+
+	ok, err := uc.checkSecondFactor(ctx, mfa, req.Code, req.RecoveryCode, time.Now())
+	if err != nil {
+		return errors.NewInternalError(op, "failed to check mfa code", err)
+	}
+	if !ok {
+		return errors.NewUnauthorizedError(op, "invalid mfa code")
+	}
+
+	if err := uc.mfaRepo.DeleteUserMFA(ctx, userID); err != nil {
+		return errors.NewInternalError(op, "failed to disable mfa", err)
+	}
+	return nil
+}
+
+// generateRecoveryCode — 10 символов вида XXXXX-XXXXX
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := make([]byte, 0, 11)
+	for i, b := range buf {
+		if i == 5 {
+			code = append(code, '-')
+		}
+		code = append(code, recoveryAlphabet[int(b)%len(recoveryAlphabet)])
+	}
+	return string(code), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -2,48 +2,495 @@ package usecases
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
 	"time"
 
+	"github.com/AlexanderMorozov1919/mobileapp/internal/config"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/services/websocket"
 	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+	"github.com/gofrs/uuid"
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthUsecase struct {
-	repo      interfaces.AuthRepository
-	secretKey string
+	repo       interfaces.AuthRepository
+	tokens     interfaces.TokenRepository
+	security   interfaces.SecurityRepository
+	mfaRepo    interfaces.MFARepository
+	sessions   interfaces.SessionRepository
+	doctors    interfaces.DoctorRepository
+	hub        *websocket.Hub
+	lockout    config.LockoutConfig
+	mfa        config.MFAConfig
+	secretKey  string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	// Размер пула горутин для bcrypt при синхронизации пользователей
+	hashWorkers int
 }
 
-func NewAuthUsecase(repo interfaces.Repository, secretKey string) *AuthUsecase {
+func NewAuthUsecase(repo interfaces.Repository, cfg *config.Config, hub *websocket.Hub) *AuthUsecase {
 	return &AuthUsecase{
-		repo:      repo,
-		secretKey: secretKey,
+		repo:       repo,
+		tokens:     repo,
+		security:   repo,
+		mfaRepo:    repo,
+		sessions:   repo,
+		doctors:    repo,
+		hub:        hub,
+		lockout:    cfg.Auth.Lockout,
+		mfa:        cfg.Auth.MFA,
+		secretKey:  cfg.JWTSecret,
+		accessTTL:  cfg.Auth.AccessTokenTTL,
+		refreshTTL: cfg.Auth.RefreshTokenTTL,
+
+		hashWorkers: cfg.Workers.PasswordHashWorkers,
+	}
+}
+
+// SyncUsers создаёт, обновляет и отключает пользователей по их ID в 1С.
+// Новые пользователи без роли получают роль врача. Открытые пароли хэшируются в пуле из hashWorkers горутин.
+// Отключённые пользователи сразу теряют доступ: их сеансы отзываются, а websocket-соединения закрываются
+func (u *AuthUsecase) SyncUsers(ctx context.Context, req models.UserSyncRequest) (models.UserSyncResult, error) {
+	now := time.Now()
+	users := make([]entities.AuthUser, len(req.Users))
+	seen := make(map[string]bool, len(req.Users))
+	for i, src := range req.Users {
+		if seen[src.ID] {
+			return models.UserSyncResult{}, &validation.Error{Field: fmt.Sprintf("users[%d].id", i), Message: "duplicate id " + src.ID}
+		}
+		seen[src.ID] = true
+
+		role := entities.Role(src.Role)
+		if role != "" && !role.Valid() {
+			return models.UserSyncResult{}, &validation.Error{Field: fmt.Sprintf("users[%d].role", i), Message: fmt.Sprintf("unknown role %q", role)}
+		}
+		if src.Password != "" && src.PasswordHash != "" {
+			return models.UserSyncResult{}, &validation.Error{Field: fmt.Sprintf("users[%d]", i), Message: "password and password_hash are mutually exclusive"}
+		}
+		if src.PasswordHash != "" {
+			if _, err := bcrypt.Cost([]byte(src.PasswordHash)); err != nil {
+				return models.UserSyncResult{}, &validation.Error{Field: fmt.Sprintf("users[%d].password_hash", i), Message: "must be a bcrypt hash"}
+			}
+		}
+
+		users[i] = entities.AuthUser{
+			OneCID:           src.ID,
+			Login:            src.Login,
+			Password:         src.PasswordHash,
+			Role:             role,
+			DoctorCertNumber: src.DoctorCertNumber,
+			UpdatedAt:        now,
+			Profile: &entities.DoctorProfile{
+				FullName:       src.FullName,
+				Specialization: src.Specialization,
+				Clinic:         src.Clinic,
+				Crew:           src.Crew,
+				UpdatedAt:      now,
+			},
+		}
+		if src.Disabled {
+			users[i].DisabledAt = &now
+		}
+	}
+
+	if err := u.hashPasswords(ctx, req.Users, users); err != nil {
+		return models.UserSyncResult{}, fmt.Errorf("failed to hash passwords: %w", err)
+	}
+
+	result, err := u.repo.SyncUsers(ctx, users, req.Full)
+	if err != nil {
+		return result, err
+	}
+	for _, sessionID := range result.RevokedSessionIDs {
+		u.hub.CloseSession(sessionID)
+	}
+	return result, nil
+}
+
+// hashPasswords хэширует открытые пароли src в users[i].Password. bcrypt нагружает CPU,
+// поэтому число одновременных хэширований ограничено
+func (u *AuthUsecase) hashPasswords(ctx context.Context, src []models.OneCUser, users []entities.AuthUser) error {
+	workers := u.hashWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				hash, err := bcrypt.GenerateFromPassword([]byte(src[i].Password), bcrypt.DefaultCost)
+				if err != nil {
+					errs <- err
+					return
+				}
+				users[i].Password = string(hash)
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := range src {
+		if src[i].Password == "" {
+			continue
+		}
+		select {
+		case jobs <- i:
+		case err = <-errs:
+			break feed
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	select {
+	case err = <-errs:
+		return err
+	default:
+		return nil
 	}
 }
 
-// SyncUsers заменяет весь список пользователей
-func (u *AuthUsecase) SyncUsers(ctx context.Context, users []entities.AuthUser) error {
-	return u.repo.SaveUsers(ctx, users)
+// SyncRolePermissions заменяет соответствие ролей и прав, присланное из 1С.
+// Новые права попадают в токены при следующем входе или обновлении токена.
+// Права из entities.PinnedRolePermissions сохраняются, даже если 1С их не прислала
+func (u *AuthUsecase) SyncRolePermissions(ctx context.Context, mapping map[entities.Role][]entities.Permission) error {
+	var rows []entities.RolePermission
+	seen := make(map[entities.RolePermission]bool)
+	for role, permissions := range mapping {
+		if !role.Valid() {
+			return &validation.Error{Field: "role", Message: fmt.Sprintf("unknown role %q", role)}
+		}
+		for _, perm := range permissions {
+			if !perm.Valid() {
+				return &validation.Error{Field: "permissions", Message: fmt.Sprintf("unknown permission %q", perm)}
+			}
+			row := entities.RolePermission{Role: role, Permission: perm}
+			if seen[row] {
+				continue
+			}
+			seen[row] = true
+			rows = append(rows, row)
+		}
+	}
+
+	for role, permissions := range entities.PinnedRolePermissions {
+		for _, perm := range permissions {
+			row := entities.RolePermission{Role: role, Permission: perm}
+			if !seen[row] {
+				seen[row] = true
+				rows = append(rows, row)
+			}
+		}
+	}
+	return u.repo.SaveRolePermissions(ctx, rows)
 }
 
-func (uc *AuthUsecase) LoginDoctor(ctx context.Context, phone, password string) (*models.DoctorAuthResponse, *errors.AppError) {
+// dummyPasswordHash — хэш, с которым сверяется пароль неизвестного или отключённого пользователя
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate dummy password hash: %v", err))
+	}
+	return hash
+})
+
+func (uc *AuthUsecase) LoginDoctor(ctx context.Context, req models.DoctorLoginRequest) (*models.DoctorAuthResponse, *errors.AppError) {
 	op := "usecase.Auth.LoginDoctor"
+	now := time.Now()
 
-	user, err := uc.repo.GetUserByLogin(ctx, phone)
-	if err != nil || user.ID == 0 {
-		return nil, errors.NewUnauthorizedError(op, "invalid credentials")
+	attempt, retryAfter, err := uc.beginLoginAttempt(ctx, req.Phone, req.ClientIP, now)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to check login lock", err)
+	}
+	if retryAfter > 0 {
+		return nil, errors.NewLoginLockedError(op, retryAfter)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	user, err := uc.repo.GetUserByLogin(ctx, req.Phone)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get user", err)
+	}
+
+	// Неизвестный логин считается так же, как неверный пароль, чтобы нельзя было перебирать логины.
+	// Пароль сверяется всегда, в том числе с фиктивным хэшем, чтобы время ответа не выдавало логин
+	hash := dummyPasswordHash()
+	if user != nil && user.ID != 0 && !user.Disabled() {
+		hash = []byte(user.Password)
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(req.Password)) != nil || user == nil || user.ID == 0 || user.Disabled() {
+		uc.failLoginAttempt(ctx, attempt, now)
 		return nil, errors.NewUnauthorizedError(op, "invalid credentials")
 	}
 
+	if err := uc.succeedLoginAttempt(ctx, attempt); err != nil {
+		return nil, errors.NewInternalError(op, "failed to reset login counter", err)
+	}
+
+	if err := uc.sessions.UpsertDevice(ctx, &entities.Device{
+		UserID:     user.ID,
+		DeviceID:   req.DeviceID,
+		Platform:   req.Platform,
+		AppVersion: req.AppVersion,
+		PushToken:  req.PushToken,
+		LastIP:     req.ClientIP,
+		LastSeenAt: now,
+	}); err != nil {
+		return nil, errors.NewInternalError(op, "failed to register device", err)
+	}
+
+	mfa, err := uc.mfaRepo.GetUserMFA(ctx, user.ID)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get mfa", err)
+	}
+	if mfa.Confirmed() || uc.mfaRequired(user.Role) {
+		return uc.issueMFAChallenge(ctx, op, user, mfa, req.DeviceID)
+	}
+
+	return uc.startSession(ctx, op, user, req.DeviceID, req.ClientIP)
+}
+
+// startSession открывает новый сеанс входа на устройстве и выпускает для него первую пару токенов
+func (uc *AuthUsecase) startSession(ctx context.Context, op string, user *entities.AuthUser, deviceID, ip string) (*models.DoctorAuthResponse, *errors.AppError) {
+	familyID, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to generate session id", err)
+	}
+
+	now := time.Now()
+	if err := uc.sessions.CreateSession(ctx, &entities.Session{
+		ID:         familyID.String(),
+		UserID:     user.ID,
+		DeviceID:   deviceID,
+		IP:         ip,
+		ExpiresAt:  now.Add(uc.refreshTTL),
+		LastUsedAt: now,
+	}); err != nil {
+		return nil, errors.NewInternalError(op, "failed to create session", err)
+	}
+
+	response, appErr := uc.issueTokens(ctx, op, user, familyID.String(), deviceID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if response.Doctor, err = uc.doctorInfo(ctx, user); err != nil {
+		return nil, errors.NewInternalError(op, "failed to get doctor profile", err)
+	}
+	return response, nil
+}
+
+// GetCurrentUser возвращает текущего пользователя с профилем из 1С
+func (uc *AuthUsecase) GetCurrentUser(ctx context.Context, userID uint) (*models.DoctorInfoResponse, *errors.AppError) {
+	op := "usecase.Auth.GetCurrentUser"
+
+	user, err := uc.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get user", err)
+	}
+	if user == nil || user.Disabled() {
+		return nil, errors.NewUnauthorizedError(op, "user not found or disabled")
+	}
+
+	info, err := uc.doctorInfo(ctx, user)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get doctor profile", err)
+	}
+	return info, nil
+}
+
+// doctorInfo собирает данные пользователя. Профиля может не быть, если 1С его ещё не прислала
+func (uc *AuthUsecase) doctorInfo(ctx context.Context, user *entities.AuthUser) (*models.DoctorInfoResponse, error) {
+	profile, err := uc.doctors.GetDoctorByID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &models.DoctorInfoResponse{
+		DoctorID: user.ID,
+		Login:    user.Login,
+		Role:     user.Role,
+	}
+	if profile != nil {
+		info.FullName = profile.FullName
+		info.Specialization = profile.Specialization
+		info.Clinic = profile.Clinic
+		info.Crew = profile.Crew
+	}
+	return info, nil
+}
+
+// RefreshTokens обменивает refresh-токен на новую пару токенов (ротация).
+// Повторное предъявление уже использованного токена отзывает всё семейство токенов
+func (uc *AuthUsecase) RefreshTokens(ctx context.Context, req models.RefreshTokenRequest) (*models.DoctorAuthResponse, *errors.AppError) {
+	op := "usecase.Auth.RefreshTokens"
+
+	stored, err := uc.tokens.GetRefreshTokenByHash(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get refresh token", err)
+	}
+	if stored == nil || stored.RevokedAt != nil {
+		return nil, errors.NewUnauthorizedError(op, "invalid refresh token")
+	}
+	if stored.UsedAt != nil {
+		return nil, uc.revokeReusedFamily(ctx, op, stored)
+	}
+	// Refresh-токен привязан к устройству: предъявление с другого устройства считается утечкой
+	if stored.DeviceID != req.DeviceID {
+		if appErr := uc.revokeSession(ctx, op, stored.FamilyID); appErr != nil {
+			return nil, appErr
+		}
+		return nil, errors.NewUnauthorizedError(op, "refresh token belongs to another device, session revoked")
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, errors.NewUnauthorizedError(op, "refresh token expired")
+	}
+
+	marked, err := uc.tokens.MarkRefreshTokenUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to rotate refresh token", err)
+	}
+	if !marked {
+		return nil, uc.revokeReusedFamily(ctx, op, stored)
+	}
+
+	user, err := uc.repo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get user", err)
+	}
+	if user == nil || user.Disabled() {
+		return nil, errors.NewUnauthorizedError(op, "user not found or disabled")
+	}
+
+	response, appErr := uc.issueTokens(ctx, op, user, stored.FamilyID, stored.DeviceID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if err := uc.sessions.TouchSession(ctx, stored.FamilyID, req.ClientIP, time.Unix(response.RefreshExpiresAt, 0)); err != nil {
+		return nil, errors.NewInternalError(op, "failed to update session", err)
+	}
+	return response, nil
+}
+
+// Logout отзывает текущий access-токен и весь сеанс входа
+func (uc *AuthUsecase) Logout(ctx context.Context, session models.TokenSession) *errors.AppError {
+	op := "usecase.Auth.Logout"
+
+	if session.FamilyID != "" {
+		if appErr := uc.revokeSession(ctx, op, session.FamilyID); appErr != nil {
+			return appErr
+		}
+	}
+	if session.JTI != "" {
+		if err := uc.tokens.RevokeAccessToken(ctx, session.JTI, session.ExpiresAt); err != nil {
+			return errors.NewInternalError(op, "failed to revoke access token", err)
+		}
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked проверяет jti по списку отозванных токенов и сеанс sid (используется middleware.JWTAuth)
+func (uc *AuthUsecase) IsAccessTokenRevoked(ctx context.Context, jti, sid string) (bool, error) {
+	revoked, err := uc.tokens.IsAccessTokenRevoked(ctx, jti)
+	if err != nil || revoked || sid == "" {
+		return revoked, err
+	}
+	return uc.sessions.IsSessionRevoked(ctx, sid)
+}
+
+// ListSessions возвращает активные сеансы пользователя, отмечая сеанс текущего запроса
+func (uc *AuthUsecase) ListSessions(ctx context.Context, userID uint, currentSID string) ([]models.SessionInfo, error) {
+	sessions, err := uc.sessions.GetActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSID
+	}
+	return sessions, nil
+}
+
+// RevokeSession удалённо завершает сеанс пользователя: отзывает его токены и закрывает websocket
+func (uc *AuthUsecase) RevokeSession(ctx context.Context, userID uint, sessionID string) *errors.AppError {
+	op := "usecase.Auth.RevokeSession"
+
+	session, err := uc.sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		return errors.NewInternalError(op, "failed to get session", err)
+	}
+	// Чужой сеанс не отличаем от несуществующего
+	if session == nil || session.UserID != userID {
+		return errors.NewAppError(errors.NotFoundErrorCode, op+": session not found", errors.ErrNotFound, true)
+	}
+	return uc.revokeSession(ctx, op, sessionID)
+}
+
+// revokeSession отзывает сеанс и все refresh-токены семейства. Access-токены сеанса
+// перестают приниматься сразу, так как middleware проверяет sid
+func (uc *AuthUsecase) revokeSession(ctx context.Context, op, sessionID string) *errors.AppError {
+	if err := uc.tokens.RevokeRefreshTokenFamily(ctx, sessionID); err != nil {
+		return errors.NewInternalError(op, "failed to revoke refresh tokens", err)
+	}
+	if err := uc.sessions.RevokeSession(ctx, sessionID); err != nil {
+		return errors.NewInternalError(op, "failed to revoke session", err)
+	}
+	uc.hub.CloseSession(sessionID)
+	return nil
+}
+
+func (uc *AuthUsecase) revokeReusedFamily(ctx context.Context, op string, token *entities.RefreshToken) *errors.AppError {
+	if appErr := uc.revokeSession(ctx, op, token.FamilyID); appErr != nil {
+		return appErr
+	}
+	return errors.NewUnauthorizedError(op, "refresh token reuse detected, session revoked")
+}
+
+// issueTokens выпускает короткоживущий access-токен и новый refresh-токен в семействе familyID
+func (uc *AuthUsecase) issueTokens(ctx context.Context, op string, user *entities.AuthUser, familyID, deviceID string) (*models.DoctorAuthResponse, *errors.AppError) {
+	now := time.Now()
+	accessExpiresAt := now.Add(uc.accessTTL)
+	refreshExpiresAt := now.Add(uc.refreshTTL)
+
+	permissions, err := uc.repo.GetRolePermissions(ctx, user.Role)
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to get role permissions", err)
+	}
+
+	jti, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to generate token id", err)
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+		"user_id":     user.ID,
+		"sid":         familyID,
+		"dev":         deviceID,
+		"jti":         jti.String(),
+		"role":        user.Role,
+		"permissions": permissions,
+		"iat":         now.Unix(),
+		"exp":         accessExpiresAt.Unix(),
 	})
 
 	tokenString, err := token.SignedString([]byte(uc.secretKey))
@@ -51,9 +498,43 @@ func (uc *AuthUsecase) LoginDoctor(ctx context.Context, phone, password string)
 		return nil, errors.NewInternalError(op, "failed to generate token", err)
 	}
 
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, errors.NewInternalError(op, "failed to generate refresh token", err)
+	}
+
+	if err := uc.tokens.SaveRefreshToken(ctx, &entities.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		DeviceID:  deviceID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: refreshExpiresAt,
+	}); err != nil {
+		return nil, errors.NewInternalError(op, "failed to save refresh token", err)
+	}
+
 	creditonalds := models.DoctorAuthResponse{
-		ID:    user.ID,
-		Token: tokenString,
+		ID:               user.ID,
+		Token:            tokenString,
+		ExpiresAt:        accessExpiresAt.Unix(),
+		Role:             user.Role,
+		Permissions:      permissions,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt.Unix(),
 	}
 	return &creditonalds, nil
 }
+
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken — в БД хранится только хэш refresh-токена
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
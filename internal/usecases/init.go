@@ -7,16 +7,26 @@ import (
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/config"
 	_ "github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/middleware/logging"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/services/websocket"
 	_ "github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"gorm.io/gorm/schema"
 )
 
 type UseCases struct {
 	interfaces.MedCardUsecase
+	interfaces.MedCardSyncUsecase
+	interfaces.PatientAccessUsecase
 	interfaces.AuthUsecase
 	interfaces.OneCWebhookUsecase
 	interfaces.OneCPatientUsecase
+	interfaces.ReceptionSmpUsecase
+	interfaces.SyncUsecase
+	interfaces.PatientDuplicateUsecase
+	interfaces.QuickAccessUsecase
+	interfaces.PatientCodeUsecase
 }
 
 func NewUsecases(
@@ -25,22 +35,31 @@ func NewUsecases(
 	conf *config.Config,
 	hub *websocket.Hub,
 	onecClient interfaces.OneCClient,
+	geocoder interfaces.Geocoder,
+	logger *logging.Logger,
 ) interfaces.Usecases {
+	medCard := NewMedCardUsecase(r, onecClient, r, geocoder, conf.Workers.MedCardFetchWorkers)
 
 	return &UseCases{
-		NewMedCardUsecase(r, onecClient, r),
-		NewAuthUsecase(r, conf.JWTSecret),
-		NewOneCWebhookUsecase(r, hub),
-		NewOneCPatientListUsecase(r, onecClient),
+		medCard,
+		NewMedCardSyncUsecase(r, medCard),
+		NewPatientAccessUsecase(r, onecClient, hub, conf.Auth.EmergencyAccessTTL),
+		NewAuthUsecase(r, conf, hub),
+		NewOneCWebhookUsecase(r, hub, logger),
+		NewOneCPatientListUsecase(r, onecClient, s),
+		NewReceptionSmpUsecase(r),
+		NewSyncUsecase(r),
+		NewPatientDuplicateUsecase(r, r, onecClient),
+		NewQuickAccessUsecase(r, conf.Patients.RecentRetention, conf.Patients.RecentMax),
+		NewPatientCodeUsecase(r, conf.Patients.QRSecret),
 	}
 
 }
 
 // getFieldTypes возвращает карту, где ключ — это имя поля (по JSON-тегу),
-// а значение — тип данных поля.
-func getFieldTypes(model interface{}) (map[string]string, error) {
-	result := make(map[string]string)
-
+// а значение — колонка SQL и тип данных поля.
+// Встроенные структуры (gorm:"embedded") дают вложенные ключи: policy.number -> policy_number
+func getFieldTypes(model interface{}) (map[string]models.FilterField, error) {
 	// Получаем тип модели
 	t := reflect.TypeOf(model)
 	if t.Kind() == reflect.Ptr {
@@ -52,6 +71,12 @@ func getFieldTypes(model interface{}) (map[string]string, error) {
 		return nil, fmt.Errorf("expected a struct, got %s", t.Kind())
 	}
 
+	result := make(map[string]models.FilterField)
+	collectFieldTypes(t, "", "", result)
+	return result, nil
+}
+
+func collectFieldTypes(t reflect.Type, keyPrefix, columnPrefix string, result map[string]models.FilterField) {
 	// Итерируемся по полям структуры
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
@@ -73,9 +98,24 @@ func getFieldTypes(model interface{}) (map[string]string, error) {
 			fieldType = fieldType.Elem()
 		}
 
+		gormTag := schema.ParseTagSetting(field.Tag.Get("gorm"), ";")
+		if _, embedded := gormTag["EMBEDDED"]; embedded && fieldType.Kind() == reflect.Struct {
+			collectFieldTypes(fieldType, keyPrefix+jsonName+".", columnPrefix+gormTag["EMBEDDEDPREFIX"], result)
+			continue
+		}
+
+		column := gormTag["COLUMN"]
+		if column == "" {
+			column = columnNaming.ColumnName("", field.Name)
+		}
+
 		// Добавляем поле и его тип в карту
-		result[jsonName] = fieldType.Name()
+		result[keyPrefix+jsonName] = models.FilterField{
+			Column: columnPrefix + column,
+			Type:   fieldType.Name(),
+		}
 	}
-
-	return result, nil
 }
+
+// columnNaming — те же имена колонок, что создаёт gorm
+var columnNaming = schema.NamingStrategy{}
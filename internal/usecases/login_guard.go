@@ -0,0 +1,165 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+)
+
+// loginKey нормализует телефон, чтобы "+7 999…" и "8999…" попадали в один счётчик.
+// Логины, которые не являются телефонами, используются как есть
+func loginKey(login string) string {
+	if phone, err := validation.NormalizePhone(login); err == nil {
+		login = phone
+	}
+	return "login:" + login
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// loginAttempt — попытка входа, засчитанная в счётчиках логина и IP до проверки пароля
+type loginAttempt struct {
+	login    string
+	ip       string
+	counters []entities.LoginAttemptCounter
+}
+
+// beginLoginAttempt атомарно проверяет блокировки логина и IP и засчитывает попытку.
+// Возвращает время до снятия блокировки (0 — попытка разрешена). Ключ, заблокированный
+// этой попыткой, остаётся заблокированным для параллельных попыток до её завершения
+func (uc *AuthUsecase) beginLoginAttempt(ctx context.Context, login, ip string, now time.Time) (*loginAttempt, time.Duration, error) {
+	windowStart := now.Add(-uc.lockout.FailureWindow)
+
+	type limit struct {
+		key  string
+		free int
+	}
+	limits := []limit{{loginKey(login), uc.lockout.FreeAttempts}}
+	if ip != "" {
+		limits = append(limits, limit{ipKey(ip), uc.lockout.IPFreeAttempts})
+	}
+
+	attempt := &loginAttempt{login: login, ip: ip}
+	for _, l := range limits {
+		counter, allowed, err := uc.security.ReserveLoginAttempt(ctx, l.key, now, windowStart, l.free, uc.backoff)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !allowed {
+			// Попытка по уже засчитанным ключам остаётся засчитанной: вход всё равно не состоялся
+			uc.recordSecurityEvent(ctx, entities.SecurityEvent{
+				Type:  entities.SecurityEventLoginBlocked,
+				Login: login,
+				IP:    ip,
+			})
+			return nil, counter.LockedUntil.Sub(now), nil
+		}
+		attempt.counters = append(attempt.counters, counter)
+	}
+	return attempt, 0, nil
+}
+
+// failLoginAttempt фиксирует неудачную попытку в журнале безопасности
+func (uc *AuthUsecase) failLoginAttempt(ctx context.Context, attempt *loginAttempt, now time.Time) {
+	for _, counter := range attempt.counters {
+		if counter.LockedUntil == nil {
+			continue
+		}
+		uc.recordSecurityEvent(ctx, entities.SecurityEvent{
+			Type:    entities.SecurityEventLoginLocked,
+			Login:   attempt.login,
+			IP:      attempt.ip,
+			Details: fmt.Sprintf("%s locked for %s after %d failures", counter.Key, counter.LockedUntil.Sub(now).Round(time.Second), counter.Failures),
+		})
+	}
+
+	uc.recordSecurityEvent(ctx, entities.SecurityEvent{
+		Type:  entities.SecurityEventLoginFailed,
+		Login: attempt.login,
+		IP:    attempt.ip,
+	})
+}
+
+// succeedLoginAttempt обнуляет счётчик логина и возвращает попытку в счётчик IP
+func (uc *AuthUsecase) succeedLoginAttempt(ctx context.Context, attempt *loginAttempt) error {
+	for _, counter := range attempt.counters {
+		if counter.Key == loginKey(attempt.login) {
+			if _, err := uc.security.ResetLoginCounter(ctx, counter.Key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := uc.security.ReleaseLoginAttempt(ctx, counter.Key, counter.LockedUntil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backoff — BackoffBase * 2^step, но не больше MaxLockout
+func (uc *AuthUsecase) backoff(step int) time.Duration {
+	delay := uc.lockout.BackoffBase
+	for i := 0; i < step && delay < uc.lockout.MaxLockout; i++ {
+		delay *= 2
+	}
+	if delay > uc.lockout.MaxLockout {
+		delay = uc.lockout.MaxLockout
+	}
+	return delay
+}
+
+// recordSecurityEvent — журнал не должен ломать вход, поэтому ошибка записи игнорируется
+func (uc *AuthUsecase) recordSecurityEvent(ctx context.Context, event entities.SecurityEvent) {
+	_ = uc.security.SaveSecurityEvent(ctx, &event)
+}
+
+// UnlockLogin снимает блокировку входа по логину и/или IP (действие администратора)
+func (uc *AuthUsecase) UnlockLogin(ctx context.Context, adminID uint, req models.UnlockLoginRequest) error {
+	if req.Login == "" && req.IP == "" {
+		return &validation.Error{Field: "login", Message: "login or ip is required"}
+	}
+
+	var keys []string
+	if req.Login != "" {
+		keys = append(keys, loginKey(req.Login))
+	}
+	if req.IP != "" {
+		keys = append(keys, ipKey(req.IP))
+	}
+
+	unlocked := false
+	for _, key := range keys {
+		removed, err := uc.security.ResetLoginCounter(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to unlock %s: %w", key, err)
+		}
+		unlocked = unlocked || removed
+	}
+	if !unlocked {
+		return errors.NewNotFoundError("no failed login attempts for given login or ip")
+	}
+
+	uc.recordSecurityEvent(ctx, entities.SecurityEvent{
+		Type:    entities.SecurityEventLoginUnlocked,
+		Login:   req.Login,
+		IP:      req.IP,
+		UserID:  &adminID,
+		Details: "unlocked by administrator",
+	})
+	return nil
+}
+
+func (uc *AuthUsecase) GetSecurityEvents(ctx context.Context, filter models.SecurityEventFilter) ([]entities.SecurityEvent, error) {
+	events, err := uc.security.GetSecurityEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get security events: %w", err)
+	}
+	return events, nil
+}
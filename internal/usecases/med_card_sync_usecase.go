@@ -0,0 +1,193 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/mergepatch"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+)
+
+// pendingEditsBatch — сколько правок обрабатывается за один проход воркера
+const pendingEditsBatch = 100
+
+// Повторная отправка правки при недоступности 1С: через 30 с, 1 мин, 2 мин и так далее, но не реже раза в час.
+// После editMaxAttempts неудачных попыток (около суток) правка отклоняется
+const (
+	editRetryBase   = 30 * time.Second
+	editRetryMax    = time.Hour
+	editMaxAttempts = 30
+)
+
+// editLease — сколько захваченная правка недоступна другим репликам. Должно хватать на запросы к 1С
+const editLease = 5 * time.Minute
+
+type MedCardSyncUsecase struct {
+	repo  interfaces.MedCardEditRepository
+	cards interfaces.MedCardUsecase
+}
+
+func NewMedCardSyncUsecase(
+	repo interfaces.MedCardEditRepository,
+	cards interfaces.MedCardUsecase,
+) interfaces.MedCardSyncUsecase {
+	return &MedCardSyncUsecase{
+		repo:  repo,
+		cards: cards,
+	}
+}
+
+// EnqueueMedCardEdits ставит офлайн-правки в очередь, в 1С их отправляет воркер.
+// Повторная отправка правки с тем же client_edit_id возвращает её текущий статус
+func (u *MedCardSyncUsecase) EnqueueMedCardEdits(ctx context.Context, userID uint, requests []models.MedCardEditRequest) ([]entities.MedCardEdit, error) {
+	now := time.Now()
+	edits := make([]entities.MedCardEdit, 0, len(requests))
+	clientIDs := make([]string, 0, len(requests))
+	for _, req := range requests {
+		edits = append(edits, entities.MedCardEdit{
+			ClientEditID:    req.ClientEditID,
+			UserID:          userID,
+			PatientID:       req.PatientID,
+			BaseVersion:     req.BaseVersion,
+			Patch:           req.Patch,
+			ClientTimestamp: req.ClientTimestamp,
+			Status:          entities.MedCardEditPending,
+			NextAttemptAt:   now,
+		})
+		clientIDs = append(clientIDs, req.ClientEditID)
+	}
+
+	if err := u.repo.SaveMedCardEdits(ctx, edits); err != nil {
+		return nil, fmt.Errorf("failed to enqueue edits: %w", err)
+	}
+
+	queued, err := u.repo.GetMedCardEditsByClientIDs(ctx, userID, clientIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queued edits: %w", err)
+	}
+	return queued, nil
+}
+
+// GetMedCardEdits возвращает статусы правок пользователя: по клиентским ID
+// или, если они не заданы, последние правки с необязательным фильтром по статусу
+func (u *MedCardSyncUsecase) GetMedCardEdits(ctx context.Context, userID uint, clientEditIDs []string, status entities.MedCardEditStatus) ([]entities.MedCardEdit, error) {
+	if len(clientEditIDs) > 0 {
+		return u.repo.GetMedCardEditsByClientIDs(ctx, userID, clientEditIDs)
+	}
+	return u.repo.ListMedCardEdits(ctx, userID, status, pendingEditsBatch)
+}
+
+// ProcessPendingMedCardEdits отправляет в 1С накопившиеся правки. Правка сначала захватывается
+// на editLease, поэтому реплики не отправляют одну правку дважды, а запросы к 1С идут вне транзакции
+func (u *MedCardSyncUsecase) ProcessPendingMedCardEdits(ctx context.Context) error {
+	for i := 0; i < pendingEditsBatch; i++ {
+		edit, err := u.repo.ClaimPendingMedCardEdit(ctx, time.Now(), editLease)
+		if err != nil {
+			return fmt.Errorf("failed to claim pending edit: %w", err)
+		}
+		if edit == nil {
+			return nil
+		}
+		u.apply(ctx, edit)
+	}
+	return nil
+}
+
+// apply пытается применить захваченную правку и сохраняет результат попытки.
+// Недоступность 1С оставляет правку в очереди до editMaxAttempts попыток; конфликт версий,
+// некорректный патч и отсутствие пациента в 1С окончательны
+func (u *MedCardSyncUsecase) apply(ctx context.Context, edit *entities.MedCardEdit) {
+	card, err := u.patch(ctx, edit)
+	switch {
+	case err == nil:
+		now := time.Now()
+		edit.Status = entities.MedCardEditApplied
+		edit.AppliedVersion = card.Version
+		edit.AppliedAt = &now
+		edit.LastError = ""
+	case errors.Is(err, errors.ErrVersionConflict):
+		edit.Status = entities.MedCardEditConflict
+		edit.LastError = err.Error()
+		edit.ConflictCard = u.currentCardJSON(ctx, edit.PatientID)
+	case errors.Is(err, mergepatch.ErrInvalidPatch),
+		errors.Is(err, mergepatch.ErrFieldNotAllowed),
+		errors.Is(err, validation.ErrInvalid),
+		errors.Is(err, errors.ErrNotFound):
+		edit.Status = entities.MedCardEditRejected
+		edit.LastError = err.Error()
+	case edit.Attempts >= editMaxAttempts:
+		edit.Status = entities.MedCardEditRejected
+		edit.LastError = fmt.Sprintf("gave up after %d attempts: %v", edit.Attempts, err)
+	default:
+		edit.LastError = err.Error()
+		edit.NextAttemptAt = time.Now().Add(editRetryDelay(edit.Attempts))
+	}
+
+	if err := u.repo.UpdateMedCardEdit(ctx, edit); err != nil && !errors.Is(err, errors.ErrEmptyAction) {
+		fmt.Printf("warn: failed to save sync status of edit %s: %v\n", edit.ClientEditID, err)
+	}
+}
+
+// editRetryDelay — пауза перед следующей отправкой правки после attempts неудачных попыток
+func editRetryDelay(attempts int) time.Duration {
+	delay := editRetryBase
+	for i := 1; i < attempts && delay < editRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, editRetryMax)
+}
+
+func (u *MedCardSyncUsecase) patch(ctx context.Context, edit *entities.MedCardEdit) (*entities.OneCMedicalCard, error) {
+	// Карта могла ещё не попасть в локальный кэш — подтягиваем её из 1С
+	current, err := u.cards.GetMedCardByPatientID(ctx, edit.PatientID)
+	if err != nil {
+		return nil, err
+	}
+
+	baseVersion, err := u.rebase(ctx, edit, current.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.cards.PatchMedicalCard(ctx, edit.PatientID, edit.Patch, baseVersion)
+}
+
+// rebase определяет, на какую версию карты накладывать правку. Если после base_version
+// карту меняли только предыдущие правки этого же пользователя из той же офлайн-сессии,
+// правка накладывается на текущую версию, иначе это конфликт
+func (u *MedCardSyncUsecase) rebase(ctx context.Context, edit *entities.MedCardEdit, currentVersion uint) (uint, error) {
+	if edit.BaseVersion == currentVersion {
+		return currentVersion, nil
+	}
+
+	last, err := u.repo.GetLastAppliedMedCardEdit(ctx, edit.UserID, edit.PatientID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last applied edit: %w", err)
+	}
+	if last != nil &&
+		last.AppliedVersion == currentVersion &&
+		last.BaseVersion == edit.BaseVersion &&
+		last.ID < edit.ID {
+		return currentVersion, nil
+	}
+
+	return 0, errors.NewVersionConflictError(currentVersion)
+}
+
+func (u *MedCardSyncUsecase) currentCardJSON(ctx context.Context, patientID string) json.RawMessage {
+	card, err := u.cards.GetMedCardByPatientID(ctx, patientID)
+	if err != nil {
+		return nil
+	}
+	data, err := json.Marshal(card)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -2,29 +2,58 @@ package usecases
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
 	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/mergepatch"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
 	"gorm.io/gorm"
 )
 
+// medCardPatchWhitelist — поля карты, которые врач может менять через PATCH.
+// Идентификационные данные (СНИЛС, полис, сертификат, лечащий врач) меняются только в 1С
+var medCardPatchWhitelist = mergepatch.Whitelist{
+	"mobile_phone":     true,
+	"additional_phone": true,
+	"email":            true,
+	"address":          true,
+	"workplace":        true,
+	"address_details":  true, // Строка address для 1С пересобирается из частей
+	"relative.status":  true,
+	"relative.name":    true,
+}
+
 type MedCardUsecase struct {
 	repo       interfaces.MedicalCardRepository
 	onecClient interfaces.OneCClient
 	txManager  interfaces.TxManager
+	geocoder   interfaces.Geocoder
+	// Сколько медкарт пакета загружается из 1С одновременно
+	fetchWorkers int
 }
 
 func NewMedCardUsecase(
 	repo interfaces.MedicalCardRepository,
 	onecClient interfaces.OneCClient,
 	txManager interfaces.TxManager,
+	geocoder interfaces.Geocoder,
+	fetchWorkers int,
 ) interfaces.MedCardUsecase {
 	return &MedCardUsecase{
 		repo:       repo,
 		onecClient: onecClient,
 		txManager:  txManager,
+		geocoder:   geocoder,
+
+		fetchWorkers: fetchWorkers,
 	}
 }
 
@@ -35,27 +64,442 @@ func (u *MedCardUsecase) GetMedCardByPatientID(ctx context.Context, patientID st
 		return nil, fmt.Errorf("db error: %w", err)
 	}
 	if card != nil {
+		card.FillAge(time.Now())
 		return card, nil
 	}
 
+	OneCCard, err := u.fetchMedCard(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	OneCCard.FillAge(time.Now())
+	return OneCCard, nil
+}
+
+// fetchMedCard получает карту из 1С и сохраняет её в БД. Карту объединённой записи 1С может ещё
+// отдавать, пока не обработала объединение, поэтому вместо загрузки возвращается errors.MergedError
+func (u *MedCardUsecase) fetchMedCard(ctx context.Context, patientID string) (*entities.OneCMedicalCard, error) {
+	survivorID, err := u.repo.GetMergeSurvivor(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("db error: %w", err)
+	}
+	if survivorID != "" {
+		return nil, errors.NewMergedError(survivorID)
+	}
+
 	OneCCard, err := u.onecClient.GetMedCardByPatientID(patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medical card from 1C: %w", err)
+	}
+	if OneCCard.Version == 0 {
+		OneCCard.Version = 1
+	}
+	u.enrichOneCAddress(ctx, OneCCard)
 	if err := u.repo.SaveMedicalCard(ctx, OneCCard); err != nil {
 		fmt.Printf("warn: failed to save medical card for patient %s: %v\n", patientID, err)
 		return nil, fmt.Errorf("failed to save medical card %v", err)
 	}
-
 	return OneCCard, nil
 }
 
-// UpdateMedicalCard — обновляет карту в 1С и БД
-func (u *MedCardUsecase) UpdateMedicalCard(ctx context.Context, card *entities.OneCMedicalCard) error {
-	if err := u.onecClient.UpdateMedCardByPatientID(card.PatientID, card); err != nil {
-		return fmt.Errorf("failed to update in 1C: %w", err)
+// GetMedCardsBatch — карты нескольких пациентов. Сохранённые в БД отдаются сразу, недостающие
+// загружаются из 1С в пуле из fetchWorkers горутин. Ошибка по одному пациенту не прерывает остальных.
+// Результат в порядке patientIDs, повторы ID схлопываются
+func (u *MedCardUsecase) GetMedCardsBatch(ctx context.Context, patientIDs []string) []models.MedCardBatchItem {
+	items := make([]models.MedCardBatchItem, 0, len(patientIDs))
+	index := make(map[string]int, len(patientIDs))
+	for _, patientID := range patientIDs {
+		if _, ok := index[patientID]; ok {
+			continue
+		}
+		index[patientID] = len(items)
+		items = append(items, models.MedCardBatchItem{PatientID: patientID})
+	}
+
+	cached, err := u.repo.GetMedicalCards(ctx, patientIDs)
+	if err != nil {
+		// Без кэша все карты загружаются из 1С
+		fmt.Printf("warn: failed to get cached medical cards: %v\n", err)
+		cached = nil
+	}
+	for i := range cached {
+		items[index[cached[i].PatientID]].Card = &cached[i]
+	}
+
+	var misses []int
+	for i := range items {
+		if items[i].Card == nil {
+			misses = append(misses, i)
+		}
+	}
+	u.fetchMedCards(ctx, items, misses)
+
+	now := time.Now()
+	for i := range items {
+		if items[i].Card != nil {
+			items[i].Status = http.StatusOK
+			items[i].Card.FillAge(now)
+		}
+	}
+	return items
+}
+
+// fetchMedCards загружает из 1С карты items[i] для i из misses. Число одновременных запросов ограничено,
+// чтобы вызов с множеством пострадавших не перегружал 1С
+func (u *MedCardUsecase) fetchMedCards(ctx context.Context, items []models.MedCardBatchItem, misses []int) {
+	workers := u.fetchWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(misses) {
+		workers = len(misses)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				card, err := u.fetchMedCard(ctx, items[i].PatientID)
+				var merged *errors.MergedError
+				if errors.As(err, &merged) {
+					items[i].Status = http.StatusGone
+					items[i].Error = "patient merged into " + merged.SurvivorID
+					continue
+				}
+				if errors.Is(err, errors.ErrNotFound) {
+					items[i].Status = http.StatusNotFound
+					items[i].Error = "medical card not found"
+					continue
+				}
+				if err != nil {
+					// Текст ошибки 1С остаётся в логе, клиенту уходит только статус
+					fmt.Printf("warn: failed to get medical card for patient %s: %v\n", items[i].PatientID, err)
+					items[i].Status = http.StatusBadGateway
+					items[i].Error = "failed to get medical card from 1C"
+					continue
+				}
+				items[i].Card = card
+			}
+		}()
+	}
+
+feed:
+	for _, i := range misses {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Карты, до которых очередь не дошла из-за отмены запроса
+	if err := ctx.Err(); err != nil {
+		for _, i := range misses {
+			if items[i].Card == nil && items[i].Error == "" {
+				items[i].Status = http.StatusServiceUnavailable
+				items[i].Error = "request canceled"
+			}
+		}
+	}
+}
+
+// UpdateMedicalCard — обновляет карту в 1С и БД.
+// expectedVersion — версия карты, которую клиент получил при чтении (If-Match).
+// Если карта в БД или в 1С успела измениться, возвращается errors.VersionConflictError.
+// Возвращается версия карты, сохранённая в БД: если 1С приняла правку, а запись в БД не удалась,
+// версия остаётся прежней, и следующий If-Match с ней пройдёт
+func (u *MedCardUsecase) UpdateMedicalCard(ctx context.Context, card *entities.OneCMedicalCard, expectedVersion uint) (uint, error) {
+	err := u.withCardLock(ctx, card.PatientID, expectedVersion, func(ctx context.Context, current *entities.OneCMedicalCard) error {
+		if err := normalizeMedCard(card, current); err != nil {
+			return err
+		}
+		if err := u.normalizeAddress(ctx, card, current); err != nil {
+			return err
+		}
+
+		card.Version = expectedVersion
+		card.Age = nil // возраст вычисляется из даты рождения и в 1С не передаётся
+		if err := u.onecClient.UpdateMedCardByPatientID(card.PatientID, oneCMedCard(card)); err != nil {
+			return fmt.Errorf("failed to update in 1C: %w", err)
+		}
+
+		// 1С уже приняла правку, поэтому сбой кэша её не отменяет
+		if err := u.repo.UpdateMedicalCard(ctx, card, expectedVersion); err != nil {
+			fmt.Printf("warn: failed to update local cache for patient %s: %v\n", card.PatientID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return card.Version, nil
+}
+
+// PatchMedicalCard — частично обновляет карту по JSON Merge Patch (RFC 7396).
+// Разрешены только поля из medCardPatchWhitelist, в 1С уходят только реально изменённые поля
+func (u *MedCardUsecase) PatchMedicalCard(ctx context.Context, patientID string, patch []byte, expectedVersion uint) (*entities.OneCMedicalCard, error) {
+	parsed, err := mergepatch.Parse(patch)
+	if err != nil {
+		return nil, err
+	}
+	if err := medCardPatchWhitelist.Check(parsed); err != nil {
+		return nil, err
+	}
+
+	var result *entities.OneCMedicalCard
+	err = u.withCardLock(ctx, patientID, expectedVersion, func(ctx context.Context, current *entities.OneCMedicalCard) error {
+		card, err := u.applyMedCardPatch(ctx, current, parsed, expectedVersion)
+		if err != nil {
+			return err
+		}
+		result = card
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.FillAge(time.Now())
+	return result, nil
+}
+
+// applyMedCardPatch применяет патч к заблокированной карте current, отправляет изменения в 1С и сохраняет карту.
+// Без изменений возвращает current
+func (u *MedCardUsecase) applyMedCardPatch(
+	ctx context.Context,
+	current *entities.OneCMedicalCard,
+	parsed mergepatch.Patch,
+	expectedVersion uint,
+) (*entities.OneCMedicalCard, error) {
+	original, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+
+	patched, err := mergepatch.Apply(original, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	var card entities.OneCMedicalCard
+	if err := json.Unmarshal(patched, &card); err != nil {
+		return nil, fmt.Errorf("%w: %v", mergepatch.ErrInvalidPatch, err)
+	}
+	card.PatientID = current.PatientID
+	card.Version = expectedVersion
+	if err := normalizeMedCard(&card, current); err != nil {
+		return nil, err
+	}
+	if err := u.normalizeAddress(ctx, &card, current); err != nil {
+		return nil, err
+	}
+
+	// В 1С отправляются нормализованные значения и только реально изменённые поля
+	normalized, err := json.Marshal(&card)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	projected, err := mergepatch.Project(normalized, parsed)
+	if err != nil {
+		return nil, err
+	}
+	changes, _, err := mergepatch.Changes(original, projected)
+	if err != nil {
+		return nil, err
+	}
+
+	// Части адреса 1С не знает: ей уходит пересобранная строка
+	delete(changes, "address_details")
+	if card.Address != current.Address {
+		changes["address"] = card.Address
+	}
+	addressChanged := !entities.SameAddress(card.AddressDetails, current.AddressDetails)
+	if len(changes) == 0 && !addressChanged {
+		return current, nil
+	}
+
+	if len(changes) > 0 {
+		if err := u.onecClient.PatchMedCardByPatientID(current.PatientID, changes); err != nil {
+			return nil, fmt.Errorf("failed to update in 1C: %w", err)
+		}
+	}
+
+	// 1С уже приняла правку, поэтому сбой кэша её не отменяет
+	if err := u.repo.UpdateMedicalCard(ctx, &card, expectedVersion); err != nil {
+		fmt.Printf("warn: failed to update local cache for patient %s: %v\n", current.PatientID, err)
+	}
+	return &card, nil
+}
+
+// withCardLock выполняет fn, удерживая блокировку строки карты до конца транзакции. Параллельная правка
+// той же карты ждёт завершения первой и получает конфликт версий, ничего не отправив в 1С.
+// fn вызывается, только если версия карты и в БД, и в 1С равна expectedVersion; ошибка fn откатывает транзакцию
+func (u *MedCardUsecase) withCardLock(
+	ctx context.Context,
+	patientID string,
+	expectedVersion uint,
+	fn func(ctx context.Context, current *entities.OneCMedicalCard) error,
+) error {
+	var newer *entities.OneCMedicalCard
+	err := u.txManager.WithinTransaction(ctx, func(txCtx context.Context) error {
+		current, err := u.repo.GetMedicalCardForUpdate(txCtx, patientID)
+		if err != nil {
+			return fmt.Errorf("db error: %w", err)
+		}
+		if current == nil {
+			return errors.NewNotFoundError("medical card " + patientID)
+		}
+		if current.Version != expectedVersion {
+			return errors.NewVersionConflictError(current.Version)
+		}
+
+		newer, err = u.newerInOneC(current)
+		if err != nil {
+			return err
+		}
+		if newer != nil {
+			return errors.NewVersionConflictError(newer.Version)
+		}
+		return fn(txCtx, current)
+	})
+
+	// Кэш обновляется после отката транзакции, иначе запись ждала бы собственной блокировки
+	if newer != nil {
+		u.enrichOneCAddress(ctx, newer)
+		if err := u.repo.SaveMedicalCard(ctx, newer); err != nil {
+			fmt.Printf("warn: failed to refresh local cache for patient %s: %v\n", patientID, err)
+		}
+	}
+	return err
+}
+
+// newerInOneC возвращает карту из 1С, если её версия отличается от локальной.
+// 1С, не передающая версию (0), считается совпадающей с локальной
+func (u *MedCardUsecase) newerInOneC(local *entities.OneCMedicalCard) (*entities.OneCMedicalCard, error) {
+	remote, err := u.onecClient.GetMedCardByPatientID(local.PatientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medical card from 1C: %w", err)
+	}
+	if remote.Version == 0 || remote.Version == local.Version {
+		return nil, nil
 	}
+	return remote, nil
+}
 
-	if err := u.repo.SaveMedicalCard(ctx, card); err != nil {
-		fmt.Printf("warn: failed to update local cache for patient %s: %v\n", card.PatientID, err)
+// normalizeMedCard проверяет и нормализует документы и контакты карты.
+// Проверяются только поля, отличающиеся от текущей карты, чтобы устаревшие данные из 1С не блокировали правку
+func normalizeMedCard(card, current *entities.OneCMedicalCard) error {
+	if card.Snils != "" && card.Snils != current.Snils {
+		digits, err := validation.NormalizeSnils(card.Snils)
+		if err != nil {
+			return err
+		}
+		card.Snils = validation.FormatSnils(digits)
 	}
 
+	if card.Policy.Number != "" && card.Policy.Number != current.Policy.Number && isOMSPolicy(card.Policy) {
+		number, err := validation.NormalizeOMSPolicy(card.Policy.Number)
+		if err != nil {
+			return err
+		}
+		card.Policy.Number = number
+	}
+
+	if card.MobilePhone != "" && card.MobilePhone != current.MobilePhone {
+		phone, err := validation.NormalizePhone(card.MobilePhone)
+		if err != nil {
+			return validation.WithField("mobile_phone", err)
+		}
+		card.MobilePhone = phone
+	}
+
+	if card.AdditionalPhone != "" && card.AdditionalPhone != current.AdditionalPhone {
+		phone, err := validation.NormalizePhone(card.AdditionalPhone)
+		if err != nil {
+			return validation.WithField("additional_phone", err)
+		}
+		card.AdditionalPhone = phone
+	}
+
+	if card.BirthDate != "" && card.BirthDate != current.BirthDate {
+		birthDate, err := validation.ParseBirthDate(card.BirthDate)
+		if err != nil {
+			return err
+		}
+		card.BirthDate = birthDate.Format(time.DateOnly)
+	}
+
+	return nil
+}
+
+// normalizeAddress согласует строку адреса и его части при правке карты. Изменённые части проверяются;
+// строка для 1С собирается из них, только если клиент её не передал — строку 1С приложение не переписывает.
+// Изменённая строка заново разбирается геокодером
+func (u *MedCardUsecase) normalizeAddress(ctx context.Context, card, current *entities.OneCMedicalCard) error {
+	switch {
+	case !card.AddressDetails.IsZero() && !entities.SameAddress(card.AddressDetails, current.AddressDetails):
+		if err := card.AddressDetails.Normalize(); err != nil {
+			var vErr *validation.Error
+			if errors.As(err, &vErr) {
+				return validation.WithField("address_details."+vErr.Field, err)
+			}
+			return err
+		}
+		if card.Address == "" {
+			card.Address = card.AddressDetails.Format()
+		}
+		if !card.AddressDetails.HasCoordinates() {
+			if found := u.geocode(ctx, card.Address); found.HasCoordinates() {
+				card.AddressDetails.Lat, card.AddressDetails.Lon = found.Lat, found.Lon
+				card.AddressDetails.Precision = found.Precision
+			}
+		}
+	case card.Address != current.Address || card.AddressDetails.IsZero():
+		card.AddressDetails = u.geocode(ctx, card.Address)
+	}
 	return nil
 }
+
+// enrichOneCAddress разбирает адрес карты, полученной из 1С. Части, переданные самой 1С, остаются, если они корректны
+func (u *MedCardUsecase) enrichOneCAddress(ctx context.Context, card *entities.OneCMedicalCard) {
+	if !card.AddressDetails.IsZero() && card.AddressDetails.Normalize() == nil {
+		return
+	}
+	card.AddressDetails = u.geocode(ctx, card.Address)
+}
+
+// geocode — недоступный геокодер не мешает сохранить карту: адрес останется строкой,
+// части и координаты появятся при следующем изменении адреса
+func (u *MedCardUsecase) geocode(ctx context.Context, raw string) *entities.Address {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	addr, err := u.geocoder.Geocode(ctx, raw)
+	if err != nil {
+		// Адрес — персональные данные, в журнал попадает только ошибка
+		fmt.Printf("warn: failed to geocode address: %v\n", err)
+		return nil
+	}
+	if addr.IsZero() {
+		return nil
+	}
+	return addr
+}
+
+// oneCMedCard — карта в том виде, в котором её знает 1С: адрес только строкой
+func oneCMedCard(card *entities.OneCMedicalCard) *entities.OneCMedicalCard {
+	out := *card
+	out.AddressDetails = nil
+	return &out
+}
+
+// isOMSPolicy — проверку 16 цифр проходят только полисы ОМС (тип не указан или "ОМС")
+func isOMSPolicy(policy entities.Policy) bool {
+	return policy.Type == "" || strings.EqualFold(policy.Type, "ОМС")
+}
@@ -4,23 +4,30 @@ package usecases
 
 import (
 	"context"
+	"time"
 
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/namesearch"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
 )
 
 type OneCPatientUsecase struct {
 	repo       interfaces.PatientRepository
 	httpClient interfaces.OneCClient
+	filters    interfaces.FilterBuilderService
 }
 
 func NewOneCPatientListUsecase(
 	repo interfaces.PatientRepository,
 	httpClient interfaces.OneCClient,
+	filters interfaces.FilterBuilderService,
 ) interfaces.OneCPatientUsecase {
 	return &OneCPatientUsecase{
 		repo:       repo,
 		httpClient: httpClient,
+		filters:    filters,
 	}
 }
 
@@ -29,14 +36,115 @@ func (u *OneCPatientUsecase) HandlePatientListUpdate(ctx context.Context, update
 	return u.repo.SavePatientList(ctx, update.Patients)
 }
 
-// UpdatePatientListFromOneC — отдаёт список пациентов из БД
-func (u *OneCPatientUsecase) GetPatientListPage(ctx context.Context, offset, limit int) ([]entities.OneCPatientListItem, error) {
-	patients, _, err := u.repo.GetPatientListPage(ctx, offset, limit)
+// patientListFilter разбирает filter и order списка пациентов.
+// Допустимые поля перечислены в models.PatientListFields
+func (u *OneCPatientUsecase) patientListFilter(query models.PatientListQuery) (models.SQLFilter, error) {
+	var filter models.SQLFilter
+	fields, err := getFieldTypes(models.PatientListFields{})
+	if err != nil {
+		return filter, err
+	}
+
+	if query.Filter != "" {
+		filter.Where, filter.Params, err = u.filters.ParseFilterString(query.Filter, fields)
+		if err != nil {
+			return filter, &validation.Error{Field: "filter", Message: err.Error()}
+		}
+	}
+	filter.Order, err = u.filters.ParseOrderString(query.Order, fields)
+	if err != nil {
+		return filter, &validation.Error{Field: "order", Message: err.Error()}
+	}
+	return filter, nil
+}
+
+// GetPatientListCursor — отдаёт страницу списка пациентов по курсору с фильтрацией и сортировкой.
+// Страницы не сдвигаются, когда список обновляется из 1С между запросами
+func (u *OneCPatientUsecase) GetPatientListCursor(ctx context.Context, query models.PatientListQuery) (*models.CursorPage[[]entities.OneCPatientListItem], error) {
+	filter, err := u.patientListFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := u.repo.GetPatientListCursor(ctx, models.CursorPageRequest{
+		Cursor:    query.Cursor,
+		Limit:     query.Limit,
+		WithTotal: query.WithTotal,
+	}, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range page.Hits {
+		page.Hits[i].FillAge(now)
+	}
+	return page, nil
+}
+
+// GetPatientListPage — отдаёт страницу списка пациентов по номеру (OFFSET) с фильтрацией и сортировкой.
+// Режим по умолчанию, совместимый со старыми версиями приложения; постраничный вывод по курсору — GetPatientListCursor
+func (u *OneCPatientUsecase) GetPatientListPage(ctx context.Context, query models.PatientListQuery) (*models.FilterResponse[[]entities.OneCPatientListItem], error) {
+	filter, err := u.patientListFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	patients, total, err := u.repo.GetPatientListPage(ctx, (query.Page-1)*query.Limit, query.Limit, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range patients {
+		patients[i].FillAge(now)
+	}
+
+	return &models.FilterResponse[[]entities.OneCPatientListItem]{
+		Hits:        patients,
+		CurrentPage: query.Page,
+		TotalPages:  int((total + int64(query.Limit) - 1) / int64(query.Limit)),
+		TotalHits:   int(total),
+		HitsPerPage: query.Limit,
+	}, nil
+}
+
+// patientSearchMinScore — минимальная похожесть ФИО: пропускает опечатку-две в фамилии,
+// но отсекает случайные совпадения по одной-двум буквам
+const patientSearchMinScore = 0.3
+
+// SearchPatients — нечёткий поиск пациентов по ФИО (опечатки, латиница, ё/е).
+// Если запрос похож на СНИЛС, полис или телефон, точные совпадения по ним поднимаются наверх
+func (u *OneCPatientUsecase) SearchPatients(ctx context.Context, q string, limit int) ([]models.PatientSearchHit, error) {
+	query := models.PatientSearchQuery{
+		Name:     namesearch.Normalize(q),
+		MinScore: patientSearchMinScore,
+		Limit:    limit,
+	}
+	if query.Name == "" {
+		return nil, &validation.Error{Field: "q", Message: "must contain letters or digits"}
+	}
+
+	if snils := validation.DigitsOnly(q); len(snils) == 11 {
+		query.Snils = snils
+	}
+	if policy := validation.SearchKey(q); len(validation.DigitsOnly(policy)) >= 6 {
+		query.Policy = policy
+	}
+	if phone, err := validation.NormalizePhone(q); err == nil {
+		query.Phone = phone
+	}
+
+	hits, err := u.repo.SearchPatients(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	return patients, nil
+	now := time.Now()
+	for i := range hits {
+		hits[i].Patient.FillAge(now)
+	}
+	return hits, nil
 }
 
 // UpdatePatientListFromOneC — получает список пациентов из 1С и сохраняет в БД
@@ -3,29 +3,43 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/middleware/logging"
 	"github.com/AlexanderMorozov1919/mobileapp/internal/services/websocket"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
 )
 
 type OneCWebhookUsecase struct {
-	repo interfaces.ReceptionSmpRepository
-	hub  *websocket.Hub
+	repo   interfaces.ReceptionSmpRepository
+	hub    *websocket.Hub
+	logger *logging.Logger
 }
 
 func NewOneCWebhookUsecase(
 	repo interfaces.ReceptionSmpRepository,
 	hub *websocket.Hub,
+	logger *logging.Logger,
 ) interfaces.OneCWebhookUsecase {
 	return &OneCWebhookUsecase{
-		repo: repo,
-		hub:  hub,
+		repo:   repo,
+		hub:    hub,
+		logger: logger.WithPrefix("1C_WEBHOOK"),
 	}
 }
 
 // HandleReceptionsUpdate — обрабатывает обновление от 1С
 func (u *OneCWebhookUsecase) HandleReceptionsUpdate(ctx context.Context, call models.Call) error {
+	u.normalizeCall(&call)
+
+	if err := u.repo.ReplaceCallAssignments(ctx, call.CallID, callAssignments(call)); err != nil {
+		return fmt.Errorf("failed to save call assignments: %w", err)
+	}
+
 	// err := u.repo.SaveReceptions(ctx, call.CallID, call.Patients)
 	// if err != nil {
 	// 	return err
@@ -48,3 +62,139 @@ func (u *OneCWebhookUsecase) GetInterestedUserIDs(callID int) []uint {
 	}
 	return []uint{1}
 }
+
+// callAssignments разворачивает бригаду и пациентов вызова в назначения.
+// Завершённый вызов больше не даёт бригаде доступа к пациентам
+func callAssignments(call models.Call) []entities.CallAssignment {
+	if call.Status == models.CallStatusCompleted {
+		return nil
+	}
+
+	var assignments []entities.CallAssignment
+	seen := map[[2]string]bool{}
+	for _, login := range call.Crew {
+		for _, patient := range call.Patients {
+			key := [2]string{login, patient.PatientID}
+			if login == "" || patient.PatientID == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			assignments = append(assignments, entities.CallAssignment{
+				CallID:    call.CallID,
+				Login:     login,
+				PatientID: patient.PatientID,
+			})
+		}
+	}
+	return assignments
+}
+
+// normalizeCall нормализует телефон вызова, адрес по частям и документы пациентов. Вызов из 1С нельзя
+// потерять из-за опечатки: некорректное значение переносится в InvalidFields, а поле очищается
+func (u *OneCWebhookUsecase) normalizeCall(call *models.Call) {
+	if call.Phone != "" {
+		phone, err := validation.NormalizePhone(call.Phone)
+		if err != nil {
+			u.flagInvalidField(&call.InvalidFields, call.CallID, "phone", call.Phone, err)
+			phone = ""
+		}
+		call.Phone = phone
+	}
+
+	// Строка адреса остаётся основной; без неё адрес собирается из частей
+	if !call.AddressDetails.IsZero() {
+		u.normalizeCallAddress(call)
+	}
+	if call.Address == "" && !call.AddressDetails.IsZero() {
+		call.Address = call.AddressDetails.Format()
+	}
+
+	for i := range call.Patients {
+		u.normalizeCallPatient(call.CallID, &call.Patients[i])
+	}
+}
+
+// normalizeCallAddress отбрасывает некорректные части адреса, остальные части и строка адреса сохраняются
+func (u *OneCWebhookUsecase) normalizeCallAddress(call *models.Call) {
+	for {
+		err := call.AddressDetails.Normalize()
+		if err == nil {
+			break
+		}
+		var vErr *validation.Error
+		if !errors.As(err, &vErr) {
+			u.flagInvalidField(&call.InvalidFields, call.CallID, "address_details", "", err)
+			call.AddressDetails = nil
+			return
+		}
+		raw, ok := call.AddressDetails.DropPart(vErr.Field)
+		u.flagInvalidField(&call.InvalidFields, call.CallID, "address_details."+vErr.Field, raw, err)
+		if !ok {
+			call.AddressDetails = nil
+			return
+		}
+	}
+	if call.AddressDetails.IsZero() {
+		call.AddressDetails = nil
+	}
+}
+
+func (u *OneCWebhookUsecase) normalizeCallPatient(callID string, patient *models.Patient) {
+	if patient.Snils != "" {
+		digits, err := validation.NormalizeSnils(patient.Snils)
+		if err != nil {
+			u.flagInvalidField(&patient.InvalidFields, callID, "snils", patient.Snils, err)
+			patient.Snils = ""
+		} else {
+			patient.Snils = validation.FormatSnils(digits)
+		}
+	}
+
+	if patient.Policy.Number != "" && isOMSPolicy(patient.Policy) {
+		number, err := validation.NormalizeOMSPolicy(patient.Policy.Number)
+		if err != nil {
+			u.flagInvalidField(&patient.InvalidFields, callID, "policy.number", patient.Policy.Number, err)
+			number = ""
+		}
+		patient.Policy.Number = number
+	}
+
+	if patient.Phone != "" {
+		phone, err := validation.NormalizePhone(patient.Phone)
+		if err != nil {
+			u.flagInvalidField(&patient.InvalidFields, callID, "phone", patient.Phone, err)
+			phone = ""
+		}
+		patient.Phone = phone
+	}
+
+	if patient.BirthDate != "" {
+		birthDate, err := validation.ParseBirthDate(patient.BirthDate)
+		if err != nil {
+			u.flagInvalidField(&patient.InvalidFields, callID, "birth_date", patient.BirthDate, err)
+			patient.BirthDate = ""
+		} else {
+			patient.BirthDate = birthDate.Format(time.DateOnly)
+		}
+	}
+}
+
+// flagInvalidField запоминает исходное значение поля, не прошедшего проверку; само поле вызывающий очищает.
+// В журнал попадает только имя поля: значения — персональные данные
+func (u *OneCWebhookUsecase) flagInvalidField(fields *map[string]string, callID, name, raw string, err error) {
+	if *fields == nil {
+		*fields = make(map[string]string)
+	}
+	(*fields)[name] = raw
+	u.logger.Warn("Invalid field from 1C cleared, raw value moved to invalid_fields",
+		"call_id", callID, "field", name, "reason", validationReason(err))
+}
+
+// validationReason — причина ошибки проверки без самого значения
+func validationReason(err error) string {
+	var vErr *validation.Error
+	if errors.As(err, &vErr) {
+		return vErr.Message
+	}
+	return err.Error()
+}
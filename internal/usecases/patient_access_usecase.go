@@ -0,0 +1,183 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/services/websocket"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+)
+
+type PatientAccessUsecase struct {
+	repo         interfaces.Repository
+	onecClient   interfaces.OneCClient
+	hub          *websocket.Hub
+	emergencyTTL time.Duration
+}
+
+func NewPatientAccessUsecase(
+	repo interfaces.Repository,
+	onecClient interfaces.OneCClient,
+	hub *websocket.Hub,
+	emergencyTTL time.Duration,
+) interfaces.PatientAccessUsecase {
+	return &PatientAccessUsecase{
+		repo:         repo,
+		onecClient:   onecClient,
+		hub:          hub,
+		emergencyTTL: emergencyTTL,
+	}
+}
+
+// AuthorizePatientAccess разрешает доступ к пациенту, если у пользователя роль с доступом
+// ко всей клинике, он лечащий врач пациента или назначен на активный вызов к нему.
+// Каждое решение записывается в журнал; если запись не удалась, доступ запрещается.
+// Лечащий врач ищется в сохранённой карте, а если её нет — в карте из 1С, которая при этом не сохраняется
+func (u *PatientAccessUsecase) AuthorizePatientAccess(ctx context.Context, subject models.AccessSubject, patientID string, action entities.PatientAccessAction) error {
+	return u.authorize(ctx, subject, patientID, action, true)
+}
+
+// AuthorizeCachedPatientAccess — то же без обращения к 1С, для пакетных запросов: лечащий врач
+// определяется только по сохранённой карте. Если карты в БД нет, доступ дают вызов, экстренный доступ
+// или право на всю клинику
+func (u *PatientAccessUsecase) AuthorizeCachedPatientAccess(ctx context.Context, subject models.AccessSubject, patientID string, action entities.PatientAccessAction) error {
+	return u.authorize(ctx, subject, patientID, action, false)
+}
+
+func (u *PatientAccessUsecase) authorize(ctx context.Context, subject models.AccessSubject, patientID string, action entities.PatientAccessAction, lookupOneC bool) error {
+	reason, err := u.resolveAccess(ctx, subject, patientID, lookupOneC)
+	if err != nil {
+		return fmt.Errorf("failed to check patient access: %w", err)
+	}
+
+	entry := &entities.PatientAccessLog{
+		UserID:    subject.UserID,
+		PatientID: patientID,
+		Action:    action,
+		Granted:   reason != entities.AccessReasonDenied,
+		Reason:    reason,
+	}
+	if err := u.repo.SavePatientAccessLog(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record patient access: %w", err)
+	}
+
+	if !entry.Granted {
+		return fmt.Errorf("%w: no access to patient %s", errors.ErrForbidden, patientID)
+	}
+	return nil
+}
+
+// resolveAccess проверяет сначала вызовы и экстренный доступ, а карту — последней: её поиск может уйти в 1С
+func (u *PatientAccessUsecase) resolveAccess(ctx context.Context, subject models.AccessSubject, patientID string, lookupOneC bool) (entities.PatientAccessReason, error) {
+	if subject.ClinicWide {
+		return entities.AccessReasonClinicWide, nil
+	}
+
+	user, err := u.repo.GetUserByID(ctx, subject.UserID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil || user.Disabled() {
+		return entities.AccessReasonDenied, nil
+	}
+
+	assigned, err := u.repo.HasCallAssignment(ctx, user.Login, patientID)
+	if err != nil {
+		return "", err
+	}
+	if assigned {
+		return entities.AccessReasonAssignedCall, nil
+	}
+
+	granted, err := u.repo.HasActiveEmergencyAccessGrant(ctx, user.ID, patientID, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if granted {
+		return entities.AccessReasonEmergency, nil
+	}
+
+	// Карта может отсутствовать и в БД, и в 1С — тогда доступа нет
+	if user.DoctorCertNumber != "" {
+		card, err := u.attendingDoctorCard(ctx, patientID, lookupOneC)
+		if err != nil {
+			return "", err
+		}
+		if card != nil && card.AttendingDoctor.PolicyOrCertNumber == user.DoctorCertNumber {
+			return entities.AccessReasonAttendingDoctor, nil
+		}
+	}
+
+	return entities.AccessReasonDenied, nil
+}
+
+// attendingDoctorCard — карта для поиска лечащего врача. Проверка доступа не должна заносить в БД
+// карты пациентов, к которым доступа может не быть, поэтому карта из 1С не сохраняется.
+// Ошибка 1С равна отсутствию карты: отказ безопаснее, чем 500 на каждый запрос при недоступной 1С
+func (u *PatientAccessUsecase) attendingDoctorCard(ctx context.Context, patientID string, lookupOneC bool) (*entities.OneCMedicalCard, error) {
+	card, err := u.repo.GetMedicalCard(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+	if card != nil || !lookupOneC {
+		return card, nil
+	}
+
+	card, err = u.onecClient.GetMedCardByPatientID(patientID)
+	if err != nil {
+		if !errors.Is(err, errors.ErrNotFound) {
+			fmt.Printf("warn: failed to get medical card for access check: %v\n", err)
+		}
+		return nil, nil
+	}
+	return card, nil
+}
+
+// RequestEmergencyAccess выдаёт экстренный доступ к пациенту на emergencyTTL.
+// Лечащий врач пациента получает уведомление, доступ попадает в отчёт для администратора
+func (u *PatientAccessUsecase) RequestEmergencyAccess(ctx context.Context, userID uint, patientID, reason string) (*entities.EmergencyAccessGrant, error) {
+	grant := &entities.EmergencyAccessGrant{
+		UserID:    userID,
+		PatientID: patientID,
+		Reason:    reason,
+		ExpiresAt: time.Now().Add(u.emergencyTTL),
+	}
+	if err := u.repo.SaveEmergencyAccessGrant(ctx, grant); err != nil {
+		return nil, fmt.Errorf("failed to save emergency access grant: %w", err)
+	}
+
+	u.notifyAttendingDoctor(ctx, grant)
+	return grant, nil
+}
+
+func (u *PatientAccessUsecase) GetEmergencyAccessReport(ctx context.Context, filter models.EmergencyAccessFilter) ([]models.EmergencyAccessReportItem, error) {
+	items, err := u.repo.GetEmergencyAccessReport(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emergency access report: %w", err)
+	}
+	return items, nil
+}
+
+// notifyAttendingDoctor — уведомление не критично: доступ уже выдан и виден в отчёте
+func (u *PatientAccessUsecase) notifyAttendingDoctor(ctx context.Context, grant *entities.EmergencyAccessGrant) {
+	card, err := u.attendingDoctorCard(ctx, grant.PatientID, true)
+	if err != nil || card == nil || card.AttendingDoctor.PolicyOrCertNumber == "" {
+		return
+	}
+
+	doctor, err := u.repo.GetUserByDoctorCertNumber(ctx, card.AttendingDoctor.PolicyOrCertNumber)
+	if err != nil || doctor == nil || doctor.ID == grant.UserID {
+		return
+	}
+
+	u.hub.SendToUsers(models.Message{
+		Header:      "Экстренный доступ к пациенту",
+		Text:        fmt.Sprintf("Открыт экстренный доступ к карте пациента %s. Обоснование: %s", card.DisplayName, grant.Reason),
+		Reference:   "emergency_access",
+		ReferenceID: grant.ID,
+	}, doctor.ID)
+}
@@ -0,0 +1,84 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/patientqr"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+)
+
+type PatientCodeUsecase struct {
+	repo   interfaces.PatientRepository
+	secret []byte
+}
+
+func NewPatientCodeUsecase(repo interfaces.PatientRepository, secret string) interfaces.PatientCodeUsecase {
+	return &PatientCodeUsecase{
+		repo:   repo,
+		secret: []byte(secret),
+	}
+}
+
+// GetPatientQR рисует подписанный QR-код пациента из списка для браслета или карточки
+func (u *PatientCodeUsecase) GetPatientQR(ctx context.Context, patientID string, format models.QRFormat, size int) ([]byte, error) {
+	patient, err := u.repo.GetPatientListItem(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+	if patient == nil {
+		return nil, errors.NewNotFoundError("patient not found")
+	}
+
+	code := patientqr.Sign(u.secret, patient.PatientID)
+	if format == models.QRFormatSVG {
+		return patientqr.SVG(code, size)
+	}
+	return patientqr.PNG(code, size)
+}
+
+// ResolvePatientCode находит пациента по отсканированному коду: подписанному QR-коду пациента
+// или номеру полиса ОМС со штрихкода полиса (16 цифр, ищется по Policy.Number медкарты)
+func (u *PatientCodeUsecase) ResolvePatientCode(ctx context.Context, code string) (*entities.OneCPatientListItem, error) {
+	code = strings.TrimSpace(code)
+
+	var patient *entities.OneCPatientListItem
+	if patientqr.IsSigned(code) {
+		patientID, err := patientqr.Verify(u.secret, code)
+		if err != nil {
+			return nil, &validation.Error{Field: "code", Message: err.Error()}
+		}
+		patient, err = u.repo.GetPatientListItem(ctx, patientID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get patient: %w", err)
+		}
+	} else {
+		policy, err := validation.NormalizeOMSPolicy(code)
+		if err != nil {
+			return nil, &validation.Error{Field: "code", Message: "must be a patient QR code or a 16-digit OMS policy number"}
+		}
+		// Два пациента с одним полисом — вероятный дубль; угадывать нельзя, бригада уточнит вручную
+		patients, err := u.repo.GetPatientsByPolicy(ctx, policy, 2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get patient by policy: %w", err)
+		}
+		if len(patients) > 1 {
+			return nil, fmt.Errorf("%w: policy number matches several patients", errors.ErrAmbiguous)
+		}
+		if len(patients) == 1 {
+			patient = &patients[0]
+		}
+	}
+	if patient == nil {
+		return nil, errors.NewNotFoundError("patient not found")
+	}
+
+	patient.FillAge(time.Now())
+	return patient, nil
+}
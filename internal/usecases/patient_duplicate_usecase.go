@@ -0,0 +1,300 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/namesearch"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+)
+
+const (
+	// duplicateMinScore — с какой оценки пара попадает в очередь проверки.
+	// Совпадение СНИЛС или ФИО с датой рождения проходит, один полис или одно ФИО — нет
+	duplicateMinScore = 0.6
+	// duplicateMaxBlock — группы с одинаковым значением больше этой считаются заглушкой
+	// (например, СНИЛС из нулей) и не сравниваются попарно
+	duplicateMaxBlock = 200
+	// pendingMergesBatch — сколько объединений отправляется в 1С за один проход воркера
+	pendingMergesBatch = 100
+)
+
+type PatientDuplicateUsecase struct {
+	repo      interfaces.PatientDuplicateRepository
+	txManager interfaces.TxManager
+	onec      interfaces.OneCClient
+}
+
+func NewPatientDuplicateUsecase(
+	repo interfaces.PatientDuplicateRepository,
+	txManager interfaces.TxManager,
+	onec interfaces.OneCClient,
+) interfaces.PatientDuplicateUsecase {
+	return &PatientDuplicateUsecase{
+		repo:      repo,
+		txManager: txManager,
+		onec:      onec,
+	}
+}
+
+// duplicateRecord — запись пациента с нормализованными признаками для сравнения
+type duplicateRecord struct {
+	models.PatientRecord
+	name   string
+	snils  string
+	policy string
+	birth  string
+}
+
+func newDuplicateRecord(record models.PatientRecord) duplicateRecord {
+	r := duplicateRecord{
+		PatientRecord: record,
+		name:          namesearch.Normalize(record.FullName),
+		snils:         validation.DigitsOnly(record.Snils),
+		policy:        validation.SearchKey(record.Policy),
+	}
+	if birthDate, err := validation.ParseDate(record.BirthDate); err == nil {
+		r.birth = birthDate.Format("2006-01-02")
+	}
+	return r
+}
+
+// ScanPatientDuplicates ищет пары похожих записей и обновляет очередь проверки.
+// Сравниваются только записи с общим СНИЛС, полисом, датой рождения или ФИО
+func (u *PatientDuplicateUsecase) ScanPatientDuplicates(ctx context.Context) (int, error) {
+	scannedAt := time.Now()
+	raw, err := u.repo.GetDuplicateScanRecords(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get patients: %w", err)
+	}
+
+	records := make([]duplicateRecord, len(raw))
+	blocks := make(map[string][]int)
+	for i, record := range raw {
+		records[i] = newDuplicateRecord(record)
+		for _, key := range []string{"snils:" + records[i].snils, "policy:" + records[i].policy,
+			"birth:" + records[i].birth, "name:" + records[i].name} {
+			if !strings.HasSuffix(key, ":") {
+				blocks[key] = append(blocks[key], i)
+			}
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+	var candidates []entities.PatientDuplicateCandidate
+	for _, block := range blocks {
+		if len(block) < 2 || len(block) > duplicateMaxBlock {
+			continue
+		}
+		for x := 0; x < len(block); x++ {
+			for y := x + 1; y < len(block); y++ {
+				pair := [2]int{block[x], block[y]}
+				if seen[pair] {
+					continue
+				}
+				seen[pair] = true
+
+				a, b := records[pair[0]], records[pair[1]]
+				if a.PatientID == b.PatientID {
+					continue
+				}
+				score, reasons := scoreDuplicate(a, b)
+				if score < duplicateMinScore {
+					continue
+				}
+				if a.PatientID > b.PatientID {
+					a, b = b, a
+				}
+				candidates = append(candidates, entities.PatientDuplicateCandidate{
+					PatientID:      a.PatientID,
+					OtherPatientID: b.PatientID,
+					Score:          score,
+					Reasons:        strings.Join(reasons, ","),
+				})
+			}
+		}
+	}
+
+	if err := u.repo.ReplacePendingDuplicateCandidates(ctx, candidates, scannedAt); err != nil {
+		return 0, fmt.Errorf("failed to save duplicate candidates: %w", err)
+	}
+	return len(candidates), nil
+}
+
+// scoreDuplicate оценивает, насколько две записи похожи на одного человека (от 0 до 1).
+// Разные СНИЛС или даты рождения снижают оценку: это скорее однофамильцы
+func scoreDuplicate(a, b duplicateRecord) (float64, []string) {
+	var score float64
+	var reasons []string
+
+	compare := func(x, y, reason string, match, mismatch float64) {
+		switch {
+		case x == "" || y == "":
+		case x == y:
+			score += match
+			reasons = append(reasons, reason)
+		default:
+			score -= mismatch
+		}
+	}
+	compare(a.snils, b.snils, "snils", 0.6, 0.6)
+	compare(a.policy, b.policy, "policy", 0.4, 0.2)
+	compare(a.birth, b.birth, "birth_date", 0.3, 0.3)
+
+	if a.name != "" && b.name != "" {
+		if similarity := namesearch.Similarity(a.name, b.name); similarity >= 0.5 {
+			score += 0.4 * similarity
+			reasons = append(reasons, "name")
+		}
+	}
+
+	score = math.Max(0, math.Min(1, score))
+	return math.Round(score*100) / 100, reasons
+}
+
+// ListPatientDuplicates возвращает очередь проверки вместе с данными обеих записей пары
+func (u *PatientDuplicateUsecase) ListPatientDuplicates(ctx context.Context, status entities.PatientDuplicateStatus, limit int) ([]models.DuplicateCandidateView, error) {
+	candidates, err := u.repo.ListDuplicateCandidates(ctx, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get duplicate candidates: %w", err)
+	}
+
+	ids := make([]string, 0, 2*len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.PatientID, c.OtherPatientID)
+	}
+	records, err := u.repo.GetPatientRecords(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patients: %w", err)
+	}
+	byID := make(map[string]*models.PatientRecord, len(records))
+	for i := range records {
+		byID[records[i].PatientID] = &records[i]
+	}
+
+	views := make([]models.DuplicateCandidateView, len(candidates))
+	for i, c := range candidates {
+		views[i] = models.DuplicateCandidateView{
+			PatientDuplicateCandidate: c,
+			Patient:                   byID[c.PatientID],
+			Other:                     byID[c.OtherPatientID],
+		}
+	}
+	return views, nil
+}
+
+// DismissPatientDuplicate отмечает пару как разных людей
+func (u *PatientDuplicateUsecase) DismissPatientDuplicate(ctx context.Context, id, reviewerID uint) error {
+	candidate, err := u.repo.GetDuplicateCandidate(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get duplicate candidate: %w", err)
+	}
+	if candidate == nil {
+		return errors.NewNotFoundError(fmt.Sprintf("duplicate candidate %d", id))
+	}
+	return u.repo.DismissDuplicateCandidate(ctx, id, reviewerID)
+}
+
+// MergePatientDuplicate объединяет пару: вторая запись переносится в survivorID.
+// Об объединении сразу сообщается в 1С; если 1С недоступна, отправку повторит воркер
+func (u *PatientDuplicateUsecase) MergePatientDuplicate(ctx context.Context, id uint, survivorID string, userID uint) (*entities.PatientMerge, error) {
+	candidate, err := u.repo.GetDuplicateCandidate(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get duplicate candidate: %w", err)
+	}
+	if candidate == nil {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("duplicate candidate %d", id))
+	}
+
+	merge := &entities.PatientMerge{SurvivorID: survivorID, MergedBy: userID}
+	switch survivorID {
+	case candidate.PatientID:
+		merge.MergedID = candidate.OtherPatientID
+	case candidate.OtherPatientID:
+		merge.MergedID = candidate.PatientID
+	default:
+		return nil, &validation.Error{Field: "survivor_id", Message: "must be one of the pair"}
+	}
+
+	if err := u.repo.MergePatients(ctx, id, merge); err != nil {
+		return nil, fmt.Errorf("failed to merge patients: %w", err)
+	}
+
+	// Объединение уже сохранено, поэтому сбой отправки не отменяет его: её повторит воркер
+	_, err = u.reportClaimed(ctx, func(ctx context.Context) (*entities.PatientMerge, error) {
+		return u.repo.ClaimPatientMerge(ctx, merge.ID)
+	}, func(reported *entities.PatientMerge) {
+		merge = reported
+	})
+	if err != nil {
+		fmt.Printf("warn: failed to report patient merge %d: %v\n", merge.ID, err)
+	}
+	return merge, nil
+}
+
+// ReportPendingPatientMerges отправляет в 1С объединения, которые не удалось отправить сразу.
+// Каждое объединение отправляется в своей транзакции под блокировкой строки, поэтому реплики
+// и запрос администратора не отправляют одно объединение дважды
+func (u *PatientDuplicateUsecase) ReportPendingPatientMerges(ctx context.Context) error {
+	var lastID uint
+	for i := 0; i < pendingMergesBatch; i++ {
+		processed, err := u.reportClaimed(ctx, func(ctx context.Context) (*entities.PatientMerge, error) {
+			return u.repo.ClaimPendingPatientMerge(ctx, lastID)
+		}, func(reported *entities.PatientMerge) {
+			lastID = reported.ID
+		})
+		if err != nil || !processed {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportClaimed захватывает объединение через claim и отправляет его. done получает объединение
+// с результатом попытки. false — захватывать нечего
+func (u *PatientDuplicateUsecase) reportClaimed(
+	ctx context.Context,
+	claim func(ctx context.Context) (*entities.PatientMerge, error),
+	done func(merge *entities.PatientMerge),
+) (bool, error) {
+	processed := false
+	err := u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		merge, err := claim(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to claim pending merge: %w", err)
+		}
+		if merge == nil {
+			return nil
+		}
+
+		u.report(ctx, merge)
+		done(merge)
+		processed = true
+		return nil
+	})
+	return processed, err
+}
+
+// report отправляет одно объединение в 1С и сохраняет результат попытки
+func (u *PatientDuplicateUsecase) report(ctx context.Context, merge *entities.PatientMerge) {
+	merge.Attempts++
+	if err := u.onec.ReportPatientMerge(merge.SurvivorID, merge.MergedID); err != nil {
+		merge.LastError = err.Error()
+	} else {
+		now := time.Now()
+		merge.Status = entities.PatientMergeSynced
+		merge.ReportedAt = &now
+		merge.LastError = ""
+	}
+
+	if err := u.repo.UpdatePatientMerge(ctx, merge); err != nil {
+		fmt.Printf("warn: failed to save report status of patient merge %d: %v\n", merge.ID, err)
+	}
+}
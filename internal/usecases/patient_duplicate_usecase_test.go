@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+)
+
+func TestScoreDuplicate(t *testing.T) {
+	tests := []struct {
+		name        string
+		a, b        models.PatientRecord
+		wantScore   float64
+		wantReasons []string
+	}{
+		{
+			name:        "same snils",
+			a:           models.PatientRecord{Snils: "112-233-445 95"},
+			b:           models.PatientRecord{Snils: "11223344595"},
+			wantScore:   0.6,
+			wantReasons: []string{"snils"},
+		},
+		{
+			name:        "same policy only",
+			a:           models.PatientRecord{Policy: "7700 0000 0000 0001"},
+			b:           models.PatientRecord{Policy: "7700000000000001"},
+			wantScore:   0.4,
+			wantReasons: []string{"policy"},
+		},
+		{
+			name:        "same name only",
+			a:           models.PatientRecord{FullName: "Иванов Иван Иванович"},
+			b:           models.PatientRecord{FullName: "иванов иван иванович"},
+			wantScore:   0.4,
+			wantReasons: []string{"name"},
+		},
+		{
+			name:        "name and birth date in different formats",
+			a:           models.PatientRecord{FullName: "Ёлкин Пётр", BirthDate: "01.02.1990"},
+			b:           models.PatientRecord{FullName: "Elkin Petr", BirthDate: "1990-02-01"},
+			wantScore:   0.7,
+			wantReasons: []string{"birth_date", "name"},
+		},
+		{
+			name:        "everything matches",
+			a:           models.PatientRecord{FullName: "Иванов Иван", BirthDate: "1990-02-01", Snils: "11223344595", Policy: "1"},
+			b:           models.PatientRecord{FullName: "Иванов Иван", BirthDate: "1990-02-01", Snils: "11223344595", Policy: "1"},
+			wantScore:   1,
+			wantReasons: []string{"snils", "policy", "birth_date", "name"},
+		},
+		{
+			name:        "different snils means namesakes",
+			a:           models.PatientRecord{FullName: "Иванов Иван", BirthDate: "1990-02-01", Snils: "11223344595"},
+			b:           models.PatientRecord{FullName: "Иванов Иван", BirthDate: "1990-02-01", Snils: "91100000400"},
+			wantScore:   0.1,
+			wantReasons: []string{"birth_date", "name"},
+		},
+		{
+			name:        "different birth date",
+			a:           models.PatientRecord{Snils: "11223344595", BirthDate: "1990-02-01"},
+			b:           models.PatientRecord{Snils: "11223344595", BirthDate: "1991-02-01"},
+			wantScore:   0.3,
+			wantReasons: []string{"snils"},
+		},
+		{
+			name:        "different policy costs less",
+			a:           models.PatientRecord{Snils: "11223344595", BirthDate: "1990-02-01", Policy: "1"},
+			b:           models.PatientRecord{Snils: "11223344595", BirthDate: "1990-02-01", Policy: "2"},
+			wantScore:   0.7,
+			wantReasons: []string{"snils", "birth_date"},
+		},
+		{
+			name:        "dissimilar names ignored",
+			a:           models.PatientRecord{FullName: "Иванов", BirthDate: "1990-02-01"},
+			b:           models.PatientRecord{FullName: "Петров", BirthDate: "1990-02-01"},
+			wantScore:   0.3,
+			wantReasons: []string{"birth_date"},
+		},
+		{
+			name:      "clamped at zero",
+			a:         models.PatientRecord{Snils: "11223344595", BirthDate: "1990-02-01"},
+			b:         models.PatientRecord{Snils: "91100000400", BirthDate: "1991-02-01"},
+			wantScore: 0,
+		},
+		{
+			name:      "unparsable birth date ignored",
+			a:         models.PatientRecord{BirthDate: "unknown"},
+			b:         models.PatientRecord{BirthDate: "1990-02-01"},
+			wantScore: 0,
+		},
+		{
+			name:      "empty records",
+			wantScore: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, reasons := scoreDuplicate(newDuplicateRecord(tt.a), newDuplicateRecord(tt.b))
+			if score != tt.wantScore || !reflect.DeepEqual(reasons, tt.wantReasons) {
+				t.Errorf("scoreDuplicate() = %v %v, want %v %v", score, reasons, tt.wantScore, tt.wantReasons)
+			}
+
+			// Оценка не зависит от порядка записей
+			if reversed, _ := scoreDuplicate(newDuplicateRecord(tt.b), newDuplicateRecord(tt.a)); reversed != score {
+				t.Errorf("scoreDuplicate(b, a) = %v, want %v", reversed, score)
+			}
+		})
+	}
+}
+
+func TestScoreDuplicateSimilarName(t *testing.T) {
+	score, reasons := scoreDuplicate(
+		newDuplicateRecord(models.PatientRecord{FullName: "Иванова Мария Петровна", BirthDate: "1990-02-01"}),
+		newDuplicateRecord(models.PatientRecord{FullName: "Иванова Марья Петровна", BirthDate: "1990-02-01"}),
+	)
+	if score < duplicateMinScore || score >= 0.7 {
+		t.Errorf("score = %v, want between %v and 0.7 for a misspelled name", score, duplicateMinScore)
+	}
+	if !reflect.DeepEqual(reasons, []string{"birth_date", "name"}) {
+		t.Errorf("reasons = %v, want [birth_date name]", reasons)
+	}
+}
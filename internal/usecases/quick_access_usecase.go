@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+)
+
+type QuickAccessUsecase struct {
+	repo      interfaces.QuickAccessRepository
+	retention time.Duration
+	maxRecent int
+}
+
+func NewQuickAccessUsecase(repo interfaces.QuickAccessRepository, retention time.Duration, maxRecent int) interfaces.QuickAccessUsecase {
+	return &QuickAccessUsecase{
+		repo:      repo,
+		retention: retention,
+		maxRecent: maxRecent,
+	}
+}
+
+// RecordPatientView запоминает, что пользователь открыл медкарту пациента
+func (u *QuickAccessUsecase) RecordPatientView(ctx context.Context, userID uint, patientID string) error {
+	if err := u.repo.RecordPatientView(ctx, userID, patientID, time.Now()); err != nil {
+		return fmt.Errorf("failed to record patient view: %w", err)
+	}
+	return nil
+}
+
+// PruneRecentPatients удаляет просмотры старше срока хранения. Вызывается воркером
+func (u *QuickAccessUsecase) PruneRecentPatients(ctx context.Context) (int64, error) {
+	pruned, err := u.repo.PruneRecentPatients(ctx, time.Now().Add(-u.retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune recent patients: %w", err)
+	}
+	return pruned, nil
+}
+
+// GetRecentPatients — пациенты, чьи медкарты пользователь открывал за срок хранения, последние первыми.
+// limit больше настроенного максимума урезается
+func (u *QuickAccessUsecase) GetRecentPatients(ctx context.Context, userID uint, limit int) ([]models.QuickAccessPatient, error) {
+	if limit <= 0 || limit > u.maxRecent {
+		limit = u.maxRecent
+	}
+
+	now := time.Now()
+	patients, err := u.repo.GetRecentPatients(ctx, userID, now.Add(-u.retention), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent patients: %w", err)
+	}
+	fillQuickAccessAge(patients, now)
+	return patients, nil
+}
+
+// GetFavoritePatients — избранные пациенты пользователя по ФИО
+func (u *QuickAccessUsecase) GetFavoritePatients(ctx context.Context, userID uint) ([]models.QuickAccessPatient, error) {
+	now := time.Now()
+	patients, err := u.repo.GetFavoritePatients(ctx, userID, now.Add(-u.retention))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get favorite patients: %w", err)
+	}
+	fillQuickAccessAge(patients, now)
+	return patients, nil
+}
+
+// AddFavoritePatient добавляет пациента в избранное. Доступ к пациенту проверяет вызывающий
+func (u *QuickAccessUsecase) AddFavoritePatient(ctx context.Context, userID uint, patientID string) error {
+	if err := u.repo.AddFavoritePatient(ctx, userID, patientID); err != nil {
+		return fmt.Errorf("failed to add favorite patient: %w", err)
+	}
+	return nil
+}
+
+// RemoveFavoritePatient убирает пациента из избранного
+func (u *QuickAccessUsecase) RemoveFavoritePatient(ctx context.Context, userID uint, patientID string) error {
+	removed, err := u.repo.RemoveFavoritePatient(ctx, userID, patientID)
+	if err != nil {
+		return fmt.Errorf("failed to remove favorite patient: %w", err)
+	}
+	if !removed {
+		return errors.NewNotFoundError("patient is not in favorites")
+	}
+	return nil
+}
+
+func fillQuickAccessAge(patients []models.QuickAccessPatient, now time.Time) {
+	for i := range patients {
+		patients[i].FillAge(now)
+	}
+}
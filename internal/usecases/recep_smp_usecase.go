@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+)
+
+type ReceptionSmpUsecase struct {
+	repo interfaces.ReceptionSmpRepository
+}
+
+func NewReceptionSmpUsecase(repo interfaces.ReceptionSmpRepository) interfaces.ReceptionSmpUsecase {
+	return &ReceptionSmpUsecase{repo: repo}
+}
+
+// ListCalls — страница вызовов по курсору. Пользователь с правом patients:all видит все вызовы,
+// остальные — только вызовы, на которые назначена их бригада
+func (u *ReceptionSmpUsecase) ListCalls(ctx context.Context, subject models.AccessSubject, page models.CursorPageRequest) (*models.CursorPage[[]models.CallListItem], error) {
+	assignedUserID := subject.UserID
+	if subject.ClinicWide {
+		assignedUserID = 0
+	}
+
+	calls, err := u.repo.ListCalls(ctx, assignedUserID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := callListItems(calls.Hits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CursorPage[[]models.CallListItem]{
+		Hits:        items,
+		NextCursor:  calls.NextCursor,
+		PrevCursor:  calls.PrevCursor,
+		HitsPerPage: calls.HitsPerPage,
+		TotalHits:   calls.TotalHits,
+	}, nil
+}
+
+// callListItems разбирает пациентов вызовов, сохранённых как JSON от 1С
+func callListItems(calls []entities.OneCReception) ([]models.CallListItem, error) {
+	items := make([]models.CallListItem, len(calls))
+	for i, call := range calls {
+		items[i] = models.CallListItem{
+			CallID:    call.CallID,
+			Status:    call.Status,
+			UpdatedAt: call.UpdatedAt,
+		}
+		if len(call.Data) > 0 {
+			if err := json.Unmarshal(call.Data, &items[i].Patients); err != nil {
+				return nil, fmt.Errorf("failed to decode patients of call %s: %w", call.CallID, err)
+			}
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,144 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/entities"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/domain/models"
+	"github.com/AlexanderMorozov1919/mobileapp/internal/interfaces"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/errors"
+	"github.com/AlexanderMorozov1919/mobileapp/pkg/validation"
+)
+
+// syncTokenPrefix — версия формата токена синхронизации
+const syncTokenPrefix = "v1."
+
+type SyncUsecase struct {
+	repo interfaces.Repository
+}
+
+func NewSyncUsecase(repo interfaces.Repository) interfaces.SyncUsecase {
+	return &SyncUsecase{repo: repo}
+}
+
+// GetChanges — изменения пациентов, медкарт и вызовов с момента выдачи токена req.Since.
+// Медкарты и вызовы отдаются только доступные пользователю; выданные медкарты пишутся в журнал доступа
+func (u *SyncUsecase) GetChanges(ctx context.Context, req models.SyncRequest) (*models.SyncResponse, error) {
+	since, err := decodeSyncToken(req.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.repo.GetUserByID(ctx, req.Subject.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.Disabled() {
+		return nil, fmt.Errorf("%w: user is disabled", errors.ErrForbidden)
+	}
+
+	scope := models.ChangeScope{
+		ClinicWide:       req.Subject.ClinicWide,
+		Login:            user.Login,
+		DoctorCertNumber: user.DoctorCertNumber,
+		MedicalCards:     req.MedicalCards,
+		Calls:            req.Calls,
+	}
+	changes, err := u.repo.GetChanges(ctx, since, req.Limit, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes: %w", err)
+	}
+
+	if err := u.logCardAccess(ctx, user, scope, changes.MedicalCards); err != nil {
+		return nil, err
+	}
+
+	calls, err := callListItems(changes.Calls)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	response := &models.SyncResponse{
+		Token:        encodeSyncToken(changes.Seq),
+		HasMore:      changes.HasMore,
+		Reset:        changes.Reset,
+		Patients:     changes.Patients,
+		MedicalCards: changes.MedicalCards,
+		Calls:        calls,
+		Deleted:      changes.Deleted,
+	}
+	if response.Reset {
+		response.Token = ""
+	}
+	for i := range response.Patients {
+		response.Patients[i].FillAge(now)
+	}
+	for i := range response.MedicalCards {
+		response.MedicalCards[i].FillAge(now)
+	}
+
+	// Пустые списки отдаются как [], а не null
+	if response.Patients == nil {
+		response.Patients = []entities.OneCPatientListItem{}
+	}
+	if response.MedicalCards == nil {
+		response.MedicalCards = []entities.OneCMedicalCard{}
+	}
+	if response.Deleted == nil {
+		response.Deleted = []models.SyncDeletion{}
+	}
+	return response, nil
+}
+
+// logCardAccess записывает выданные медкарты в журнал доступа. Если запись не удалась, медкарты не отдаются
+func (u *SyncUsecase) logCardAccess(ctx context.Context, user *entities.AuthUser, scope models.ChangeScope, cards []entities.OneCMedicalCard) error {
+	entries := make([]entities.PatientAccessLog, len(cards))
+	for i, card := range cards {
+		reason := entities.AccessReasonAssignedCall
+		switch {
+		case scope.ClinicWide:
+			reason = entities.AccessReasonClinicWide
+		case user.DoctorCertNumber != "" && card.AttendingDoctor.PolicyOrCertNumber == user.DoctorCertNumber:
+			reason = entities.AccessReasonAttendingDoctor
+		}
+		entries[i] = entities.PatientAccessLog{
+			UserID:    user.ID,
+			PatientID: card.PatientID,
+			Action:    entities.PatientAccessRead,
+			Granted:   true,
+			Reason:    reason,
+		}
+	}
+
+	if err := u.repo.SavePatientAccessLogs(ctx, entries); err != nil {
+		return fmt.Errorf("failed to record patient access: %w", err)
+	}
+	return nil
+}
+
+// encodeSyncToken — непрозрачный для клиента токен с номером последнего выданного изменения
+func encodeSyncToken(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(syncTokenPrefix + strconv.FormatInt(seq, 10)))
+}
+
+func decodeSyncToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(raw), syncTokenPrefix) {
+		return 0, &validation.Error{Field: "since", Message: "malformed sync token"}
+	}
+	seq, err := strconv.ParseInt(strings.TrimPrefix(string(raw), syncTokenPrefix), 10, 64)
+	if err != nil || seq < 0 {
+		return 0, &validation.Error{Field: "since", Message: "malformed sync token"}
+	}
+	return seq, nil
+}
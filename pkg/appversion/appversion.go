@@ -0,0 +1,67 @@
+// Package appversion сравнивает версии мобильного приложения вида 1.2.3.
+// Суффиксы сборки (1.2.3-beta, 1.2.3+45) при сравнении не учитываются
+package appversion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse разбирает версию на числовые компоненты
+func Parse(version string) ([]int, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, fmt.Errorf("empty version")
+	}
+
+	parts := strings.Split(version, ".")
+	result := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+// Compare возвращает -1, 0 или 1, если a меньше, равна или больше b.
+// Недостающие компоненты считаются нулями: 1.2 == 1.2.0
+func Compare(a, b string) (int, error) {
+	va, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// Less сообщает, что версия a строго меньше b
+func Less(a, b string) (bool, error) {
+	cmp, err := Compare(a, b)
+	return cmp < 0, err
+}
@@ -0,0 +1,112 @@
+package appversion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{"full", "1.2.3", []int{1, 2, 3}, false},
+		{"short", "1.2", []int{1, 2}, false},
+		{"single", "7", []int{7}, false},
+		{"v prefix", "v1.4.0", []int{1, 4, 0}, false},
+		{"spaces", " 1.4.0 ", []int{1, 4, 0}, false},
+		{"pre-release", "1.2.3-beta.1", []int{1, 2, 3}, false},
+		{"build", "1.2.3+45", []int{1, 2, 3}, false},
+		{"pre-release and build", "1.2.3-rc.2+build.7", []int{1, 2, 3}, false},
+		{"build number after space", "1.2.3 (45)", []int{1, 2, 3}, false},
+		{"empty", "", nil, true},
+		{"only prefix", "v", nil, true},
+		{"only suffix", "-beta", nil, true},
+		{"letters", "1.a.3", nil, true},
+		{"empty component", "1..3", nil, true},
+		{"trailing dot", "1.2.", nil, true},
+		{"negative", "1.-2.3", nil, true},
+		{"plus sign component", "1.+2", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Parse(%q) = %v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.0.0", "1.2", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10.0", "1.9.9", 1},
+		{"2.0", "1.99.99", 1},
+		{"1.2.3-beta", "1.2.3", 0},
+		{"1.2.3+45", "1.2.3+46", 0},
+		{"1.2.3-rc.1", "1.2.4-alpha", -1},
+		{"v1.3", "1.2.9", 1},
+	}
+
+	for _, tt := range tests {
+		got, err := Compare(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, %v, want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+}
+
+func TestCompareMalformed(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"", "1.0"},
+		{"1.0", ""},
+		{"abc", "1.0"},
+		{"1.0", "1.x"},
+	}
+
+	for _, tt := range tests {
+		if _, err := Compare(tt.a, tt.b); err == nil {
+			t.Errorf("Compare(%q, %q) error = nil, want error", tt.a, tt.b)
+		}
+		if _, err := Less(tt.a, tt.b); err == nil {
+			t.Errorf("Less(%q, %q) error = nil, want error", tt.a, tt.b)
+		}
+	}
+}
+
+func TestLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.2.3", "1.2.4", true},
+		{"1.2.4", "1.2.3", false},
+		{"1.2.3", "1.2.3", false},
+		{"1.2.3-beta", "1.2.3", false},
+	}
+
+	for _, tt := range tests {
+		got, err := Less(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("Less(%q, %q) = %v, %v, want %v", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+}
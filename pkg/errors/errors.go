@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 type AppError struct {
@@ -22,6 +23,13 @@ func (a *AppError) Error() string {
 	return fmt.Sprintf("%s (code: %d)", a.Message, a.Code)
 }
 
+func (a *AppError) Unwrap() error {
+	if a == nil {
+		return nil
+	}
+	return a.Err
+}
+
 type DBError struct {
 	Message string
 	Err     error
@@ -33,11 +41,14 @@ const (
 	NotFound            = "not_found"
 	UnauthorizedError   = "unauthorized"
 
-	UnauthorizedErrorCode   = 401
-	InvalidDataCode         = 402
-	ForbiddenErrorCode      = 403
-	InternalServerErrorCode = 500
-	NotFoundErrorCode       = 404
+	UnauthorizedErrorCode       = 401
+	InvalidDataCode             = 402
+	ForbiddenErrorCode          = 403
+	InternalServerErrorCode     = 500
+	NotFoundErrorCode           = 404
+	ConflictErrorCode           = 409
+	PreconditionFailedErrorCode = 412
+	TooManyRequestsErrorCode    = 429
 )
 
 func NewAppError(httpCode int, message string, err error, isUserFacing bool) *AppError {
@@ -65,6 +76,11 @@ var (
 	ErrUnauthorized = errors.New("unauthorized")
 	ErrForbidden    = errors.New("forbidden")
 	ErrInternal     = errors.New("internal error")
+
+	ErrVersionConflict = errors.New("version conflict")
+	ErrLoginLocked     = errors.New("too many failed login attempts")
+	ErrAmbiguous       = errors.New("ambiguous match")
+	ErrMerged          = errors.New("patient record merged")
 )
 
 func Is(err any, err2 error) bool {
@@ -74,6 +90,10 @@ func Is(err any, err2 error) bool {
 	return false
 }
 
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
 var ErrNotFound = errors.New("not found")
 
 func NewNotFoundError(message string) error {
@@ -109,3 +129,62 @@ func NewForbiddenError(op string, message string) *AppError {
 		IsUserFacing: true,
 	}
 }
+
+// VersionConflictError — версия записи изменилась с момента её чтения клиентом
+type VersionConflictError struct {
+	CurrentVersion uint
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s: current version is %d", ErrVersionConflict, e.CurrentVersion)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+// NewVersionConflictError создает ошибку конфликта версий
+func NewVersionConflictError(currentVersion uint) error {
+	return &VersionConflictError{CurrentVersion: currentVersion}
+}
+
+// LoginLockedError — вход временно заблокирован после серии неудачных попыток
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrLoginLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// NewLoginLockedError создает ошибку блокировки входа
+func NewLoginLockedError(op string, retryAfter time.Duration) *AppError {
+	return &AppError{
+		Code:         TooManyRequestsErrorCode,
+		Message:      fmt.Sprintf("%s: too many failed login attempts", op),
+		Err:          &LoginLockedError{RetryAfter: retryAfter},
+		IsUserFacing: true,
+	}
+}
+
+// MergedError — запись пациента объединена с другой; данные теперь хранятся под SurvivorID
+type MergedError struct {
+	SurvivorID string
+}
+
+func (e *MergedError) Error() string {
+	return fmt.Sprintf("%s into %s", ErrMerged, e.SurvivorID)
+}
+
+func (e *MergedError) Unwrap() error {
+	return ErrMerged
+}
+
+// NewMergedError создает ошибку обращения к объединённой записи пациента
+func NewMergedError(survivorID string) error {
+	return &MergedError{SurvivorID: survivorID}
+}
@@ -0,0 +1,177 @@
+// Package mergepatch реализует JSON Merge Patch (RFC 7396)
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidPatch    = errors.New("invalid merge patch")
+	ErrFieldNotAllowed = errors.New("field is not allowed to be changed")
+	ErrInvalidDocument = errors.New("invalid document")
+)
+
+// Whitelist — набор путей (через точку), которые разрешено изменять патчем.
+// Путь "relative.name" разрешает менять только поле name вложенного объекта relative
+type Whitelist map[string]bool
+
+// Patch — разобранный merge patch
+type Patch map[string]interface{}
+
+// Parse разбирает тело merge patch. По RFC 7396 патч может быть любым JSON,
+// но для документов-объектов имеет смысл только объект
+func Parse(data []byte) (Patch, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: patch must be a JSON object", ErrInvalidPatch)
+	}
+	return obj, nil
+}
+
+// Check проверяет, что патч затрагивает только разрешённые пути.
+// Вложенный объект разрешён, если разрешены все изменяемые в нём поля;
+// удаление (null) целого вложенного объекта требует разрешения самого объекта
+func (w Whitelist) Check(patch Patch) error {
+	return w.check("", patch)
+}
+
+func (w Whitelist) check(prefix string, patch map[string]interface{}) error {
+	for key, value := range patch {
+		path := joinPath(prefix, key)
+		if w[path] {
+			continue
+		}
+		nested, ok := value.(map[string]interface{})
+		if !ok || !w.hasChildren(path) {
+			return fmt.Errorf("%w: %s", ErrFieldNotAllowed, path)
+		}
+		if err := w.check(path, nested); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w Whitelist) hasChildren(path string) bool {
+	for allowed := range w {
+		if strings.HasPrefix(allowed, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply применяет патч к JSON-документу и возвращает новый документ
+func Apply(doc []byte, patch Patch) ([]byte, error) {
+	var target map[string]interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	merged := mergeObject(target, patch)
+	return json.Marshal(merged)
+}
+
+// mergeObject — алгоритм MergePatch из раздела 2 RFC 7396
+func mergeObject(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = map[string]interface{}{}
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			current, _ := target[key].(map[string]interface{})
+			target[key] = mergeObject(current, nested)
+			continue
+		}
+		target[key] = value
+	}
+	return target
+}
+
+// Changes возвращает часть патча, которая действительно меняет документ
+// (без полей, значения которых совпадают с исходными), и список изменённых путей
+func Changes(doc []byte, patch Patch) (Patch, []string, error) {
+	var original map[string]interface{}
+	if err := json.Unmarshal(doc, &original); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	var paths []string
+	changes := diff("", original, patch, &paths)
+	sort.Strings(paths)
+	return changes, paths, nil
+}
+
+func diff(prefix string, original, patch map[string]interface{}, paths *[]string) Patch {
+	changes := Patch{}
+	for key, value := range patch {
+		path := joinPath(prefix, key)
+		current, exists := original[key]
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			currentObj, _ := current.(map[string]interface{})
+			if nestedChanges := diff(path, currentObj, nested, paths); len(nestedChanges) > 0 {
+				changes[key] = map[string]interface{}(nestedChanges)
+			}
+			continue
+		}
+
+		if value == nil && !exists {
+			continue
+		}
+		if exists && reflect.DeepEqual(current, value) {
+			continue
+		}
+		changes[key] = value
+		*paths = append(*paths, path)
+	}
+	return changes
+}
+
+// Project возвращает патч той же структуры, что и patch, но со значениями из документа doc.
+// Нужен, когда после применения патча документ дополнительно нормализуется
+func Project(doc []byte, patch Patch) (Patch, error) {
+	var source map[string]interface{}
+	if err := json.Unmarshal(doc, &source); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	return project(source, patch), nil
+}
+
+func project(source, patch map[string]interface{}) Patch {
+	projected := Patch{}
+	for key, value := range patch {
+		current, exists := source[key]
+		if nested, ok := value.(map[string]interface{}); ok {
+			currentObj, _ := current.(map[string]interface{})
+			projected[key] = map[string]interface{}(project(currentObj, nested))
+			continue
+		}
+		if !exists {
+			projected[key] = nil
+			continue
+		}
+		projected[key] = current
+	}
+	return projected
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
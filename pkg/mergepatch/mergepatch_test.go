@@ -0,0 +1,179 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func mustParse(t *testing.T, data string) Patch {
+	t.Helper()
+	patch, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse(%s) error: %v", data, err)
+	}
+	return patch
+}
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("invalid expected JSON %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"object", `{"a":1}`, false},
+		{"empty object", `{}`, false},
+		{"array", `[1,2]`, true},
+		{"string", `"a"`, true},
+		{"null", `null`, true},
+		{"malformed", `{"a":`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPatch) {
+					t.Errorf("Parse(%s) error = %v, want ErrInvalidPatch", tt.data, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Parse(%s) error: %v", tt.data, err)
+			}
+		})
+	}
+}
+
+// Примеры из приложения A RFC 7396, в которых и документ, и патч — объекты
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"replace", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"remove one of two", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"array replaced", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"value replaced by array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"nested", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"array of objects replaced", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"null inside new object dropped", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{"empty patch", `{"a":"b"}`, `{}`, `{"a":"b"}`},
+		{"scalar replaced by object", `{"a":"b"}`, `{"a":{"c":1}}`, `{"a":{"c":1}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), mustParse(t, tt.patch))
+			if err != nil {
+				t.Fatalf("Apply error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyInvalidDocument(t *testing.T) {
+	if _, err := Apply([]byte(`[1]`), Patch{"a": 1.0}); !errors.Is(err, ErrInvalidDocument) {
+		t.Errorf("Apply(array) error = %v, want ErrInvalidDocument", err)
+	}
+}
+
+func TestWhitelistCheck(t *testing.T) {
+	whitelist := Whitelist{
+		"display_name":  true,
+		"relative.name": true,
+		"contacts":      true,
+	}
+
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr bool
+	}{
+		{"allowed field", `{"display_name":"Иванов"}`, false},
+		{"allowed nested field", `{"relative":{"name":"Иванова"}}`, false},
+		{"whole allowed object", `{"contacts":{"phone":"+7"}}`, false},
+		{"forbidden field", `{"patient_id":"x"}`, true},
+		{"forbidden nested field", `{"relative":{"phone":"+7"}}`, true},
+		{"delete object with allowed child only", `{"relative":null}`, true},
+		{"scalar instead of object with allowed child", `{"relative":"x"}`, true},
+		{"one forbidden among allowed", `{"display_name":"a","version":2}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := whitelist.Check(mustParse(t, tt.patch))
+			if tt.wantErr && !errors.Is(err, ErrFieldNotAllowed) {
+				t.Errorf("Check(%s) error = %v, want ErrFieldNotAllowed", tt.patch, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Check(%s) error: %v", tt.patch, err)
+			}
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	doc := `{"a":"b","n":1,"obj":{"x":"y","z":"w"}}`
+
+	tests := []struct {
+		name    string
+		patch   string
+		changes string
+		paths   []string
+	}{
+		{"no changes", `{"a":"b","obj":{"x":"y"}}`, `{}`, nil},
+		{"changed field", `{"a":"c"}`, `{"a":"c"}`, []string{"a"}},
+		{"same number", `{"n":1}`, `{}`, nil},
+		{"nested change", `{"obj":{"x":"y","z":"v"}}`, `{"obj":{"z":"v"}}`, []string{"obj.z"}},
+		{"removed field", `{"a":null}`, `{"a":null}`, []string{"a"}},
+		{"remove missing field", `{"missing":null}`, `{}`, nil},
+		{"new field", `{"c":"d","a":"e"}`, `{"a":"e","c":"d"}`, []string{"a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, paths, err := Changes([]byte(doc), mustParse(t, tt.patch))
+			if err != nil {
+				t.Fatalf("Changes error: %v", err)
+			}
+			got, _ := json.Marshal(changes)
+			assertJSONEqual(t, got, tt.changes)
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("paths = %v, want %v", paths, tt.paths)
+			}
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	doc := `{"phone":"+79123456789","policy":{"number":"1234567890123456"}}`
+	patch := mustParse(t, `{"phone":"8 912 345-67-89","policy":{"number":"1234 5678 9012 3456","type":"ОМС"},"note":"x"}`)
+
+	projected, err := Project([]byte(doc), patch)
+	if err != nil {
+		t.Fatalf("Project error: %v", err)
+	}
+	got, _ := json.Marshal(projected)
+	assertJSONEqual(t, got, `{"phone":"+79123456789","policy":{"number":"1234567890123456","type":null},"note":null}`)
+}
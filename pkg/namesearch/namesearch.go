@@ -0,0 +1,113 @@
+// Package namesearch нормализует ФИО для нечёткого поиска и считает похожесть по триграммам
+// так же, как расширение pg_trgm. Используется, когда pg_trgm в базе недоступен
+package namesearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// latinToCyrillic — транслитерация, в которой врачи набирают фамилии латиницей.
+// Многобуквенные сочетания проверяются раньше одиночных букв
+var latinToCyrillic = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"}, {"sch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"tz", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"iu", "ю"}, {"ya", "я"}, {"ia", "я"}, {"yo", "е"}, {"ye", "е"},
+	{"ay", "ай"}, {"ey", "ей"}, {"iy", "ий"}, {"oy", "ой"}, {"uy", "уй"},
+	{"a", "а"}, {"b", "б"}, {"c", "к"}, {"d", "д"}, {"e", "е"}, {"f", "ф"}, {"g", "г"},
+	{"h", "х"}, {"i", "и"}, {"j", "й"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"},
+	{"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"},
+	{"v", "в"}, {"w", "в"}, {"x", "кс"}, {"y", "ы"}, {"z", "з"},
+}
+
+// phonetic — буквы, которые путают при наборе и транслитерации
+var phonetic = strings.NewReplacer(
+	"ё", "е",
+	"э", "е",
+	"й", "и",
+	"ъ", "",
+	"ь", "",
+)
+
+// Normalize приводит ФИО к виду для поиска: нижний регистр, кириллица, ё→е, й→и, без ь/ъ,
+// слова разделены одним пробелом. Латиница транслитерируется: Ivanov → иванов
+func Normalize(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = phonetic.Replace(transliterate(w))
+	}
+	return strings.Join(words, " ")
+}
+
+func transliterate(word string) string {
+	var b strings.Builder
+	for i := 0; i < len(word); {
+		if word[i] >= 'a' && word[i] <= 'z' {
+			for _, t := range latinToCyrillic {
+				if strings.HasPrefix(word[i:], t.latin) {
+					b.WriteString(t.cyrillic)
+					i += len(t.latin)
+					break
+				}
+			}
+			continue
+		}
+		b.WriteByte(word[i])
+		i++
+	}
+	return b.String()
+}
+
+// Trigrams возвращает множество триграмм строки по правилам pg_trgm:
+// каждое слово дополняется двумя пробелами слева и одним справа
+func Trigrams(s string) map[string]struct{} {
+	result := make(map[string]struct{})
+	for _, word := range strings.Fields(s) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			result[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return result
+}
+
+// Similarity — доля общих триграмм, как similarity() в pg_trgm (от 0 до 1)
+func Similarity(a, b string) float64 {
+	ta, tb := Trigrams(a), Trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	common := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+// WordSimilarity — насколько запрос похож на часть текста, как word_similarity() в pg_trgm.
+// Приближение: лучшая похожесть запроса на любую непрерывную последовательность слов текста
+func WordSimilarity(query, text string) float64 {
+	words := strings.Fields(text)
+	queryWords := len(strings.Fields(query))
+	if queryWords == 0 {
+		return 0
+	}
+
+	best := 0.0
+	for i := range words {
+		for j := i + 1; j <= len(words) && j-i <= queryWords; j++ {
+			if s := Similarity(query, strings.Join(words[i:j], " ")); s > best {
+				best = s
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,134 @@
+package namesearch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"lower case", "Иванов Иван", "иванов иван"},
+		{"yo", "Ёлкин Пётр", "елкин петр"},
+		{"yo equals ye", "Семёнов", "семенов"},
+		{"e oborotnoe", "Эдуард", "едуард"},
+		{"short i", "Андрей Чайкин", "андреи чаикин"},
+		{"soft and hard signs", "Ильин Подъячев", "илин подячев"},
+		{"double surname", "Салтыков-Щедрин", "салтыков щедрин"},
+		{"double name", "Анна-Мария", "анна мария"},
+		{"extra spaces and punctuation", "  Иванов,  Иван   Иванович. ", "иванов иван иванович"},
+		{"latin", "Ivanov", "иванов"},
+		{"latin upper case", "IVANOV IVAN", "иванов иван"},
+		{"latin shch", "Shchukin", "щукин"},
+		{"latin sch", "Schukin", "щукин"},
+		{"latin zh kh ts ch sh", "Zhukov Khariton Tsarev Chekhov Shishkin", "жуков харитон царев чехов шишкин"},
+		{"latin yu ya", "Yuriy Yakovlev", "юрии яковлев"},
+		{"latin iu ia", "Iurii Iakovlev", "юрии яковлев"},
+		{"latin yo ye", "Yolkin Yevgeniy", "елкин евгении"},
+		{"latin y endings", "Tsoy Sergey", "цои сергеи"},
+		{"latin x", "Maxim", "максим"},
+		{"latin double name", "Anna-Maria", "анна маря"},
+		{"mixed scripts", "Иванов Ivan", "иванов иван"},
+		{"digits kept", "Иванов 2", "иванов 2"},
+		{"empty", "", ""},
+		{"only punctuation", " -., ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMatchesVariants(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"Фёдоров", "Федоров"},
+		{"Fedorov", "Фёдоров"},
+		{"Ilin", "Ильин"},
+		{"Zhukova", "Жукова"},
+		{"Tsoy", "Цой"},
+		{"Салтыков-Щедрин", "салтыков щедрин"},
+	}
+
+	for _, tt := range tests {
+		if a, b := Normalize(tt.a), Normalize(tt.b); a != b {
+			t.Errorf("Normalize(%q) = %q, Normalize(%q) = %q, want equal", tt.a, a, tt.b, b)
+		}
+	}
+}
+
+func TestTrigrams(t *testing.T) {
+	got := Trigrams("кот")
+	want := []string{"  к", " ко", "кот", "от "}
+	if len(got) != len(want) {
+		t.Fatalf("Trigrams(%q) has %d trigrams, want %d", "кот", len(got), len(want))
+	}
+	for _, tri := range want {
+		if _, ok := got[tri]; !ok {
+			t.Errorf("Trigrams(%q) misses %q", "кот", tri)
+		}
+	}
+
+	if got := Trigrams("  "); len(got) != 0 {
+		t.Errorf("Trigrams of blank string = %v, want empty", got)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"identical", "иванов", "иванов", 1},
+		{"common ending", "иванов", "петров", 1.0 / 13},
+		{"one letter more", "иванов", "иванова", 6.0 / 9},
+		{"disjoint", "кот", "пес", 0},
+		{"word order", "иванов иван", "иван иванов", 1},
+		{"empty", "", "иванов", 0},
+		{"both empty", "", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		text  string
+		want  float64
+	}{
+		{"exact word", "петров", "иванов петров сергеевич", 1},
+		{"two words", "петров сергеевич", "иванов петров сергеевич", 1},
+		{"empty query", "", "иванов", 0},
+		{"empty text", "иванов", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WordSimilarity(tt.query, tt.text); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("WordSimilarity(%q, %q) = %v, want %v", tt.query, tt.text, got, tt.want)
+			}
+		})
+	}
+
+	// Опечатка в запросе снижает похожесть, но не до нуля
+	if got := WordSimilarity("петроф", "иванов петров"); got <= 0 || got >= 1 {
+		t.Errorf("WordSimilarity with typo = %v, want between 0 and 1", got)
+	}
+}
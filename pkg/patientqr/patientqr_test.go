@@ -0,0 +1,77 @@
+package patientqr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testSecret = []byte("test-patient-qr-secret")
+
+func TestSignVerify(t *testing.T) {
+	for _, patientID := range []string{"user1_id", "0000123", "Пациент/1.2"} {
+		code := Sign(testSecret, patientID)
+		if !IsSigned(code) {
+			t.Errorf("Sign(%q) = %q, want prefix %q", patientID, code, Prefix)
+		}
+		got, err := Verify(testSecret, code)
+		if err != nil || got != patientID {
+			t.Errorf("Verify(Sign(%q)) = %q, %v", patientID, got, err)
+		}
+	}
+}
+
+func TestVerifyErrors(t *testing.T) {
+	valid := Sign(testSecret, "user1_id")
+	other := Sign(testSecret, "user2_id")
+	_, otherSig, _ := strings.Cut(strings.TrimPrefix(other, Prefix), ".")
+	encodedID, _, _ := strings.Cut(strings.TrimPrefix(valid, Prefix), ".")
+
+	tests := []struct {
+		name string
+		code string
+		want error
+	}{
+		{"other barcode", "1234567890123456", ErrInvalidCode},
+		{"no signature", Prefix + encodedID, ErrInvalidCode},
+		{"empty ID", Prefix + "." + otherSig, ErrInvalidCode},
+		{"ID not base64", Prefix + "!!." + otherSig, ErrInvalidCode},
+		{"signature not base64", Prefix + encodedID + ".!!", ErrInvalidCode},
+		{"signature of another patient", Prefix + encodedID + "." + otherSig, ErrInvalidSignature},
+		{"truncated signature", valid[:len(valid)-2], ErrInvalidSignature},
+		{"another secret", Sign([]byte("another-secret"), "user1_id"), ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Verify(testSecret, tt.code); !errors.Is(err, tt.want) {
+				t.Errorf("Verify(%q) error = %v, want %v", tt.code, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	png, err := PNG(Sign(testSecret, "user1_id"), 256)
+	if err != nil {
+		t.Fatalf("PNG error: %v", err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Error("PNG output has no PNG signature")
+	}
+}
+
+func TestSVG(t *testing.T) {
+	svg, err := SVG(Sign(testSecret, "user1_id"), 256)
+	if err != nil {
+		t.Fatalf("SVG error: %v", err)
+	}
+	s := string(svg)
+	if !strings.HasPrefix(s, `<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256"`) || !strings.HasSuffix(s, "</svg>") {
+		t.Errorf("SVG = %.80q..., want a 256x256 svg document", s)
+	}
+	if !strings.Contains(s, "h1v1h-1z") {
+		t.Error("SVG has no dark modules")
+	}
+}
@@ -0,0 +1,192 @@
+// Package validation проверяет и нормализует документы и контакты пациентов:
+// СНИЛС, полис ОМС, телефон и дату рождения
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var ErrInvalid = errors.New("validation failed")
+
+// Error — ошибка проверки конкретного поля
+type Error struct {
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return ErrInvalid
+}
+
+func newError(field, message string) error {
+	return &Error{Field: field, Message: message}
+}
+
+// WithField переименовывает поле в ошибке проверки (например, "phone" -> "additional_phone")
+func WithField(field string, err error) error {
+	var vErr *Error
+	if errors.As(err, &vErr) {
+		return &Error{Field: field, Message: vErr.Message}
+	}
+	return err
+}
+
+// DigitsOnly оставляет в строке только цифры
+func DigitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// SearchKey приводит строку к виду для поиска: только буквы и цифры в верхнем регистре
+func SearchKey(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// onlySeparators проверяет, что кроме цифр в строке есть только пробелы и дефисы
+func onlySeparators(s string, extra ...rune) bool {
+	for _, r := range s {
+		if r >= '0' && r <= '9' || r == ' ' || r == '-' {
+			continue
+		}
+		allowed := false
+		for _, e := range extra {
+			if r == e {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeSnils проверяет СНИЛС (формат и контрольное число) и возвращает 11 цифр
+func NormalizeSnils(s string) (string, error) {
+	if !onlySeparators(s) {
+		return "", newError("snils", "must contain only digits, spaces and dashes")
+	}
+	digits := DigitsOnly(s)
+	if len(digits) != 11 {
+		return "", newError("snils", "must contain 11 digits")
+	}
+	if SnilsChecksum(digits[:9]) != digits[9:] {
+		return "", newError("snils", "invalid checksum")
+	}
+	return digits, nil
+}
+
+// SnilsChecksum вычисляет контрольное число СНИЛС по первым 9 цифрам:
+// сумма произведений цифр на их позицию с конца, взятая по модулю 101 (100 и 101 дают 00)
+func SnilsChecksum(first9 string) string {
+	sum := 0
+	for i, r := range first9 {
+		sum += int(r-'0') * (9 - i)
+	}
+	if sum > 101 {
+		sum %= 101
+	}
+	if sum == 100 || sum == 101 {
+		sum = 0
+	}
+	return fmt.Sprintf("%02d", sum)
+}
+
+// FormatSnils форматирует 11 цифр СНИЛС в вид "123-456-789 01"
+func FormatSnils(digits string) string {
+	if len(digits) != 11 {
+		return digits
+	}
+	return fmt.Sprintf("%s-%s-%s %s", digits[:3], digits[3:6], digits[6:9], digits[9:])
+}
+
+// NormalizeOMSPolicy проверяет номер полиса ОМС единого образца и возвращает 16 цифр
+func NormalizeOMSPolicy(s string) (string, error) {
+	if !onlySeparators(s) {
+		return "", newError("policy.number", "must contain only digits, spaces and dashes")
+	}
+	digits := DigitsOnly(s)
+	if len(digits) != 16 {
+		return "", newError("policy.number", "OMS policy number must contain 16 digits")
+	}
+	return digits, nil
+}
+
+// NormalizePhone приводит телефон к формату E.164. Номера без кода страны
+// (8XXXXXXXXXX, 9XXXXXXXXX) считаются российскими
+func NormalizePhone(s string) (string, error) {
+	trimmed := strings.TrimSpace(s)
+	if !onlySeparators(strings.TrimPrefix(trimmed, "+"), '(', ')') {
+		return "", newError("phone", "must contain only digits, spaces, dashes and parentheses")
+	}
+
+	digits := DigitsOnly(trimmed)
+	switch {
+	case strings.HasPrefix(trimmed, "+"):
+		if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+			return "", newError("phone", "must be a valid international number")
+		}
+	case len(digits) == 11 && (digits[0] == '8' || digits[0] == '7'):
+		digits = "7" + digits[1:]
+	case len(digits) == 10 && digits[0] == '9':
+		digits = "7" + digits
+	default:
+		return "", newError("phone", "must be a valid phone number")
+	}
+	return "+" + digits, nil
+}
+
+// dateLayouts — форматы дат, которые приходят из 1С и с устройств
+var dateLayouts = []string{
+	"2006-01-02",
+	"02.01.2006",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"02.01.2006 15:04:05",
+}
+
+// ParseDate разбирает дату в одном из известных форматов и отбрасывает время
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, newError("date", "unrecognized date format")
+}
+
+// ParseBirthDate разбирает дату рождения и проверяет, что она правдоподобна
+func ParseBirthDate(s string) (time.Time, error) {
+	t, err := ParseDate(s)
+	if err != nil {
+		return time.Time{}, newError("birth_date", "unrecognized date format")
+	}
+	if t.After(time.Now()) {
+		return time.Time{}, newError("birth_date", "must not be in the future")
+	}
+	if t.Year() < 1900 {
+		return time.Time{}, newError("birth_date", "must not be earlier than 1900")
+	}
+	return t, nil
+}
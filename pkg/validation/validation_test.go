@@ -0,0 +1,207 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSnilsChecksum(t *testing.T) {
+	tests := []struct {
+		first9 string
+		want   string
+	}{
+		{"112233445", "95"}, // сумма меньше 100
+		{"911000004", "00"}, // сумма 100
+		{"911000005", "00"}, // сумма 101
+		{"911000006", "01"}, // сумма 102 по модулю 101
+		{"996100000", "00"}, // сумма 201, по модулю 101 даёт 100
+		{"000000000", "00"},
+	}
+
+	for _, tt := range tests {
+		if got := SnilsChecksum(tt.first9); got != tt.want {
+			t.Errorf("SnilsChecksum(%q) = %q, want %q", tt.first9, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSnils(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"formatted", "112-233-445 95", "11223344595", false},
+		{"digits only", "11223344595", "11223344595", false},
+		{"checksum 00", "911-000-004 00", "91100000400", false},
+		{"wrong checksum", "112-233-445 96", "", true},
+		{"too short", "112-233-445 9", "", true},
+		{"too long", "112-233-445 950", "", true},
+		{"letters", "112-233-445 9A", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeSnils(tt.in)
+			if tt.wantErr {
+				assertFieldError(t, err, "snils")
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NormalizeSnils(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSnils(t *testing.T) {
+	if got := FormatSnils("11223344595"); got != "112-233-445 95" {
+		t.Errorf("FormatSnils = %q, want %q", got, "112-233-445 95")
+	}
+	if got := FormatSnils("123"); got != "123" {
+		t.Errorf("FormatSnils(short) = %q, want input unchanged", got)
+	}
+}
+
+func TestNormalizeOMSPolicy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"1234567890123456", "1234567890123456", false},
+		{"1234 5678 9012 3456", "1234567890123456", false},
+		{"1234-5678-9012-3456", "1234567890123456", false},
+		{"123456789012345", "", true},
+		{"12345678901234567", "", true},
+		{"АБ 123456", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeOMSPolicy(tt.in)
+		if tt.wantErr {
+			assertFieldError(t, err, "policy.number")
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeOMSPolicy(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"+7 (912) 345-67-89", "+79123456789", false},
+		{"8 912 345 67 89", "+79123456789", false},
+		{"79123456789", "+79123456789", false},
+		{"9123456789", "+79123456789", false},
+		{" +44 20 7946 0958 ", "+442079460958", false},
+		{"+0123456789", "", true},
+		{"+1234567", "", true},
+		{"3456789", "", true},
+		{"5123456789", "", true},
+		{"+7 912 345-67-89 доб. 1", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.in)
+		if tt.wantErr {
+			assertFieldError(t, err, "phone")
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(1985, time.March, 7, 0, 0, 0, 0, time.UTC)
+	for _, in := range []string{
+		"1985-03-07",
+		"07.03.1985",
+		"1985-03-07T10:30:00+03:00",
+		"1985-03-07T10:30:00",
+		"07.03.1985 10:30:00",
+		" 1985-03-07 ",
+	} {
+		got, err := ParseDate(in)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "07/03/1985", "1985-13-01", "вчера"} {
+		if _, err := ParseDate(in); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ParseDate(%q) error = %v, want ErrInvalid", in, err)
+		}
+	}
+}
+
+func TestParseBirthDate(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(time.DateOnly)
+
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{"1985-03-07", false},
+		{"01.01.1900", false},
+		{"31.12.1899", true},
+		{tomorrow, true},
+		{"not a date", true},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseBirthDate(tt.in)
+		if tt.wantErr {
+			assertFieldError(t, err, "birth_date")
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseBirthDate(%q) error: %v", tt.in, err)
+		}
+	}
+}
+
+func TestWithField(t *testing.T) {
+	_, err := NormalizePhone("abc")
+	assertFieldError(t, WithField("additional_phone", err), "additional_phone")
+
+	other := errors.New("other")
+	if got := WithField("phone", other); got != other {
+		t.Errorf("WithField(non-validation error) = %v, want it unchanged", got)
+	}
+}
+
+func TestSearchKey(t *testing.T) {
+	if got := SearchKey("123-456-789 01"); got != "12345678901" {
+		t.Errorf("SearchKey = %q, want %q", got, "12345678901")
+	}
+	if got := SearchKey("ab ёж-1"); got != "ABЁЖ1" {
+		t.Errorf("SearchKey = %q, want %q", got, "ABЁЖ1")
+	}
+}
+
+func assertFieldError(t *testing.T, err error, field string) {
+	t.Helper()
+	var vErr *Error
+	if !errors.As(err, &vErr) {
+		t.Errorf("error = %v, want *validation.Error for %s", err, field)
+		return
+	}
+	if vErr.Field != field {
+		t.Errorf("error field = %q, want %q", vErr.Field, field)
+	}
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("error %v does not unwrap to ErrInvalid", err)
+	}
+}